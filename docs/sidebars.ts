@@ -6,10 +6,12 @@ const sidebars: SidebarsConfig = {
     'installation',
     'usage',
     'configuration',
+    'messages',
+    'telemetry',
     {
       type: 'category',
       label: 'Targets',
-      items: ['targets/webhook', 'targets/slack'],
+      items: ['targets/webhook', 'targets/slack', 'targets/teams', 'targets/discord', 'targets/googlechat', 'targets/mattermost', 'targets/rocketchat', 'targets/email', 'targets/pagerduty', 'targets/opsgenie', 'targets/github', 'targets/gitlab', 'targets/bitbucket', 'targets/azuredevops', 'targets/jira', 'targets/servicenow', 'targets/file', 'targets/exec', 'targets/kafka', 'targets/nats', 'targets/amqp', 'targets/sns', 'targets/sqs', 'targets/eventbridge', 'targets/pubsub', 'targets/azureservicebus', 'targets/azureeventgrid', 'targets/syslog', 'targets/log', 'targets/splunk', 'targets/elasticsearch', 'targets/loki', 'targets/datadog', 'targets/grafana', 'targets/prometheus', 'targets/store'],
     },
     'contributing',
   ],
@@ -8,10 +8,11 @@ import (
 // Metadata contains git repository information automatically extracted
 // from the local git repository.
 type Metadata struct {
-	Committer string `json:"committer,omitempty"`
-	CommitSHA string `json:"commit_sha,omitempty"`
-	Branch    string `json:"branch,omitempty"`
-	RepoURL   string `json:"repo_url,omitempty"`
+	Committer     string `json:"committer,omitempty"`
+	CommitSHA     string `json:"commit_sha,omitempty"`
+	CommitMessage string `json:"commit_message,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	RepoURL       string `json:"repo_url,omitempty"`
 }
 
 // Extract attempts to extract git metadata from the current directory.
@@ -32,10 +33,11 @@ func Extract() *Metadata {
 	}
 
 	metadata := &Metadata{
-		Committer: runGitCommand("config", "user.name"),
-		CommitSHA: commitSHA,
-		Branch:    runGitCommand("rev-parse", "--abbrev-ref", "HEAD"),
-		RepoURL:   runGitCommand("config", "--get", "remote.origin.url"),
+		Committer:     runGitCommand("config", "user.name"),
+		CommitSHA:     commitSHA,
+		CommitMessage: runGitCommand("log", "-1", "--format=%B"),
+		Branch:        runGitCommand("rev-parse", "--abbrev-ref", "HEAD"),
+		RepoURL:       runGitCommand("config", "--get", "remote.origin.url"),
 	}
 
 	return metadata
@@ -60,6 +62,6 @@ func runGitCommand(args ...string) string {
 
 // isEmpty checks if all metadata fields are empty.
 func (m *Metadata) isEmpty() bool {
-	return m.Committer == "" && m.CommitSHA == "" &&
+	return m.Committer == "" && m.CommitSHA == "" && m.CommitMessage == "" &&
 		m.Branch == "" && m.RepoURL == ""
 }
@@ -76,6 +76,13 @@ func TestIsEmpty(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "commit message only",
+			metadata: &Metadata{
+				CommitMessage: "Add VPC",
+			},
+			want: false,
+		},
 		{
 			name: "branch only",
 			metadata: &Metadata{
@@ -93,10 +100,11 @@ func TestIsEmpty(t *testing.T) {
 		{
 			name: "all fields populated",
 			metadata: &Metadata{
-				Committer: "John Doe",
-				CommitSHA: "abc123",
-				Branch:    "main",
-				RepoURL:   "https://github.com/user/repo",
+				Committer:     "John Doe",
+				CommitSHA:     "abc123",
+				CommitMessage: "Add VPC",
+				Branch:        "main",
+				RepoURL:       "https://github.com/user/repo",
 			},
 			want: false,
 		},
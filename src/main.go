@@ -1,17 +1,58 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"infralog/config"
 	"infralog/target"
+	"infralog/target/amqp"
+	"infralog/target/azuredevops"
+	"infralog/target/azureeventgrid"
+	"infralog/target/azureservicebus"
+	"infralog/target/bitbucket"
+	"infralog/target/datadog"
+	"infralog/target/discord"
+	"infralog/target/elasticsearch"
+	"infralog/target/email"
+	"infralog/target/eventbridge"
+	"infralog/target/exec"
+	"infralog/target/file"
+	"infralog/target/github"
+	"infralog/target/gitlab"
+	"infralog/target/googlechat"
+	"infralog/target/grafana"
+	"infralog/target/jira"
+	"infralog/target/kafka"
+	"infralog/target/log"
+	"infralog/target/loki"
+	"infralog/target/mattermost"
+	"infralog/target/nats"
+	"infralog/target/opsgenie"
+	"infralog/target/pagerduty"
+	"infralog/target/prometheus"
+	"infralog/target/pubsub"
+	"infralog/target/rocketchat"
+	"infralog/target/servicenow"
 	"infralog/target/slack"
+	"infralog/target/sns"
+	"infralog/target/splunk"
+	"infralog/target/sqs"
+	"infralog/target/store"
+	"infralog/target/syslog"
+	"infralog/target/teams"
 	"infralog/target/webhook"
+	"infralog/telemetry"
 	"infralog/tfplan"
 	"os"
 	"sort"
+	"time"
 )
 
+// telemetryShutdownTimeout bounds flushing telemetry at exit, so an
+// unreachable OTLP collector can't hang the run.
+const telemetryShutdownTimeout = 5 * time.Second
+
 func main() {
 	// Parse CLI flags
 	planFile := flag.String("plan-file", "", "Path to Terraform plan JSON file (required)")
@@ -40,37 +81,85 @@ func main() {
 	// Initialize targets
 	targets := initTargets(cfg)
 
+	// Initialize telemetry, configured with OTEL_EXPORTER_OTLP_* variables
+	ctx := context.Background()
+	tel, err := telemetry.New(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing telemetry: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := run(ctx, tel, cfg, targets, plan)
+
+	// Flush telemetry; export errors don't fail the run
+	shutdownCtx, cancel := context.WithTimeout(ctx, telemetryShutdownTimeout)
+	if err := tel.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	cancel()
+
+	os.Exit(code)
+}
+
+// run parses and filters the plan and notifies targets, tracing each step as
+// a child span of the run. It returns the exit code.
+func run(ctx context.Context, tel *telemetry.Telemetry, cfg *config.Config, targets []target.Target, planFile string) int {
+	ctx, span := tel.StartRun(ctx, telemetry.String("plan_file", planFile))
+	var runErr error
+	defer func() { telemetry.End(span, runErr) }()
+
 	// Parse plan file
-	terraformPlan, err := tfplan.ParsePlanFile(plan)
+	_, parseSpan := tel.Start(ctx, "parse plan")
+	terraformPlan, err := tfplan.ParsePlanFile(planFile)
+	if err == nil {
+		parseSpan.SetAttributes(telemetry.Int("resources", len(terraformPlan.ResourceChanges)), telemetry.Int("outputs", len(terraformPlan.OutputChanges)))
+	}
+	telemetry.End(parseSpan, err)
 	if err != nil {
+		runErr = err
 		fmt.Printf("Error parsing plan file: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	// Apply filters to plan
+	_, filterSpan := tel.Start(ctx, "filter plan")
 	filteredPlan := tfplan.ApplyFilter(terraformPlan, cfg.Filter)
+	filterSpan.SetAttributes(telemetry.Int("resources", len(filteredPlan.ResourceChanges)), telemetry.Int("outputs", len(filteredPlan.OutputChanges)))
+	telemetry.End(filterSpan, nil)
+
+	payload := target.NewPayload(filteredPlan)
+	span.SetAttributes(telemetry.PayloadAttributes(payload)...)
+	tel.EmitLogs(ctx, payload)
 
-	// Exit early if no changes
+	// Exit early if no changes, after notifying targets that report empty plans
 	if !filteredPlan.HasChanges() {
+		if emptyTargets := emptyPlanTargets(targets); len(emptyTargets) > 0 {
+			if err := notifyTargets(ctx, tel, emptyTargets, payload); err != nil {
+				runErr = err
+				fmt.Fprintf(os.Stderr, "Error notifying targets: %v\n", err)
+				return 1
+			}
+		}
 		fmt.Println("No changes detected in plan")
-		os.Exit(0)
+		return 0
 	}
 
 	// Notify targets
 	hasNotificationTargets := len(targets) > 0
-	if err := notifyTargets(targets, filteredPlan, plan); err != nil {
+	if err := notifyTargets(ctx, tel, targets, payload); err != nil {
+		runErr = err
 		fmt.Fprintf(os.Stderr, "Error notifying targets: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	// Print output based on whether notification targets exist
 	if hasNotificationTargets {
 		printNotificationSummary(filteredPlan, targets)
 	} else {
-		printDetailedSummary(filteredPlan, plan)
+		printDetailedSummary(filteredPlan, planFile)
 	}
 
-	os.Exit(0)
+	return 0
 }
 
 // loadConfig loads the configuration file if provided, otherwise returns empty config.
@@ -111,16 +200,343 @@ func initTargets(cfg *config.Config) []target.Target {
 		targets = append(targets, t)
 	}
 
+	if cfg.Target.Teams.WebhookURL != "" {
+		t, err := teams.New(cfg.Target.Teams)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating teams target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Discord.WebhookURL != "" {
+		t, err := discord.New(cfg.Target.Discord)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating discord target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.GoogleChat.WebhookURL != "" {
+		t, err := googlechat.New(cfg.Target.GoogleChat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating google chat target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Mattermost.WebhookURL != "" {
+		t, err := mattermost.New(cfg.Target.Mattermost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating mattermost target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.RocketChat.WebhookURL != "" {
+		t, err := rocketchat.New(cfg.Target.RocketChat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating rocket.chat target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Email.Host != "" {
+		t, err := email.New(cfg.Target.Email)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating email target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.PagerDuty.RoutingKey != "" {
+		t, err := pagerduty.New(cfg.Target.PagerDuty)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating pagerduty target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Opsgenie.APIKey != "" {
+		t, err := opsgenie.New(cfg.Target.Opsgenie)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating opsgenie target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.GitHub.Token != "" {
+		t, err := github.New(cfg.Target.GitHub)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating github target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.GitLab.Token != "" {
+		t, err := gitlab.New(cfg.Target.GitLab)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating gitlab target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Bitbucket.Token != "" {
+		t, err := bitbucket.New(cfg.Target.Bitbucket)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating bitbucket target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.AzureDevOps.Token != "" {
+		t, err := azuredevops.New(cfg.Target.AzureDevOps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating azure devops target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.GitHubCheck.Token != "" {
+		t, err := github.NewCheckRun(cfg.Target.GitHubCheck)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating github check target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Jira.URL != "" {
+		t, err := jira.New(cfg.Target.Jira)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating jira target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.ServiceNow.InstanceURL != "" {
+		t, err := servicenow.New(cfg.Target.ServiceNow)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating servicenow target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.File.Path != "" {
+		t, err := file.New(cfg.Target.File)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating file target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Exec.Command != "" {
+		t, err := exec.New(cfg.Target.Exec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating exec target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if len(cfg.Target.Kafka.Brokers) > 0 {
+		t, err := kafka.New(cfg.Target.Kafka)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating kafka target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.NATS.URL != "" {
+		t, err := nats.New(cfg.Target.NATS)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating nats target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.AMQP.URL != "" {
+		t, err := amqp.New(cfg.Target.AMQP)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating amqp target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.SNS.TopicARN != "" {
+		t, err := sns.New(cfg.Target.SNS)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating sns target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.SQS.QueueURL != "" {
+		t, err := sqs.New(cfg.Target.SQS)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating sqs target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.EventBridge.EventBusName != "" {
+		t, err := eventbridge.New(cfg.Target.EventBridge)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating eventbridge target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.PubSub.Topic != "" {
+		t, err := pubsub.New(cfg.Target.PubSub)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating pubsub target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.AzureServiceBus.ConnectionString != "" {
+		t, err := azureservicebus.New(cfg.Target.AzureServiceBus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating azure service bus target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.AzureEventGrid.TopicEndpoint != "" {
+		t, err := azureeventgrid.New(cfg.Target.AzureEventGrid)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating azure event grid target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Syslog.Address != "" {
+		t, err := syslog.New(cfg.Target.Syslog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating syslog target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Log.Output != "" {
+		t, err := log.New(cfg.Target.Log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating log target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Splunk.URL != "" {
+		t, err := splunk.New(cfg.Target.Splunk)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating splunk target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Elasticsearch.URL != "" {
+		t, err := elasticsearch.New(cfg.Target.Elasticsearch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating elasticsearch target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Loki.URL != "" {
+		t, err := loki.New(cfg.Target.Loki)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating loki target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Datadog.APIKey != "" {
+		t, err := datadog.New(cfg.Target.Datadog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating datadog target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Grafana.URL != "" {
+		t, err := grafana.New(cfg.Target.Grafana)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating grafana target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Pushgateway.URL != "" {
+		t, err := prometheus.NewPushgateway(cfg.Target.Pushgateway)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating pushgateway target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Textfile.Path != "" {
+		t, err := prometheus.NewTextfile(cfg.Target.Textfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating textfile target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
+	if cfg.Target.Store.DSN != "" {
+		t, err := store.New(cfg.Target.Store)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating store target: %v\n", err)
+			os.Exit(1)
+		}
+		targets = append(targets, t)
+	}
+
 	return targets
 }
 
-// notifyTargets sends the plan to all configured targets.
-func notifyTargets(targets []target.Target, plan *tfplan.Plan, planFile string) error {
-	payload := target.NewPayload(plan)
-
+// notifyTargets sends the payload to all configured targets, each in its own
+// delivery span.
+func notifyTargets(ctx context.Context, tel *telemetry.Telemetry, targets []target.Target, payload *target.Payload) error {
 	var hasError bool
 	for _, t := range targets {
-		if err := t.Write(payload); err != nil {
+		name := targetName(t)
+		_, span := tel.Start(ctx, "deliver "+name, telemetry.String("target", name))
+		err := t.Write(payload)
+		telemetry.End(span, err)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing to target: %v\n", err)
 			hasError = true
 		}
@@ -133,6 +549,17 @@ func notifyTargets(targets []target.Target, plan *tfplan.Plan, planFile string)
 	return nil
 }
 
+// emptyPlanTargets returns the targets that report plans without changes.
+func emptyPlanTargets(targets []target.Target) []target.Target {
+	var result []target.Target
+	for _, t := range targets {
+		if et, ok := t.(target.EmptyPlanTarget); ok && et.ReportsEmptyPlans() {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
 // printDetailedSummary prints a detailed summary for local usage (no notification targets).
 func printDetailedSummary(plan *tfplan.Plan, planFile string) {
 	resourceCount := len(plan.ResourceChanges)
@@ -173,6 +600,10 @@ func printNotificationSummary(plan *tfplan.Plan, targets []target.Target) {
 		resourceCount, outputCount)
 
 	for _, t := range targets {
+		if r, ok := t.(target.Reporter); ok && r.Result() != "" {
+			fmt.Printf("✓ %s notification sent: %s\n", targetName(t), r.Result())
+			continue
+		}
 		fmt.Printf("✓ %s notification sent\n", targetName(t))
 	}
 }
@@ -216,6 +647,78 @@ func targetName(t target.Target) string {
 		return "Webhook"
 	case *slack.SlackTarget:
 		return "Slack"
+	case *teams.TeamsTarget:
+		return "Teams"
+	case *discord.DiscordTarget:
+		return "Discord"
+	case *googlechat.GoogleChatTarget:
+		return "Google Chat"
+	case *mattermost.MattermostTarget:
+		return "Mattermost"
+	case *rocketchat.RocketChatTarget:
+		return "Rocket.Chat"
+	case *email.EmailTarget:
+		return "Email"
+	case *pagerduty.PagerDutyTarget:
+		return "PagerDuty"
+	case *opsgenie.OpsgenieTarget:
+		return "Opsgenie"
+	case *github.GitHubTarget:
+		return "GitHub"
+	case *gitlab.GitLabTarget:
+		return "GitLab"
+	case *bitbucket.BitbucketTarget:
+		return "Bitbucket"
+	case *azuredevops.AzureDevOpsTarget:
+		return "Azure DevOps"
+	case *github.CheckRunTarget:
+		return "GitHub check run"
+	case *jira.JiraTarget:
+		return "Jira"
+	case *servicenow.ServiceNowTarget:
+		return "ServiceNow"
+	case *file.FileTarget:
+		return "File"
+	case *exec.ExecTarget:
+		return "Exec"
+	case *kafka.KafkaTarget:
+		return "Kafka"
+	case *nats.NATSTarget:
+		return "NATS"
+	case *amqp.AMQPTarget:
+		return "AMQP"
+	case *sns.SNSTarget:
+		return "SNS"
+	case *sqs.SQSTarget:
+		return "SQS"
+	case *eventbridge.EventBridgeTarget:
+		return "EventBridge"
+	case *pubsub.PubSubTarget:
+		return "Pub/Sub"
+	case *azureservicebus.AzureServiceBusTarget:
+		return "Azure Service Bus"
+	case *azureeventgrid.AzureEventGridTarget:
+		return "Azure Event Grid"
+	case *syslog.SyslogTarget:
+		return "Syslog"
+	case *log.LogTarget:
+		return "Log"
+	case *splunk.SplunkTarget:
+		return "Splunk"
+	case *elasticsearch.ElasticsearchTarget:
+		return "Elasticsearch"
+	case *loki.LokiTarget:
+		return "Loki"
+	case *datadog.DatadogTarget:
+		return "Datadog"
+	case *grafana.GrafanaTarget:
+		return "Grafana"
+	case *prometheus.PushgatewayTarget:
+		return "Pushgateway"
+	case *prometheus.TextfileTarget:
+		return "Textfile"
+	case *store.StoreTarget:
+		return "Store"
 	default:
 		return "Target"
 	}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// migrations are applied in order and recorded in schema_migrations. Applied
+// migrations must never change; add a new one instead. The statements are
+// valid in both SQLite and Postgres.
+var migrations = []string{
+	// 1: runs and resource changes
+	`CREATE TABLE runs (
+		id TEXT PRIMARY KEY,
+		datetime TIMESTAMP NOT NULL,
+		repo_url TEXT NOT NULL DEFAULT '',
+		branch TEXT NOT NULL DEFAULT '',
+		commit_sha TEXT NOT NULL DEFAULT '',
+		committer TEXT NOT NULL DEFAULT '',
+		terraform_version TEXT NOT NULL DEFAULT '',
+		resources INTEGER NOT NULL,
+		added INTEGER NOT NULL,
+		changed INTEGER NOT NULL,
+		replaced INTEGER NOT NULL,
+		removed INTEGER NOT NULL,
+		outputs INTEGER NOT NULL
+	);
+	CREATE INDEX runs_datetime ON runs (datetime);
+	CREATE INDEX runs_repo_branch ON runs (repo_url, branch);
+	CREATE TABLE resource_changes (
+		run_id TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		address TEXT NOT NULL,
+		type TEXT NOT NULL,
+		module TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		actions TEXT NOT NULL,
+		action_reason TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (run_id, position)
+	);
+	CREATE INDEX resource_changes_address ON resource_changes (address);
+	CREATE INDEX resource_changes_type_action ON resource_changes (type, action);`,
+}
+
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// concurrent runs don't apply the same migration twice.
+const migrationLockID = 4630796
+
+// migrate applies pending migrations, each in its own transaction.
+func (s *Store) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	for i, statements := range migrations {
+		if err := s.applyMigration(ctx, i+1, statements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration applies a migration unless its version is already recorded.
+func (s *Store) applyMigration(ctx context.Context, version int, statements string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting migration %d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if s.driver == DriverPostgres {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("error locking migrations: %w", err)
+		}
+	}
+
+	var applied int
+	if err := tx.QueryRowContext(ctx, s.rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied); err != nil {
+		return fmt.Errorf("error reading schema_migrations: %w", err)
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return fmt.Errorf("error applying migration %d: %w", version, err)
+	}
+	if _, err := tx.ExecContext(ctx, s.rebind("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"), version, time.Now().UTC()); err != nil {
+		return fmt.Errorf("error recording migration %d: %w", version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error applying migration %d: %w", version, err)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver
+	_ "modernc.org/sqlite"             // registers the sqlite driver
+)
+
+// Drivers.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// sqliteParams are added to SQLite DSNs unless the DSN sets them itself:
+// concurrent runs wait for the write lock instead of failing, foreign keys are
+// enforced, and times are stored in a format SQLite's date functions
+// understand.
+var sqliteParams = []string{"_pragma=busy_timeout(10000)", "_pragma=foreign_keys(1)", "_time_format=sqlite"}
+
+// postgresKeywords are libpq connection keywords that mark a key/value DSN,
+// such as "host=db dbname=infralog", as Postgres.
+var postgresKeywords = []string{"host", "hostaddr", "port", "dbname", "user", "password", "sslmode", "application_name", "connect_timeout"}
+
+// Run is a recorded infralog run and its change counts.
+type Run struct {
+	ID               string
+	Datetime         time.Time
+	RepoURL          string
+	Branch           string
+	CommitSHA        string
+	Committer        string
+	TerraformVersion string
+	Resources        int
+	Added            int
+	Changed          int
+	Replaced         int
+	Removed          int
+	Outputs          int
+	ResourceChanges  []ResourceChange
+}
+
+// ResourceChange is a resource change of a run.
+type ResourceChange struct {
+	Address      string
+	Type         string
+	Module       string
+	Action       string // added, changed, replaced or removed
+	Actions      string // Terraform actions, e.g. delete,create
+	ActionReason string
+}
+
+// Store records runs in SQLite or Postgres.
+type Store struct {
+	db     *sql.DB
+	driver string
+}
+
+// DetectDriver returns the driver for a DSN: postgres for postgres:// and
+// postgresql:// URLs and for key/value strings such as
+// "host=db dbname=infralog", sqlite otherwise.
+func DetectDriver(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") || isKeyValueDSN(dsn) {
+		return DriverPostgres
+	}
+	return DriverSQLite
+}
+
+// isKeyValueDSN reports whether dsn is a libpq key/value connection string:
+// space-separated key=value pairs, at least one with a libpq keyword.
+func isKeyValueDSN(dsn string) bool {
+	fields := strings.Fields(dsn)
+	if len(fields) == 0 {
+		return false
+	}
+
+	known := false
+	for _, field := range fields {
+		key, _, ok := strings.Cut(field, "=")
+		if !ok {
+			return false
+		}
+		if slices.Contains(postgresKeywords, strings.TrimSpace(key)) {
+			known = true
+		}
+	}
+	return known
+}
+
+// Open opens the database and applies pending migrations. For SQLite the DSN
+// is a file path, for Postgres a connection URL or key/value string.
+func Open(ctx context.Context, driver, dsn string) (*Store, error) {
+	var driverName string
+	switch driver {
+	case DriverSQLite:
+		driverName = "sqlite"
+		dsn = sqliteDSN(dsn)
+	case DriverPostgres:
+		driverName = "pgx"
+	default:
+		return nil, fmt.Errorf("invalid store driver: %s. Driver must be sqlite or postgres", driver)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s store: %w", driver, err)
+	}
+
+	s := &Store{db: db, driver: driver}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// sqliteDSN adds the sqliteParams that a SQLite DSN doesn't set. Pragmas are
+// matched by name, so "_pragma=busy_timeout(500)" replaces the default busy
+// timeout but keeps the other defaults.
+func sqliteDSN(dsn string) string {
+	_, query, _ := strings.Cut(dsn, "?")
+	set := map[string]bool{}
+	for _, param := range strings.Split(query, "&") {
+		set[sqliteParamName(param)] = true
+	}
+
+	var missing []string
+	for _, param := range sqliteParams {
+		if !set[sqliteParamName(param)] {
+			missing = append(missing, param)
+		}
+	}
+	if len(missing) == 0 {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+		if strings.HasSuffix(dsn, "?") || strings.HasSuffix(dsn, "&") {
+			separator = ""
+		}
+	}
+	return dsn + separator + strings.Join(missing, "&")
+}
+
+// sqliteParamName returns the name a SQLite DSN parameter sets: the pragma
+// name for _pragma, e.g. _pragma.busy_timeout, the key otherwise.
+func sqliteParamName(param string) string {
+	key, value, _ := strings.Cut(param, "=")
+	if key != "_pragma" {
+		return key
+	}
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		value = unescaped
+	}
+	name, _, _ := strings.Cut(value, "(")
+	name, _, _ = strings.Cut(name, "=")
+	return "_pragma." + strings.ToLower(strings.TrimSpace(name))
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun records a run and its resource changes in one transaction.
+func (s *Store) SaveRun(ctx context.Context, run *Run) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO runs
+		(id, datetime, repo_url, branch, commit_sha, committer, terraform_version, resources, added, changed, replaced, removed, outputs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		run.ID, run.Datetime.UTC(), run.RepoURL, run.Branch, run.CommitSHA, run.Committer, run.TerraformVersion,
+		run.Resources, run.Added, run.Changed, run.Replaced, run.Removed, run.Outputs)
+	if err != nil {
+		return fmt.Errorf("error saving run %s: %w", run.ID, err)
+	}
+
+	for i, rc := range run.ResourceChanges {
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO resource_changes
+			(run_id, position, address, type, module, action, actions, action_reason)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			run.ID, i, rc.Address, rc.Type, rc.Module, rc.Action, rc.Actions, rc.ActionReason)
+		if err != nil {
+			return fmt.Errorf("error saving resource change %s: %w", rc.Address, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error saving run %s: %w", run.ID, err)
+	}
+	return nil
+}
+
+// Runs returns up to limit runs, newest first, without their resource changes.
+func (s *Store) Runs(ctx context.Context, limit int) ([]Run, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT
+		id, datetime, repo_url, branch, commit_sha, committer, terraform_version, resources, added, changed, replaced, removed, outputs
+		FROM runs ORDER BY datetime DESC, id LIMIT ?`), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		if err := rows.Scan(&r.ID, &r.Datetime, &r.RepoURL, &r.Branch, &r.CommitSHA, &r.Committer, &r.TerraformVersion,
+			&r.Resources, &r.Added, &r.Changed, &r.Replaced, &r.Removed, &r.Outputs); err != nil {
+			return nil, fmt.Errorf("error reading run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying runs: %w", err)
+	}
+	return runs, nil
+}
+
+// ResourceChanges returns the resource changes of a run in plan order.
+func (s *Store) ResourceChanges(ctx context.Context, runID string) ([]ResourceChange, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT
+		address, type, module, action, actions, action_reason
+		FROM resource_changes WHERE run_id = ? ORDER BY position`), runID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying resource changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []ResourceChange
+	for rows.Next() {
+		var rc ResourceChange
+		if err := rows.Scan(&rc.Address, &rc.Type, &rc.Module, &rc.Action, &rc.Actions, &rc.ActionReason); err != nil {
+			return nil, fmt.Errorf("error reading resource change: %w", err)
+		}
+		changes = append(changes, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying resource changes: %w", err)
+	}
+	return changes, nil
+}
+
+// rebind replaces ? placeholders with $1, $2, ... for Postgres.
+func (s *Store) rebind(query string) string {
+	if s.driver != DriverPostgres {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testRun(id string, datetime time.Time) *Run {
+	return &Run{
+		ID:               id,
+		Datetime:         datetime,
+		RepoURL:          "git@github.com:company/infrastructure.git",
+		Branch:           "main",
+		CommitSHA:        "abc123def456",
+		Committer:        "Jane Doe",
+		TerraformVersion: "1.9.0",
+		Resources:        2,
+		Added:            1,
+		Removed:          1,
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_s3_bucket.data", Type: "aws_s3_bucket", Action: "added", Actions: "create"},
+			{Address: "module.web.aws_instance.web", Type: "aws_instance", Module: "module.web", Action: "removed", Actions: "delete", ActionReason: "delete_because_no_resource_config"},
+		},
+	}
+}
+
+func openTestStore(t *testing.T, driver, dsn string) *Store {
+	t.Helper()
+
+	s, err := Open(context.Background(), driver, dsn)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func testSaveRun(t *testing.T, s *Store) {
+	ctx := context.Background()
+	older := testRun("run-1", time.Date(2025, 12, 11, 10, 30, 45, 0, time.UTC))
+	newer := testRun("run-2", time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC))
+
+	for _, run := range []*Run{older, newer} {
+		if err := s.SaveRun(ctx, run); err != nil {
+			t.Fatalf("SaveRun() error = %v", err)
+		}
+	}
+
+	runs, err := s.Runs(ctx, 10)
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != "run-2" || runs[1].ID != "run-1" {
+		t.Fatalf("Expected runs newest first, got %+v", runs)
+	}
+
+	got := runs[0]
+	if !got.Datetime.Equal(newer.Datetime) {
+		t.Errorf("Expected datetime %v, got %v", newer.Datetime, got.Datetime)
+	}
+	got.Datetime = newer.Datetime
+	want := *newer
+	want.ResourceChanges = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Runs()[0] = %+v, want %+v", got, want)
+	}
+
+	changes, err := s.ResourceChanges(ctx, "run-2")
+	if err != nil {
+		t.Fatalf("ResourceChanges() error = %v", err)
+	}
+	if !reflect.DeepEqual(changes, newer.ResourceChanges) {
+		t.Errorf("ResourceChanges() = %+v, want %+v", changes, newer.ResourceChanges)
+	}
+
+	if err := s.SaveRun(ctx, older); err == nil {
+		t.Error("Expected an error for a duplicate run ID")
+	}
+	if runs, _ := s.Runs(ctx, 1); len(runs) != 1 {
+		t.Errorf("Expected the limit to apply, got %d runs", len(runs))
+	}
+}
+
+func TestStore_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infralog.db")
+	testSaveRun(t, openTestStore(t, DriverSQLite, path))
+
+	// Reopening applies no migration twice and keeps the history.
+	s := openTestStore(t, DriverSQLite, path)
+	var version int
+	if err := s.db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil || version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d (%v)", len(migrations), version, err)
+	}
+	if runs, err := s.Runs(context.Background(), 10); err != nil || len(runs) != 2 {
+		t.Errorf("Expected 2 runs after reopening, got %d (%v)", len(runs), err)
+	}
+}
+
+// TestStore_Postgres runs against the database in INFRALOG_TEST_POSTGRES_DSN,
+// which must be empty.
+func TestStore_Postgres(t *testing.T) {
+	dsn := os.Getenv("INFRALOG_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("INFRALOG_TEST_POSTGRES_DSN is not set")
+	}
+	testSaveRun(t, openTestStore(t, DriverPostgres, dsn))
+}
+
+func TestOpen_InvalidDriver(t *testing.T) {
+	if _, err := Open(context.Background(), "mysql", "infralog"); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
+
+func TestDetectDriver(t *testing.T) {
+	tests := map[string]string{
+		"infralog.db":                              DriverSQLite,
+		"/var/lib/infralog/history.db":             DriverSQLite,
+		"postgres://infralog@db:5432/infralog":     DriverPostgres,
+		"postgresql://infralog@db/infralog?ssl=on": DriverPostgres,
+		"host=db dbname=infralog":                  DriverPostgres,
+		"host=db port=5432 user=infralog":          DriverPostgres,
+		"dbname=infralog":                          DriverPostgres,
+		"file:infralog.db?cache=shared":            DriverSQLite,
+		"runs=history.db":                          DriverSQLite,
+		"":                                         DriverSQLite,
+	}
+	for dsn, expected := range tests {
+		if got := DetectDriver(dsn); got != expected {
+			t.Errorf("DetectDriver(%q) = %s, want %s", dsn, got, expected)
+		}
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	defaults := "_pragma=busy_timeout(10000)&_pragma=foreign_keys(1)&_time_format=sqlite"
+
+	tests := []struct {
+		dsn      string
+		expected string
+	}{
+		{"infralog.db", "infralog.db?" + defaults},
+		{"infralog.db?", "infralog.db?" + defaults},
+		{"infralog.db?_pragma=journal_mode(WAL)", "infralog.db?_pragma=journal_mode(WAL)&" + defaults},
+		{"infralog.db?_pragma=busy_timeout(500)", "infralog.db?_pragma=busy_timeout(500)&_pragma=foreign_keys(1)&_time_format=sqlite"},
+		{"infralog.db?_pragma=FOREIGN_KEYS%3D0&_time_format=sqlite", "infralog.db?_pragma=FOREIGN_KEYS%3D0&_time_format=sqlite&_pragma=busy_timeout(10000)"},
+		{"infralog.db?" + defaults, "infralog.db?" + defaults},
+	}
+
+	for _, tt := range tests {
+		if got := sqliteDSN(tt.dsn); got != tt.expected {
+			t.Errorf("sqliteDSN(%q) = %q, want %q", tt.dsn, got, tt.expected)
+		}
+	}
+}
+
+func TestOpen_SQLiteParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infralog.db")
+	s := openTestStore(t, DriverSQLite, path+"?_pragma=journal_mode(WAL)")
+
+	var foreignKeys int
+	if err := s.db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || foreignKeys != 1 {
+		t.Errorf("Expected foreign keys to stay enabled with custom parameters, got %d (%v)", foreignKeys, err)
+	}
+	var journalMode string
+	if err := s.db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil || journalMode != "wal" {
+		t.Errorf("Expected the custom journal mode, got %q (%v)", journalMode, err)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM runs WHERE branch = ? AND repo_url = ?"
+
+	if got := (&Store{driver: DriverSQLite}).rebind(query); got != query {
+		t.Errorf("Expected SQLite query unchanged, got %s", got)
+	}
+	if got := (&Store{driver: DriverPostgres}).rebind(query); got != "SELECT * FROM runs WHERE branch = $1 AND repo_url = $2" {
+		t.Errorf("Unexpected Postgres query %s", got)
+	}
+}
@@ -0,0 +1,168 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"text/template"
+	"time"
+
+	amqpgo "github.com/rabbitmq/amqp091-go"
+)
+
+const publishTimeout = 30 * time.Second
+
+// publisher publishes messages on an AMQP channel.
+type publisher interface {
+	Publish(ctx context.Context, exchange, key string, msg amqpgo.Publishing) error
+	Close()
+}
+
+type AMQPTarget struct {
+	exchange    string
+	routingKey  *template.Template
+	perResource bool
+	cloudEvents *target.CloudEvents
+	connect     func() (publisher, error)
+}
+
+func New(cfg config.AMQPConfig) (*AMQPTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("amqp URL is required")
+	}
+	if cfg.RoutingKey == "" {
+		return nil, fmt.Errorf("amqp routing key is required")
+	}
+
+	routingKey, err := target.ParseTemplate("routing_key", cfg.RoutingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cloudEvents, err := target.NewCloudEvents(cfg.CloudEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AMQPTarget{
+		exchange:    cfg.Exchange,
+		routingKey:  routingKey,
+		perResource: cfg.MessagePerResource,
+		cloudEvents: cloudEvents,
+		connect: func() (publisher, error) {
+			return dial(cfg.URL)
+		},
+	}, nil
+}
+
+// Write publishes the payload, or one message per resource change, as
+// persistent messages and waits for the broker to confirm each of them.
+func (t *AMQPTarget) Write(p *target.Payload) error {
+	routingKey, err := target.ExecuteTemplate(t.routingKey, p)
+	if err != nil {
+		return err
+	}
+
+	var msgs []amqpgo.Publishing
+	for _, m := range target.NewMessages(p, t.perResource) {
+		body, attributes, err := target.EncodeMessage(p, m, t.cloudEvents, "cloudEvents:")
+		if err != nil {
+			return err
+		}
+
+		// The content type is a message property rather than a header.
+		contentType := attributes["content-type"]
+		delete(attributes, "content-type")
+
+		headers := amqpgo.Table{}
+		for key, value := range attributes {
+			headers[key] = value
+		}
+
+		msgs = append(msgs, amqpgo.Publishing{
+			Headers:      headers,
+			ContentType:  contentType,
+			DeliveryMode: amqpgo.Persistent,
+			MessageId:    m.ID,
+			Timestamp:    m.Datetime,
+			Type:         m.Type,
+			AppId:        "infralog",
+			Body:         body,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	pub, err := t.connect()
+	if err != nil {
+		return fmt.Errorf("error connecting to amqp broker: %w", err)
+	}
+	defer pub.Close()
+
+	for _, msg := range msgs {
+		if err := pub.Publish(ctx, t.exchange, routingKey, msg); err != nil {
+			return fmt.Errorf("error publishing to amqp exchange %q with routing key %q: %w", t.exchange, routingKey, err)
+		}
+	}
+	return nil
+}
+
+// channelPublisher publishes mandatory messages on a channel in confirm mode.
+type channelPublisher struct {
+	conn    *amqpgo.Connection
+	ch      *amqpgo.Channel
+	returns chan amqpgo.Return
+}
+
+func dial(url string) (*channelPublisher, error) {
+	conn, err := amqpgo.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error enabling publisher confirms: %w", err)
+	}
+
+	return &channelPublisher{
+		conn:    conn,
+		ch:      ch,
+		returns: ch.NotifyReturn(make(chan amqpgo.Return, 1)),
+	}, nil
+}
+
+func (p *channelPublisher) Publish(ctx context.Context, exchange, key string, msg amqpgo.Publishing) error {
+	confirmation, err := p.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, true, false, msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("message was rejected by the broker")
+	}
+
+	// The broker returns unroutable mandatory messages before confirming them.
+	select {
+	case r := <-p.returns:
+		return fmt.Errorf("message was returned by the broker: %d %s", r.ReplyCode, r.ReplyText)
+	default:
+		return nil
+	}
+}
+
+func (p *channelPublisher) Close() {
+	p.ch.Close()
+	p.conn.Close()
+}
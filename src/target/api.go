@@ -0,0 +1,75 @@
+package target
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APITimeout bounds each request to a REST API, so an unresponsive server
+// can't stall a run.
+const APITimeout = 30 * time.Second
+
+// APIClient sends JSON requests to a REST API, such as a code host or issue
+// tracker.
+type APIClient struct {
+	client    *http.Client
+	authorize func(req *http.Request)
+}
+
+// NewAPIClient creates a client. authorize is called for every request to
+// set authentication and any API-specific headers; it may override the
+// default Accept header of application/json.
+func NewAPIClient(authorize func(req *http.Request)) *APIClient {
+	return &APIClient{
+		client:    &http.Client{Timeout: APITimeout},
+		authorize: authorize,
+	}
+}
+
+// Do sends in, if non-nil, as the JSON request body and decodes the JSON
+// response into out, if non-nil. Unsuccessful responses are returned as
+// errors that include the start of the response body, which most APIs use to
+// explain validation failures.
+func (c *APIClient) Do(method, url string, in, out interface{}) error {
+	var reqBody io.Reader
+	if in != nil {
+		jsonData, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error marshaling request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s failed with status code: %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+	}
+	return nil
+}
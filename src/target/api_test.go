@@ -0,0 +1,88 @@
+package target
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIClient_Do(t *testing.T) {
+	var received map[string]string
+	var contentType, accept, auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, accept, auth = r.Header.Get("Content-Type"), r.Header.Get("Accept"), r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.Write([]byte(`{"id":"42"}`))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer secret")
+	})
+	if client.client.Timeout != APITimeout {
+		t.Errorf("Expected a %s timeout, got %s", APITimeout, client.client.Timeout)
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := client.Do("POST", server.URL+"/items", map[string]string{"name": "web"}, &out); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if received["name"] != "web" {
+		t.Errorf("Unexpected request body: %v", received)
+	}
+	if contentType != "application/json" || accept != "application/json" {
+		t.Errorf("Unexpected content type %q or accept %q", contentType, accept)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected authorize callback to set the header, got %q", auth)
+	}
+	if out.ID != "42" {
+		t.Errorf("Expected decoded response, got %+v", out)
+	}
+}
+
+func TestAPIClient_Do_NoBody(t *testing.T) {
+	var contentType, accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, accept = r.Header.Get("Content-Type"), r.Header.Get("Accept")
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(func(req *http.Request) {
+		req.Header.Set("Accept", "application/vnd.example+json")
+	})
+	if err := client.Do("GET", server.URL, nil, nil); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if contentType != "" {
+		t.Errorf("Expected no content type without a body, got %q", contentType)
+	}
+	if accept != "application/vnd.example+json" {
+		t.Errorf("Expected authorize callback to override accept, got %q", accept)
+	}
+}
+
+func TestAPIClient_Do_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errors":{"customfield_10001":"Field does not exist"}}`))
+	}))
+	defer server.Close()
+
+	err := NewAPIClient(func(*http.Request) {}).Do("POST", server.URL+"/issue", map[string]string{}, nil)
+	if err == nil {
+		t.Fatal("Expected an error but got none")
+	}
+	if !strings.Contains(err.Error(), "POST /issue failed with status code: 400") || !strings.Contains(err.Error(), "customfield_10001") {
+		t.Errorf("Expected status and response body in error, got %v", err)
+	}
+}
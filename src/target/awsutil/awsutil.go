@@ -0,0 +1,151 @@
+package awsutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"infralog/target"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	// MaxMessageSize is the maximum size of a message, and of a batch of
+	// messages, accepted by SNS, SQS and EventBridge.
+	MaxMessageSize = 256 * 1024
+
+	// MaxBatchEntries is the maximum number of messages in a batch request.
+	MaxBatchEntries = 10
+)
+
+// LoadConfig loads the AWS configuration from the standard credential chain
+// (environment, shared config and credentials files, SSO, web identity and
+// instance or container roles). The region defaults to AWS_REGION or the
+// shared config.
+func LoadConfig(ctx context.Context, region string) (aws.Config, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("error loading aws configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, fmt.Errorf("aws region is required")
+	}
+	return cfg, nil
+}
+
+// Attribute is an SNS or SQS message attribute.
+type Attribute struct {
+	DataType string
+	Value    string
+}
+
+// Summary describes the plan for filtering and routing, as message attributes
+// or as part of an event.
+type Summary struct {
+	Resources int      `json:"resources"`
+	Added     int      `json:"added"`
+	Changed   int      `json:"changed"`
+	Replaced  int      `json:"replaced"`
+	Removed   int      `json:"removed"`
+	Actions   []string `json:"actions"` // Distinct actions in the plan, e.g. ["added", "removed"]
+}
+
+// Summarize returns the summary of a payload's plan.
+func Summarize(p *target.Payload) Summary {
+	s := target.Summarize(p.Plan)
+	summary := Summary{
+		Resources: s.Resources,
+		Added:     s.Added,
+		Changed:   s.Changed,
+		Replaced:  s.Replaced,
+		Removed:   s.Removed,
+		Actions:   []string{},
+	}
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		if len(groups[status]) > 0 {
+			summary.Actions = append(summary.Actions, status)
+		}
+	}
+	return summary
+}
+
+// MessageAttributes returns the SNS and SQS attributes of a message. Both
+// services accept at most 10 attributes, so plan messages carry the counts
+// per action and resource change messages carry the resource's address and
+// action instead.
+func MessageAttributes(p *target.Payload, m target.Message) map[string]Attribute {
+	s := Summarize(p)
+	attributes := map[string]Attribute{
+		"infralog-type":      {"String", m.Type},
+		"infralog-resources": {"Number", strconv.Itoa(s.Resources)},
+	}
+
+	if m.Type == target.MessageTypeResourceChange {
+		attributes["infralog-address"] = Attribute{"String", m.Attributes["infralog-address"]}
+		attributes["infralog-action"] = Attribute{"String", m.Attributes["infralog-action"]}
+	} else {
+		attributes["infralog-added"] = Attribute{"Number", strconv.Itoa(s.Added)}
+		attributes["infralog-changed"] = Attribute{"Number", strconv.Itoa(s.Changed)}
+		attributes["infralog-replaced"] = Attribute{"Number", strconv.Itoa(s.Replaced)}
+		attributes["infralog-removed"] = Attribute{"Number", strconv.Itoa(s.Removed)}
+		actions, _ := json.Marshal(s.Actions)
+		attributes["infralog-actions"] = Attribute{"String.Array", string(actions)}
+	}
+
+	// Attribute values must not be empty.
+	for _, name := range []string{"infralog-branch", "infralog-commit-sha"} {
+		if value := m.Attributes[name]; value != "" {
+			attributes[name] = Attribute{"String", value}
+		}
+	}
+
+	return attributes
+}
+
+// AttributesSize returns the size of message attributes as counted towards
+// the message size limit.
+func AttributesSize(attributes map[string]Attribute) int {
+	size := 0
+	for name, a := range attributes {
+		size += len(name) + len(a.DataType) + len(a.Value)
+	}
+	return size
+}
+
+// Split returns the messages to publish for a payload: a single plan message,
+// or one message per resource change if perResource is set or the plan
+// message is larger than MaxMessageSize as measured by size. It is an error
+// for a resource change message to exceed the limit.
+func Split(p *target.Payload, perResource bool, size func(target.Message) (int, error)) ([]target.Message, error) {
+	if !perResource {
+		messages := target.NewMessages(p, false)
+		n, err := size(messages[0])
+		if err != nil {
+			return nil, err
+		}
+		if n <= MaxMessageSize {
+			return messages, nil
+		}
+	}
+
+	messages := target.NewMessages(p, true)
+	for _, m := range messages {
+		n, err := size(m)
+		if err != nil {
+			return nil, err
+		}
+		if n > MaxMessageSize {
+			return nil, fmt.Errorf("change to %s is %d bytes, more than the %d byte message limit", m.Attributes["infralog-address"], n, MaxMessageSize)
+		}
+	}
+	return messages, nil
+}
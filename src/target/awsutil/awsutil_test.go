@@ -0,0 +1,96 @@
+package awsutil
+
+import (
+	"infralog/target"
+	"infralog/target/targettest"
+	"strings"
+	"testing"
+)
+
+func bodySize(m target.Message) (int, error) {
+	body, err := m.Body()
+	return len(body), err
+}
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+
+	if s.Resources != 2 || s.Added != 1 || s.Removed != 1 {
+		t.Errorf("Unexpected counts: %+v", s)
+	}
+	if strings.Join(s.Actions, ",") != "added,removed" {
+		t.Errorf("Expected actions added and removed, got %v", s.Actions)
+	}
+}
+
+func TestMessageAttributes(t *testing.T) {
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))
+	p.Metadata.Git = nil
+
+	for _, m := range target.NewMessages(p, true) {
+		attributes := MessageAttributes(p, m)
+		if attributes["infralog-address"].Value != m.Attributes["infralog-address"] || attributes["infralog-action"].Value == "" {
+			t.Errorf("Expected address and action attributes, got %v", attributes)
+		}
+		if _, ok := attributes["infralog-branch"]; ok {
+			t.Error("Expected no branch attribute without git metadata")
+		}
+		if len(attributes) > 10 {
+			t.Errorf("Expected at most 10 attributes, got %d", len(attributes))
+		}
+	}
+
+	m := target.NewMessages(p, false)[0]
+	attributes := MessageAttributes(p, m)
+	if attributes["infralog-actions"] != (Attribute{"String.Array", `["added","removed"]`}) {
+		t.Errorf("Unexpected actions attribute %v", attributes["infralog-actions"])
+	}
+	if attributes["infralog-removed"] != (Attribute{"Number", "1"}) {
+		t.Errorf("Unexpected removed attribute %v", attributes["infralog-removed"])
+	}
+}
+
+func TestSplit(t *testing.T) {
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))
+
+	messages, err := Split(p, false, bodySize)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(messages) != 1 || messages[0].Type != target.MessageTypePlan {
+		t.Errorf("Expected a single plan message, got %d", len(messages))
+	}
+
+	messages, err = Split(p, true, bodySize)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("Expected a message per resource, got %d", len(messages))
+	}
+}
+
+func TestSplit_FallsBackToPerResource(t *testing.T) {
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))
+
+	// Plan messages are larger than the limit, resource messages are not.
+	size := func(m target.Message) (int, error) {
+		if m.Type == target.MessageTypePlan {
+			return MaxMessageSize + 1, nil
+		}
+		return 1, nil
+	}
+
+	messages, err := Split(p, false, size)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(messages) != 2 || messages[0].Type != target.MessageTypeResourceChange {
+		t.Errorf("Expected a message per resource, got %+v", messages)
+	}
+
+	_, err = Split(p, false, func(target.Message) (int, error) { return MaxMessageSize + 1, nil })
+	if err == nil || !strings.Contains(err.Error(), "aws_s3_bucket.data") {
+		t.Errorf("Expected an error naming the resource, got %v", err)
+	}
+}
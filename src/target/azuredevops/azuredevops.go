@@ -0,0 +1,149 @@
+package azuredevops
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	apiVersion = "7.1"
+
+	// Azure DevOps rejects comments longer than 150000 characters.
+	maxCommentLength = 150000
+
+	// Thread status used for new threads. Closed threads do not block
+	// completion when the "check for comment resolution" policy is enabled.
+	threadStatusClosed = "closed"
+)
+
+type AzureDevOpsTarget struct {
+	url    string
+	marker string
+	api    *target.APIClient
+}
+
+type thread struct {
+	ID       int64           `json:"id,omitempty"`
+	Comments []threadComment `json:"comments"`
+	Status   string          `json:"status,omitempty"`
+}
+
+type threadComment struct {
+	ID              int64  `json:"id,omitempty"`
+	ParentCommentID int64  `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     string `json:"commentType,omitempty"`
+	IsDeleted       bool   `json:"isDeleted,omitempty"`
+}
+
+func New(cfg config.AzureDevOpsConfig) (*AzureDevOpsTarget, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("azure devops token is required")
+	}
+
+	organizationURL := cfg.OrganizationURL
+	if organizationURL == "" {
+		organizationURL = os.Getenv("SYSTEM_COLLECTIONURI")
+	}
+	project := cfg.Project
+	if project == "" {
+		project = os.Getenv("SYSTEM_TEAMPROJECT")
+	}
+	repository := cfg.Repository
+	if repository == "" {
+		repository = os.Getenv("BUILD_REPOSITORY_NAME")
+	}
+	if organizationURL == "" || project == "" || repository == "" {
+		return nil, fmt.Errorf("azure devops organization URL, project and repository are required")
+	}
+
+	pullRequest := cfg.PullRequest
+	if pullRequest == 0 {
+		pullRequest, _ = strconv.Atoi(os.Getenv("SYSTEM_PULLREQUEST_PULLREQUESTID"))
+	}
+	if pullRequest <= 0 {
+		return nil, fmt.Errorf("azure devops pull request ID is required: set it in the config or run in a pull request build")
+	}
+
+	return &AzureDevOpsTarget{
+		url: fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullRequests/%d/threads",
+			strings.TrimSuffix(organizationURL, "/"), url.PathEscape(project), url.PathEscape(repository), pullRequest),
+		marker: target.CommentMarker(cfg.Marker),
+		api: target.NewAPIClient(func(req *http.Request) {
+			req.SetBasicAuth("", cfg.Token)
+		}),
+	}, nil
+}
+
+// ReportsEmptyPlans reports that plans without changes are written too, so
+// a comment left by an earlier plan can be updated.
+func (t *AzureDevOpsTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write posts the plan summary as a pull request comment thread, updating the
+// previous Infralog comment if there is one. Plans without changes only
+// update an existing comment.
+func (t *AzureDevOpsTarget) Write(p *target.Payload) error {
+	body := target.RenderMarkdown(p, target.MarkdownOptions{
+		Marker:      t.marker,
+		Collapsible: true,
+		MaxLength:   maxCommentLength,
+	})
+
+	post := target.UpsertComment
+	if !p.Plan.HasChanges() {
+		post = target.UpdateExistingComment
+	}
+	if err := post(t, t.marker, body); err != nil {
+		return fmt.Errorf("error posting azure devops comment: %w", err)
+	}
+	return nil
+}
+
+// FindComment returns the "<thread>/<comment>" ID of the first comment
+// containing the marker. The threads endpoint is not paginated.
+func (t *AzureDevOpsTarget) FindComment(marker string) (string, error) {
+	var threads struct {
+		Value []thread `json:"value"`
+	}
+	if err := t.api.Do("GET", t.endpoint(""), nil, &threads); err != nil {
+		return "", err
+	}
+
+	for _, th := range threads.Value {
+		for _, c := range th.Comments {
+			if !c.IsDeleted && strings.Contains(c.Content, marker) {
+				return fmt.Sprintf("%d/%d", th.ID, c.ID), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (t *AzureDevOpsTarget) CreateComment(body string) error {
+	return t.api.Do("POST", t.endpoint(""), thread{
+		Comments: []threadComment{{Content: body, CommentType: "text"}},
+		Status:   threadStatusClosed,
+	}, nil)
+}
+
+func (t *AzureDevOpsTarget) UpdateComment(id, body string) error {
+	threadID, commentID, ok := strings.Cut(id, "/")
+	if !ok {
+		return fmt.Errorf("invalid comment ID: %s", id)
+	}
+	return t.api.Do("PATCH", t.endpoint("/"+threadID+"/comments/"+commentID), threadComment{Content: body}, nil)
+}
+
+// endpoint returns the URL of path below the pull request threads, with the
+// API version every Azure DevOps request requires.
+func (t *AzureDevOpsTarget) endpoint(path string) string {
+	return t.url + path + "?api-version=" + apiVersion
+}
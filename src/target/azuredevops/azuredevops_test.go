@@ -0,0 +1,186 @@
+package azuredevops
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const threadsPath = "/example/Platform/_apis/git/repositories/infra/pullRequests/99/threads"
+
+func TestNew(t *testing.T) {
+	for _, env := range []string{"SYSTEM_COLLECTIONURI", "SYSTEM_TEAMPROJECT", "BUILD_REPOSITORY_NAME", "SYSTEM_PULLREQUEST_PULLREQUESTID"} {
+		t.Setenv(env, "")
+	}
+
+	valid := config.AzureDevOpsConfig{
+		Token:           "ado-pat",
+		OrganizationURL: "https://dev.azure.com/example",
+		Project:         "Platform",
+		Repository:      "infra",
+		PullRequest:     99,
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(cfg *config.AzureDevOpsConfig)
+		expectError bool
+	}{
+		{"Valid config", func(cfg *config.AzureDevOpsConfig) {}, false},
+		{"Missing token", func(cfg *config.AzureDevOpsConfig) { cfg.Token = "" }, true},
+		{"Missing organization", func(cfg *config.AzureDevOpsConfig) { cfg.OrganizationURL = "" }, true},
+		{"Missing project", func(cfg *config.AzureDevOpsConfig) { cfg.Project = "" }, true},
+		{"Missing pull request", func(cfg *config.AzureDevOpsConfig) { cfg.PullRequest = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			target, err := New(cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNew_FromPipelinesEnvironment(t *testing.T) {
+	t.Setenv("SYSTEM_COLLECTIONURI", "https://dev.azure.com/example/")
+	t.Setenv("SYSTEM_TEAMPROJECT", "Platform Team")
+	t.Setenv("BUILD_REPOSITORY_NAME", "infra")
+	t.Setenv("SYSTEM_PULLREQUEST_PULLREQUESTID", "99")
+
+	adoTarget, err := New(config.AzureDevOpsConfig{Token: "ado-pat"})
+	if err != nil {
+		t.Fatalf("Failed to create azure devops target: %v", err)
+	}
+	expected := "https://dev.azure.com/example/Platform%20Team/_apis/git/repositories/infra/pullRequests/99/threads"
+	if adoTarget.url != expected {
+		t.Errorf("Expected URL %s, got %s", expected, adoTarget.url)
+	}
+}
+
+func TestWrite_CreatesThenUpdatesComment(t *testing.T) {
+	var threads []thread
+	var password string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, _ = r.BasicAuth()
+		if r.URL.Query().Get("api-version") != apiVersion {
+			t.Errorf("Expected api-version %s, got %q", apiVersion, r.URL.Query().Get("api-version"))
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == threadsPath:
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": threads})
+		case r.Method == "POST" && r.URL.Path == threadsPath:
+			var th thread
+			json.NewDecoder(r.Body).Decode(&th)
+			th.ID = int64(len(threads) + 1)
+			th.Comments[0].ID = 1
+			threads = append(threads, th)
+		case r.Method == "PATCH" && r.URL.Path == threadsPath+"/2/comments/1":
+			var c threadComment
+			json.NewDecoder(r.Body).Decode(&c)
+			threads[1].Comments[0].Content = c.Content
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	threads = []thread{{ID: 1, Comments: []threadComment{{ID: 1, Content: "Please rebase"}}}}
+
+	adoTarget, err := New(config.AzureDevOpsConfig{
+		Token:           "ado-pat",
+		OrganizationURL: server.URL + "/example",
+		Project:         "Platform",
+		Repository:      "infra",
+		PullRequest:     99,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create azure devops target: %v", err)
+	}
+	if !adoTarget.ReportsEmptyPlans() {
+		t.Error("Expected azure devops target to report empty plans")
+	}
+
+	if err := adoTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(threads) != 1 {
+		t.Fatalf("Expected no thread for a plan without changes, got %d threads", len(threads))
+	}
+
+	if err := adoTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(threads) != 2 || threads[1].Status != threadStatusClosed {
+		t.Fatalf("Expected a new closed thread, got %+v", threads)
+	}
+	if password != "ado-pat" {
+		t.Errorf("Expected token in basic auth, got %q", password)
+	}
+
+	if err := adoTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("Expected the existing thread to be updated, got %d threads", len(threads))
+	}
+
+	content := threads[1].Comments[0].Content
+	if !strings.HasPrefix(content, "<!-- infralog -->") || !strings.Contains(content, "`aws_instance.web` | removed") {
+		t.Errorf("Expected updated comment, got:\n%s", content)
+	}
+	if threads[0].Comments[0].Content != "Please rebase" {
+		t.Error("Expected unrelated thread to be left alone")
+	}
+
+	if err := adoTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if content := threads[1].Comments[0].Content; !strings.Contains(content, "No changes.") {
+		t.Errorf("Expected comment to be updated for a plan without changes, got:\n%s", content)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	adoTarget, err := New(config.AzureDevOpsConfig{
+		Token:           "ado-pat",
+		OrganizationURL: server.URL,
+		Project:         "Platform",
+		Repository:      "infra",
+		PullRequest:     99,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create azure devops target: %v", err)
+	}
+	if err := adoTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
@@ -0,0 +1,125 @@
+package azureeventgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	batchContentType = "application/cloudevents-batch+json; charset=utf-8"
+
+	// maxBatchSize is the maximum size of a publish request, and so of a
+	// single event.
+	maxBatchSize = 1024 * 1024
+
+	sendTimeout = 30 * time.Second
+)
+
+type AzureEventGridTarget struct {
+	url         string
+	accessKey   string
+	sasToken    string // Used instead of the access key when set
+	perResource bool
+	events      *target.CloudEvents
+	client      *http.Client
+}
+
+func New(cfg config.AzureEventGridConfig) (*AzureEventGridTarget, error) {
+	if cfg.TopicEndpoint == "" {
+		return nil, fmt.Errorf("azure event grid topic endpoint is required")
+	}
+	if cfg.AccessKey == "" && cfg.SASToken == "" {
+		return nil, fmt.Errorf("azure event grid access key or SAS token is required")
+	}
+
+	// Event Grid topics that accept the CloudEvents schema only accept
+	// structured events.
+	events, err := target.NewCloudEvents(config.CloudEventsConfig{
+		Mode:    target.CloudEventsStructured,
+		Source:  cfg.Source,
+		Subject: cfg.Subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureEventGridTarget{
+		url:         cfg.TopicEndpoint,
+		accessKey:   cfg.AccessKey,
+		sasToken:    cfg.SASToken,
+		perResource: cfg.MessagePerResource,
+		events:      events,
+		client:      &http.Client{Timeout: sendTimeout},
+	}, nil
+}
+
+// Write publishes the payload to the topic as CloudEvents, as one event or
+// as one event per resource change.
+func (t *AzureEventGridTarget) Write(p *target.Payload) error {
+	var events []json.RawMessage
+	for _, m := range target.NewMessages(p, t.perResource) {
+		event, err := t.events.NewEvent(p, m)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error marshaling cloudevent: %w", err)
+		}
+		if eventSize(data)+1 > maxBatchSize {
+			return fmt.Errorf("event %s is %d bytes, more than the azure event grid limit of %d bytes", event.ID, len(data), maxBatchSize)
+		}
+		events = append(events, data)
+	}
+
+	// One byte of the request is left for the opening bracket of the batch.
+	for _, batch := range target.Batch(events, 0, maxBatchSize-1, eventSize) {
+		if err := t.send(batch); err != nil {
+			return fmt.Errorf("error publishing to azure event grid: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *AzureEventGridTarget) send(events []json.RawMessage) error {
+	jsonData, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", batchContentType)
+	if t.sasToken != "" {
+		req.Header.Set("aeg-sas-token", t.sasToken)
+	} else {
+		req.Header.Set("aeg-sas-key", t.accessKey)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("publish failed with status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// eventSize returns the size of an event in a batch, including the comma or
+// bracket that follows it.
+func eventSize(event json.RawMessage) int {
+	return len(event) + 1
+}
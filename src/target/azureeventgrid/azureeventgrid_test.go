@@ -0,0 +1,141 @@
+package azureeventgrid
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer returns a fake Event Grid topic endpoint that records
+// published events and the authentication headers of the last request.
+func newTestServer(t *testing.T, status int) (*httptest.Server, *[]target.CloudEvent, *http.Header) {
+	t.Helper()
+
+	var events []target.CloudEvent
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/events" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != batchContentType {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		headers = r.Header
+
+		var batch []target.CloudEvent
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		events = append(events, batch...)
+
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error":{"code":"Unauthorized","message":"The request authorization key is not authorized."}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &events, &headers
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.AzureEventGridConfig
+		expectError bool
+	}{
+		{"Access key", config.AzureEventGridConfig{TopicEndpoint: "https://infra.westeurope-1.eventgrid.azure.net/api/events", AccessKey: "secret"}, false},
+		{"SAS token", config.AzureEventGridConfig{TopicEndpoint: "https://infra.westeurope-1.eventgrid.azure.net/api/events", SASToken: "r=...&e=...&s=..."}, false},
+		{"Missing endpoint", config.AzureEventGridConfig{AccessKey: "secret"}, true},
+		{"Missing credentials", config.AzureEventGridConfig{TopicEndpoint: "https://infra.westeurope-1.eventgrid.azure.net/api/events"}, true},
+		{"Invalid subject template", config.AzureEventGridConfig{TopicEndpoint: "https://infra.westeurope-1.eventgrid.azure.net/api/events", AccessKey: "secret", Subject: "{{.Git"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if (err != nil) != tt.expectError {
+				t.Errorf("New() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	server, events, headers := newTestServer(t, http.StatusOK)
+
+	tgt, err := New(config.AzureEventGridConfig{TopicEndpoint: server.URL + "/api/events", AccessKey: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if headers.Get("aeg-sas-key") != "secret" || headers.Get("aeg-sas-token") != "" {
+		t.Errorf("Expected the access key in aeg-sas-key, got %v", *headers)
+	}
+	if len(*events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*events))
+	}
+	event := (*events)[0]
+	if event.SpecVersion != "1.0" || event.Type != target.CloudEventTypePlanChanged || event.ID == "" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.Source != "ssh://git@github.com/company/infrastructure.git" || event.Subject != "main" {
+		t.Errorf("Unexpected source %q or subject %q", event.Source, event.Subject)
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	server, events, headers := newTestServer(t, http.StatusOK)
+
+	tgt, err := New(config.AzureEventGridConfig{
+		TopicEndpoint:      server.URL + "/api/events",
+		SASToken:           "r=topic&e=expiry&s=signature",
+		MessagePerResource: true,
+		Source:             "infra",
+		Subject:            "infra/{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if headers.Get("aeg-sas-token") != "r=topic&e=expiry&s=signature" || headers.Get("aeg-sas-key") != "" {
+		t.Errorf("Expected the SAS token in aeg-sas-token, got %v", *headers)
+	}
+	if len(*events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(*events))
+	}
+	for i, event := range *events {
+		if event.Type != target.CloudEventTypeResourceChanged || event.Source != "infra" || event.Subject != "infra/main" {
+			t.Errorf("Event %d: unexpected event %+v", i, event)
+		}
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server, _, _ := newTestServer(t, http.StatusUnauthorized)
+
+	tgt, err := New(config.AzureEventGridConfig{TopicEndpoint: server.URL + "/api/events", AccessKey: "wrong"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil {
+		t.Fatal("Expected an error but got none")
+	}
+	if !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("Expected status and response body in error, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package azureservicebus
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	batchContentType = "application/vnd.microsoft.servicebus.json"
+
+	// maxBatchSize is the maximum size of a batch on the standard tier.
+	maxBatchSize = 256 * 1024
+
+	tokenTTL    = time.Hour
+	sendTimeout = 30 * time.Second
+)
+
+// brokerProperties are the Service Bus system properties of a message.
+type brokerProperties struct {
+	MessageId   string `json:"MessageId"`
+	Label       string `json:"Label"`
+	ContentType string `json:"ContentType"`
+	SessionId   string `json:"SessionId,omitempty"`
+}
+
+type batchMessage struct {
+	Body             string            `json:"Body"`
+	BrokerProperties brokerProperties  `json:"BrokerProperties"`
+	UserProperties   map[string]string `json:"UserProperties"`
+}
+
+type AzureServiceBusTarget struct {
+	url         string // Send endpoint of the queue or topic
+	resourceURI string // Resource URI signed in SAS tokens
+	keyName     string
+	key         string
+	signature   string // Pre-generated SAS token, used instead of the key
+	perResource bool
+	sessionID   *template.Template
+	client      *http.Client
+}
+
+func New(cfg config.AzureServiceBusConfig) (*AzureServiceBusTarget, error) {
+	if cfg.ConnectionString == "" {
+		return nil, fmt.Errorf("azure service bus connection string is required")
+	}
+
+	cs, err := parseConnectionString(cfg.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := cfg.QueueOrTopic
+	if entity == "" {
+		entity = cs["EntityPath"]
+	}
+	if entity == "" {
+		return nil, fmt.Errorf("azure service bus queue or topic is required")
+	}
+	if cs["SharedAccessSignature"] == "" && (cs["SharedAccessKeyName"] == "" || cs["SharedAccessKey"] == "") {
+		return nil, fmt.Errorf("azure service bus connection string must contain SharedAccessKeyName and SharedAccessKey, or SharedAccessSignature")
+	}
+
+	endpoint := cfg.EndpointURL
+	if endpoint == "" {
+		endpoint = cs["Endpoint"]
+		if endpoint == "" {
+			return nil, fmt.Errorf("azure service bus connection string must contain Endpoint")
+		}
+		endpoint = "https://" + strings.TrimPrefix(endpoint, "sb://")
+	}
+	resourceURI := strings.TrimSuffix(endpoint, "/") + "/" + entity
+
+	var sessionID *template.Template
+	if cfg.SessionID != "" {
+		if sessionID, err = target.ParseTemplate("session_id", cfg.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AzureServiceBusTarget{
+		url:         resourceURI + "/messages",
+		resourceURI: resourceURI,
+		keyName:     cs["SharedAccessKeyName"],
+		key:         cs["SharedAccessKey"],
+		signature:   cs["SharedAccessSignature"],
+		perResource: cfg.MessagePerResource,
+		sessionID:   sessionID,
+		client:      &http.Client{Timeout: sendTimeout},
+	}, nil
+}
+
+// Write sends the payload to the queue or topic, as one message or as one
+// message per resource change.
+func (t *AzureServiceBusTarget) Write(p *target.Payload) error {
+	messages, err := t.buildMessages(p)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range target.Batch(messages, 0, maxBatchSize, messageSize) {
+		if err := t.send(batch); err != nil {
+			return fmt.Errorf("error sending to azure service bus: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *AzureServiceBusTarget) buildMessages(p *target.Payload) ([]batchMessage, error) {
+	var sessionID string
+	if t.sessionID != nil {
+		var err error
+		if sessionID, err = target.ExecuteTemplate(t.sessionID, p); err != nil {
+			return nil, err
+		}
+	}
+
+	counts := target.CountAttributes(p)
+
+	var messages []batchMessage
+	for _, m := range target.NewMessages(p, t.perResource) {
+		body, err := m.Body()
+		if err != nil {
+			return nil, err
+		}
+
+		properties := make(map[string]string, len(m.Attributes)+len(counts))
+		for k, v := range m.Attributes {
+			properties[k] = v
+		}
+		for k, v := range counts {
+			properties[k] = v
+		}
+		// The content type is a broker property.
+		delete(properties, "content-type")
+
+		messages = append(messages, batchMessage{
+			Body: string(body),
+			BrokerProperties: brokerProperties{
+				MessageId:   m.ID,
+				Label:       m.Type,
+				ContentType: "application/json",
+				SessionId:   sessionID,
+			},
+			UserProperties: properties,
+		})
+	}
+	return messages, nil
+}
+
+func (t *AzureServiceBusTarget) send(messages []batchMessage) error {
+	jsonData, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", batchContentType)
+	req.Header.Set("Authorization", t.token(time.Now()))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("send failed with status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// token returns a shared access signature token for the queue or topic.
+func (t *AzureServiceBusTarget) token(now time.Time) string {
+	if t.signature != "" {
+		return t.signature
+	}
+
+	resource := url.QueryEscape(t.resourceURI)
+	expiry := now.Add(tokenTTL).Unix()
+
+	mac := hmac.New(sha256.New, []byte(t.key))
+	fmt.Fprintf(mac, "%s\n%d", resource, expiry)
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%d&skn=%s", resource, url.QueryEscape(signature), expiry, t.keyName)
+}
+
+// parseConnectionString parses a connection string of the form
+// Endpoint=sb://...;SharedAccessKeyName=...;SharedAccessKey=...
+func parseConnectionString(s string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid azure service bus connection string: expected key=value, got %q", key)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// messageSize returns the size of a message counted against the batch size
+// limit: its body and user properties.
+func messageSize(m batchMessage) int {
+	size := len(m.Body)
+	for k, v := range m.UserProperties {
+		size += len(k) + len(v)
+	}
+	return size
+}
@@ -0,0 +1,204 @@
+package azureservicebus
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConnectionString = "Endpoint=sb://infra.servicebus.windows.net/;SharedAccessKeyName=send;SharedAccessKey=c2VjcmV0;EntityPath=infra-changes"
+
+// newTestServer returns a fake Service Bus endpoint that records sent
+// messages.
+func newTestServer(t *testing.T, status int) (*httptest.Server, *[]batchMessage) {
+	t.Helper()
+
+	var messages []batchMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/infra-changes/messages" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != batchContentType {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedAccessSignature ") {
+			t.Errorf("Expected a SAS token, got %q", r.Header.Get("Authorization"))
+		}
+
+		var batch []batchMessage
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		messages = append(messages, batch...)
+
+		w.WriteHeader(status)
+		if status != http.StatusCreated {
+			w.Write([]byte("<Error><Code>404</Code><Detail>The messaging entity could not be found.</Detail></Error>"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &messages
+}
+
+func TestWrite_Success(t *testing.T) {
+	server, messages := newTestServer(t, http.StatusCreated)
+
+	tgt, err := New(config.AzureServiceBusConfig{ConnectionString: testConnectionString, EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(*messages))
+	}
+	m := (*messages)[0]
+
+	var got struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(m.Body), &got); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected message: %+v", got)
+	}
+
+	expectedBroker := brokerProperties{MessageId: got.ID, Label: target.MessageTypePlan, ContentType: "application/json"}
+	if m.BrokerProperties != expectedBroker {
+		t.Errorf("Expected broker properties %+v, got %+v", expectedBroker, m.BrokerProperties)
+	}
+
+	expected := map[string]string{
+		"infralog-branch":     "main",
+		"infralog-commit-sha": "abc123def456",
+		"infralog-resources":  "2",
+		"infralog-added":      "1",
+		"infralog-removed":    "1",
+	}
+	for key, value := range expected {
+		if m.UserProperties[key] != value {
+			t.Errorf("Expected property %s %q, got %q", key, value, m.UserProperties[key])
+		}
+	}
+	if _, ok := m.UserProperties["content-type"]; ok {
+		t.Error("Expected content type as a broker property only")
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	server, messages := newTestServer(t, http.StatusCreated)
+
+	tgt, err := New(config.AzureServiceBusConfig{
+		ConnectionString:   testConnectionString,
+		EndpointURL:        server.URL,
+		MessagePerResource: true,
+		SessionID:          "{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(*messages))
+	}
+	for i, expected := range []string{"aws_s3_bucket.data", "aws_instance.web"} {
+		m := (*messages)[i]
+		if m.UserProperties["infralog-address"] != expected || m.BrokerProperties.SessionId != "main" {
+			t.Errorf("Message %d: unexpected properties %+v %v", i, m.BrokerProperties, m.UserProperties)
+		}
+		if m.BrokerProperties.Label != target.MessageTypeResourceChange {
+			t.Errorf("Message %d: unexpected label %q", i, m.BrokerProperties.Label)
+		}
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusNotFound)
+
+	tgt, err := New(config.AzureServiceBusConfig{ConnectionString: testConnectionString, EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "could not be found") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	tgt, err := New(config.AzureServiceBusConfig{ConnectionString: testConnectionString})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.url != "https://infra.servicebus.windows.net/infra-changes/messages" {
+		t.Errorf("Unexpected URL %q", tgt.url)
+	}
+
+	now := time.Unix(1765535445, 0)
+	token := tgt.token(now)
+	if !strings.HasPrefix(token, "SharedAccessSignature ") {
+		t.Fatalf("Unexpected token %q", token)
+	}
+
+	params, err := url.ParseQuery(strings.TrimPrefix(token, "SharedAccessSignature "))
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	if params.Get("sr") != "https://infra.servicebus.windows.net/infra-changes" {
+		t.Errorf("Unexpected resource %q", params.Get("sr"))
+	}
+	if params.Get("skn") != "send" || params.Get("se") != "1765539045" {
+		t.Errorf("Unexpected key name %q or expiry %q", params.Get("skn"), params.Get("se"))
+	}
+	if params.Get("sig") != "vXQQrz8mQ3f9T0sdfmJ6kpZ2bfWjZQiMa2K6b0eF4KA=" {
+		t.Errorf("Unexpected signature %q", params.Get("sig"))
+	}
+
+	tgt, err = New(config.AzureServiceBusConfig{ConnectionString: "Endpoint=sb://infra.servicebus.windows.net/;SharedAccessSignature=SharedAccessSignature sr=x&sig=y&se=1&skn=send", QueueOrTopic: "infra-changes"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.token(now) != "SharedAccessSignature sr=x&sig=y&se=1&skn=send" {
+		t.Errorf("Expected the pre-generated signature, got %q", tgt.token(now))
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AzureServiceBusConfig
+	}{
+		{"Missing connection string", config.AzureServiceBusConfig{}},
+		{"Invalid connection string", config.AzureServiceBusConfig{ConnectionString: "Endpoint"}},
+		{"Missing queue or topic", config.AzureServiceBusConfig{ConnectionString: "Endpoint=sb://infra.servicebus.windows.net/;SharedAccessKeyName=send;SharedAccessKey=c2VjcmV0"}},
+		{"Missing key", config.AzureServiceBusConfig{ConnectionString: "Endpoint=sb://infra.servicebus.windows.net/;SharedAccessKeyName=send", QueueOrTopic: "infra-changes"}},
+		{"Missing endpoint", config.AzureServiceBusConfig{ConnectionString: "SharedAccessKeyName=send;SharedAccessKey=c2VjcmV0", QueueOrTopic: "infra-changes"}},
+		{"Invalid session ID template", config.AzureServiceBusConfig{ConnectionString: testConnectionString, SessionID: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
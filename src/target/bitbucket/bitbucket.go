@@ -0,0 +1,180 @@
+package bitbucket
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Bitbucket deployment types.
+const (
+	TypeCloud  = "cloud"
+	TypeServer = "server"
+)
+
+const (
+	defaultCloudURL = "https://api.bitbucket.org/2.0"
+
+	// Bitbucket Server rejects comments longer than 32768 characters by
+	// default; the same limit is applied to Bitbucket Cloud.
+	maxCommentLength = 32768
+
+	pageSize = 100
+)
+
+type BitbucketTarget struct {
+	comments target.CommentStore
+	marker   string
+}
+
+// client holds the connection details shared by the Cloud and Server APIs.
+type client struct {
+	baseURL string
+	api     *target.APIClient
+}
+
+func New(cfg config.BitbucketConfig) (*BitbucketTarget, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("bitbucket token is required")
+	}
+
+	workspace := cfg.Workspace
+	if workspace == "" {
+		workspace = os.Getenv("BITBUCKET_WORKSPACE")
+	}
+	repository := cfg.Repository
+	if repository == "" {
+		repository = os.Getenv("BITBUCKET_REPO_SLUG")
+	}
+	if workspace == "" || repository == "" {
+		return nil, fmt.Errorf("bitbucket workspace and repository are required")
+	}
+
+	pullRequest := cfg.PullRequest
+	if pullRequest == 0 {
+		pullRequest, _ = strconv.Atoi(os.Getenv("BITBUCKET_PR_ID"))
+	}
+	if pullRequest <= 0 {
+		return nil, fmt.Errorf("bitbucket pull request ID is required: set it in the config or run in a pull request pipeline")
+	}
+
+	// With a username, the token is sent as an app password using basic
+	// authentication; otherwise it is sent as a bearer token.
+	c := client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		api: target.NewAPIClient(func(req *http.Request) {
+			if cfg.Username != "" {
+				req.SetBasicAuth(cfg.Username, cfg.Token)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+cfg.Token)
+			}
+		}),
+	}
+
+	var comments target.CommentStore
+	switch strings.ToLower(cfg.Type) {
+	case "", TypeCloud:
+		if c.baseURL == "" {
+			c.baseURL = defaultCloudURL
+		}
+		comments = &cloudComments{
+			client: c,
+			url:    fmt.Sprintf("%s/repositories/%s/%s/pullrequests/%d/comments", c.baseURL, workspace, repository, pullRequest),
+		}
+	case TypeServer:
+		if c.baseURL == "" {
+			return nil, fmt.Errorf("bitbucket server base URL is required")
+		}
+		comments = &serverComments{
+			client:   c,
+			url:      fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", c.baseURL, workspace, repository, pullRequest),
+			versions: map[string]int{},
+		}
+	default:
+		return nil, fmt.Errorf("invalid bitbucket type: %s. Type must be cloud or server", cfg.Type)
+	}
+
+	return &BitbucketTarget{
+		comments: comments,
+		marker:   target.ReferenceCommentMarker(cfg.Marker),
+	}, nil
+}
+
+// ReportsEmptyPlans reports that plans without changes are written too, so
+// a comment left by an earlier plan can be updated.
+func (t *BitbucketTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write posts the plan summary as a pull request comment, updating the
+// previous Infralog comment if there is one. Plans without changes only
+// update an existing comment.
+func (t *BitbucketTarget) Write(p *target.Payload) error {
+	// Bitbucket does not render HTML, so diffs are never collapsed.
+	body := target.RenderMarkdown(p, target.MarkdownOptions{
+		Marker:    t.marker,
+		MaxLength: maxCommentLength,
+	})
+
+	post := target.UpsertComment
+	if !p.Plan.HasChanges() {
+		post = target.UpdateExistingComment
+	}
+	if err := post(t.comments, t.marker, body); err != nil {
+		return fmt.Errorf("error posting bitbucket comment: %w", err)
+	}
+	return nil
+}
+
+// cloudComments implements target.CommentStore for Bitbucket Cloud.
+type cloudComments struct {
+	client
+	url string
+}
+
+type cloudComment struct {
+	ID      int64 `json:"id,omitempty"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+func newCloudComment(body string) cloudComment {
+	var c cloudComment
+	c.Content.Raw = body
+	return c
+}
+
+func (s *cloudComments) FindComment(marker string) (string, error) {
+	next := fmt.Sprintf("%s?pagelen=%d", s.url, pageSize)
+	for next != "" {
+		var page struct {
+			Values []cloudComment `json:"values"`
+			Next   string         `json:"next"`
+		}
+		if err := s.api.Do("GET", next, nil, &page); err != nil {
+			return "", err
+		}
+
+		for _, c := range page.Values {
+			if !c.Deleted && strings.Contains(c.Content.Raw, marker) {
+				return strconv.FormatInt(c.ID, 10), nil
+			}
+		}
+		next = page.Next
+	}
+	return "", nil
+}
+
+func (s *cloudComments) CreateComment(body string) error {
+	return s.api.Do("POST", s.url, newCloudComment(body), nil)
+}
+
+func (s *cloudComments) UpdateComment(id, body string) error {
+	return s.api.Do("PUT", s.url+"/"+id, newCloudComment(body), nil)
+}
@@ -0,0 +1,241 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("BITBUCKET_WORKSPACE", "")
+	t.Setenv("BITBUCKET_REPO_SLUG", "")
+	t.Setenv("BITBUCKET_PR_ID", "")
+
+	valid := config.BitbucketConfig{Token: "bb-token", Workspace: "example", Repository: "infra", PullRequest: 12}
+
+	tests := []struct {
+		name        string
+		modify      func(cfg *config.BitbucketConfig)
+		expectError bool
+	}{
+		{"Valid cloud config", func(cfg *config.BitbucketConfig) {}, false},
+		{"Valid server config", func(cfg *config.BitbucketConfig) {
+			cfg.Type = "server"
+			cfg.BaseURL = "https://bitbucket.example.com"
+		}, false},
+		{"Missing token", func(cfg *config.BitbucketConfig) { cfg.Token = "" }, true},
+		{"Missing repository", func(cfg *config.BitbucketConfig) { cfg.Repository = "" }, true},
+		{"Missing pull request", func(cfg *config.BitbucketConfig) { cfg.PullRequest = 0 }, true},
+		{"Server without base URL", func(cfg *config.BitbucketConfig) { cfg.Type = "server" }, true},
+		{"Invalid type", func(cfg *config.BitbucketConfig) { cfg.Type = "gitea" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			target, err := New(cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNew_FromPipelinesEnvironment(t *testing.T) {
+	t.Setenv("BITBUCKET_WORKSPACE", "example")
+	t.Setenv("BITBUCKET_REPO_SLUG", "infra")
+	t.Setenv("BITBUCKET_PR_ID", "12")
+
+	bbTarget, err := New(config.BitbucketConfig{Token: "bb-token"})
+	if err != nil {
+		t.Fatalf("Failed to create bitbucket target: %v", err)
+	}
+
+	comments := bbTarget.comments.(*cloudComments)
+	if comments.url != "https://api.bitbucket.org/2.0/repositories/example/infra/pullrequests/12/comments" {
+		t.Errorf("Unexpected comments URL: %s", comments.url)
+	}
+}
+
+func TestWrite_Cloud(t *testing.T) {
+	const path = "/repositories/example/infra/pullrequests/12/comments"
+	var requests []string
+	var created, updated cloudComment
+	var username, password string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		username, password, _ = r.BasicAuth()
+
+		switch {
+		case r.Method == "GET" && r.URL.Query().Get("page") == "":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"values": []cloudComment{newCloudComment("nice")},
+				"next":   "http://" + r.Host + path + "?pagelen=100&page=2",
+			})
+		case r.Method == "GET":
+			existing := newCloudComment("[//]: # (infralog)\nold")
+			existing.ID = 55
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": []cloudComment{existing}})
+		case r.Method == "PUT":
+			json.NewDecoder(r.Body).Decode(&updated)
+		case r.Method == "POST":
+			json.NewDecoder(r.Body).Decode(&created)
+		}
+	}))
+	defer server.Close()
+
+	bbTarget, err := New(config.BitbucketConfig{
+		BaseURL:     server.URL,
+		Username:    "ci-bot",
+		Token:       "app-password",
+		Workspace:   "example",
+		Repository:  "infra",
+		PullRequest: 12,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bitbucket target: %v", err)
+	}
+
+	if err := bbTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if username != "ci-bot" || password != "app-password" {
+		t.Errorf("Expected basic auth with app password, got %q/%q", username, password)
+	}
+	if last := requests[len(requests)-1]; last != "PUT "+path+"/55" {
+		t.Errorf("Expected comment 55 to be updated, got %s", last)
+	}
+	if created.Content.Raw != "" {
+		t.Error("Expected no comment to be created")
+	}
+	if !strings.HasPrefix(updated.Content.Raw, "[//]: # (infralog)\n") {
+		t.Errorf("Expected reference marker, got %q", updated.Content.Raw)
+	}
+	if strings.Contains(updated.Content.Raw, "<!--") || strings.Contains(updated.Content.Raw, "<details>") {
+		t.Error("Expected no HTML in Bitbucket comments")
+	}
+}
+
+func TestWrite_Server(t *testing.T) {
+	const path = "/rest/api/1.0/projects/INFRA/repos/terraform/pull-requests/12"
+	var auth string
+	var created serverComment
+	var updated serverComment
+	var existing bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == path+"/activities":
+			values := []map[string]interface{}{{"action": "APPROVED"}}
+			if existing {
+				values = append(values, map[string]interface{}{
+					"action":  "COMMENTED",
+					"comment": serverComment{ID: 7, Version: 3, Text: created.Text},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": values, "isLastPage": true})
+		case r.Method == "POST" && r.URL.Path == path+"/comments":
+			json.NewDecoder(r.Body).Decode(&created)
+			existing = true
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "PUT" && r.URL.Path == path+"/comments/7":
+			json.NewDecoder(r.Body).Decode(&updated)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	bbTarget, err := New(config.BitbucketConfig{
+		Type:        "server",
+		BaseURL:     server.URL,
+		Token:       "bb-token",
+		Workspace:   "INFRA",
+		Repository:  "terraform",
+		PullRequest: 12,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bitbucket target: %v", err)
+	}
+	if !bbTarget.ReportsEmptyPlans() {
+		t.Error("Expected bitbucket target to report empty plans")
+	}
+
+	if err := bbTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if created.Text != "" {
+		t.Fatalf("Expected no comment for a plan without changes, got %q", created.Text)
+	}
+
+	if err := bbTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if !strings.Contains(created.Text, "`aws_instance.web` | added") {
+		t.Errorf("Expected comment to be created, got %q", created.Text)
+	}
+	if auth != "Bearer bb-token" {
+		t.Errorf("Expected bearer token authorization, got %q", auth)
+	}
+
+	if err := bbTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "update"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if updated.Version != 3 {
+		t.Errorf("Expected update to send comment version 3, got %d", updated.Version)
+	}
+	if !strings.Contains(updated.Text, "`aws_instance.web` | changed") {
+		t.Errorf("Expected comment to be updated, got %q", updated.Text)
+	}
+
+	if err := bbTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if !strings.Contains(updated.Text, "No changes.") {
+		t.Errorf("Expected comment to be updated for a plan without changes, got %q", updated.Text)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	bbTarget, err := New(config.BitbucketConfig{
+		BaseURL:     server.URL,
+		Token:       "bb-token",
+		Workspace:   "example",
+		Repository:  "infra",
+		PullRequest: 12,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create bitbucket target: %v", err)
+	}
+	if err := bbTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
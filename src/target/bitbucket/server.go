@@ -0,0 +1,65 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// serverComments implements target.CommentStore for Bitbucket Server and
+// Data Center.
+type serverComments struct {
+	client
+	url string
+
+	// versions records the version of comments returned by FindComment, which
+	// Bitbucket Server requires for optimistic locking on updates.
+	versions map[string]int
+}
+
+type serverComment struct {
+	ID      int64  `json:"id,omitempty"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// FindComment scans the pull request activity for a comment containing the
+// marker. Comments are only listed through the activities endpoint.
+func (s *serverComments) FindComment(marker string) (string, error) {
+	start := 0
+	for {
+		var page struct {
+			Values []struct {
+				Action  string        `json:"action"`
+				Comment serverComment `json:"comment"`
+			} `json:"values"`
+			IsLastPage    bool `json:"isLastPage"`
+			NextPageStart int  `json:"nextPageStart"`
+		}
+		url := fmt.Sprintf("%s/activities?limit=%d&start=%d", s.url, pageSize, start)
+		if err := s.api.Do("GET", url, nil, &page); err != nil {
+			return "", err
+		}
+
+		for _, activity := range page.Values {
+			if activity.Action == "COMMENTED" && strings.Contains(activity.Comment.Text, marker) {
+				id := strconv.FormatInt(activity.Comment.ID, 10)
+				s.versions[id] = activity.Comment.Version
+				return id, nil
+			}
+		}
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			return "", nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (s *serverComments) CreateComment(body string) error {
+	return s.api.Do("POST", s.url+"/comments", serverComment{Text: body}, nil)
+}
+
+func (s *serverComments) UpdateComment(id, body string) error {
+	return s.api.Do("PUT", s.url+"/comments/"+id, serverComment{Text: body, Version: s.versions[id]}, nil)
+}
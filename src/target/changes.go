@@ -0,0 +1,258 @@
+package target
+
+import (
+	"fmt"
+	"infralog/tfplan"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Readable statuses derived from Terraform plan actions.
+const (
+	StatusAdded    = "added"
+	StatusChanged  = "changed"
+	StatusReplaced = "replaced"
+	StatusRemoved  = "removed"
+	StatusUnknown  = "unknown"
+)
+
+// ActionsToStatus maps Terraform plan actions to a readable status string.
+func ActionsToStatus(actions []string) string {
+	if len(actions) == 0 {
+		return StatusUnknown
+	}
+
+	// Sort actions to normalize ordering
+	sortedActions := make([]string, len(actions))
+	copy(sortedActions, actions)
+	sort.Strings(sortedActions)
+
+	// Single action cases
+	if len(sortedActions) == 1 {
+		switch sortedActions[0] {
+		case "create":
+			return StatusAdded
+		case "delete":
+			return StatusRemoved
+		case "update":
+			return StatusChanged
+		default:
+			return sortedActions[0]
+		}
+	}
+
+	// Multiple actions (typically replace operations: create + delete)
+	if len(sortedActions) == 2 {
+		if sortedActions[0] == "create" && sortedActions[1] == "delete" {
+			return StatusReplaced
+		}
+	}
+
+	return StatusChanged
+}
+
+// Summary counts the changes in a plan by status.
+type Summary struct {
+	Resources int
+	Outputs   int
+	Added     int
+	Changed   int
+	Replaced  int
+	Removed   int
+}
+
+// Summarize counts the resource and output changes of a plan.
+func Summarize(plan *tfplan.Plan) Summary {
+	s := Summary{
+		Resources: len(plan.ResourceChanges),
+		Outputs:   len(plan.OutputChanges),
+	}
+
+	for _, rc := range plan.ResourceChanges {
+		switch ActionsToStatus(rc.Change.Actions) {
+		case StatusAdded:
+			s.Added++
+		case StatusChanged:
+			s.Changed++
+		case StatusReplaced:
+			s.Replaced++
+		case StatusRemoved:
+			s.Removed++
+		}
+	}
+
+	return s
+}
+
+// HasDestructiveChanges returns true if any resource is removed or replaced.
+func (s Summary) HasDestructiveChanges() bool {
+	return s.Removed > 0 || s.Replaced > 0
+}
+
+// Text returns a one-line description of the counts, such as "Terraform plan
+// changes detected: 3 resource(s), 1 output(s) changed", for summary lines and
+// notification fallback texts.
+func (s Summary) Text() string {
+	parts := []string{}
+	if s.Resources > 0 {
+		parts = append(parts, fmt.Sprintf("%d resource(s)", s.Resources))
+	}
+	if s.Outputs > 0 {
+		parts = append(parts, fmt.Sprintf("%d output(s)", s.Outputs))
+	}
+
+	return fmt.Sprintf("Terraform plan changes detected: %s changed", strings.Join(parts, ", "))
+}
+
+// StatusOrder is the display order used when changes are grouped by status.
+var StatusOrder = []string{StatusAdded, StatusChanged, StatusReplaced, StatusRemoved, StatusUnknown}
+
+// GroupByStatus groups resource changes by status, preserving plan order within
+// each group. Statuses not listed in StatusOrder are grouped as StatusUnknown.
+func GroupByStatus(changes []tfplan.ResourceChange) map[string][]tfplan.ResourceChange {
+	groups := make(map[string][]tfplan.ResourceChange)
+	for _, rc := range changes {
+		status := ActionsToStatus(rc.Change.Actions)
+		switch status {
+		case StatusAdded, StatusChanged, StatusReplaced, StatusRemoved:
+		default:
+			status = StatusUnknown
+		}
+		groups[status] = append(groups[status], rc)
+	}
+	return groups
+}
+
+// ValueChange represents a before/after value pair of a changed attribute.
+type ValueChange struct {
+	Before interface{}
+	After  interface{}
+}
+
+// SensitiveValue replaces attribute values that Terraform marks as sensitive,
+// so they are never sent to a target.
+const SensitiveValue = "(sensitive)"
+
+// ExtractChanges compares the before and after attributes of a change and
+// returns the changed attributes. Values marked in before_sensitive or
+// after_sensitive are replaced with SensitiveValue.
+func ExtractChanges(change tfplan.Change) map[string]ValueChange {
+	changes := make(map[string]ValueChange)
+
+	// Collect all unique attribute keys
+	allKeys := make(map[string]bool)
+	for key := range change.Before {
+		allKeys[key] = true
+	}
+	for key := range change.After {
+		allKeys[key] = true
+	}
+
+	// Compare each attribute
+	for key := range allKeys {
+		beforeVal := change.Before[key]
+		afterVal := change.After[key]
+
+		// Check if values are different (simple comparison)
+		if fmt.Sprintf("%v", beforeVal) != fmt.Sprintf("%v", afterVal) {
+			if isSensitive(change.BeforeSensitive, key) {
+				beforeVal = SensitiveValue
+			}
+			if isSensitive(change.AfterSensitive, key) {
+				afterVal = SensitiveValue
+			}
+			changes[key] = ValueChange{
+				Before: beforeVal,
+				After:  afterVal,
+			}
+		}
+	}
+
+	return changes
+}
+
+// isSensitive reports whether the attribute key is marked in a
+// before_sensitive or after_sensitive value. Terraform marks either the whole
+// object with true, or each sensitive attribute, nested in the same shape as
+// the value itself. An attribute with any sensitive part is treated as
+// sensitive as a whole.
+func isSensitive(marks interface{}, key string) bool {
+	switch m := marks.(type) {
+	case bool:
+		return m
+	case map[string]interface{}:
+		return containsMark(m[key])
+	}
+	return false
+}
+
+func containsMark(marks interface{}) bool {
+	switch m := marks.(type) {
+	case bool:
+		return m
+	case map[string]interface{}:
+		for _, v := range m {
+			if containsMark(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range m {
+			if containsMark(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SortedAttributes returns the attribute names of changes in alphabetical order.
+func SortedAttributes(changes map[string]ValueChange) []string {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatValue formats an attribute or output value for display, rendering
+// missing values as null.
+func FormatValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// ResourceAddress returns the address of a resource change, falling back to
+// type.name when the plan does not include one.
+func ResourceAddress(rc tfplan.ResourceChange) string {
+	if rc.Address != "" {
+		return rc.Address
+	}
+	return rc.Type + "." + rc.Name
+}
+
+// ShortSHA returns the first 8 characters of a commit SHA.
+func ShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// Truncate shortens s to at most max characters, replacing the end with "..."
+// when it is cut. It counts runes rather than bytes, so multibyte characters
+// are never split.
+func Truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
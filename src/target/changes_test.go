@@ -0,0 +1,213 @@
+package target
+
+import (
+	"infralog/tfplan"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestActionsToStatus(t *testing.T) {
+	tests := []struct {
+		actions  []string
+		expected string
+	}{
+		{[]string{"create"}, StatusAdded},
+		{[]string{"delete"}, StatusRemoved},
+		{[]string{"update"}, StatusChanged},
+		{[]string{"create", "delete"}, StatusReplaced},
+		{[]string{"delete", "create"}, StatusReplaced},
+		{[]string{}, StatusUnknown},
+		{nil, StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		result := ActionsToStatus(tt.actions)
+		if result != tt.expected {
+			t.Errorf("ActionsToStatus(%v) = %s, expected %s", tt.actions, result, tt.expected)
+		}
+	}
+}
+
+func TestExtractChanges(t *testing.T) {
+	before := map[string]interface{}{
+		"instance_type": "t2.micro",
+		"ami":           "ami-123",
+		"removed":       "value",
+	}
+	after := map[string]interface{}{
+		"instance_type": "t2.small",
+		"ami":           "ami-123",
+		"added":         "value",
+	}
+
+	changes := ExtractChanges(tfplan.Change{Before: before, After: after})
+
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d: %v", len(changes), changes)
+	}
+	if changes["instance_type"].Before != "t2.micro" || changes["instance_type"].After != "t2.small" {
+		t.Errorf("Unexpected instance_type change: %+v", changes["instance_type"])
+	}
+	if _, exists := changes["ami"]; exists {
+		t.Error("Expected unchanged attribute ami to be omitted")
+	}
+
+	names := SortedAttributes(changes)
+	expected := []string{"added", "instance_type", "removed"}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("SortedAttributes()[%d] = %s, expected %s", i, names[i], name)
+		}
+	}
+}
+
+func TestExtractChanges_Sensitive(t *testing.T) {
+	change := tfplan.Change{
+		Before: map[string]interface{}{
+			"password":      "old-secret",
+			"instance_type": "t2.micro",
+			"tags":          map[string]interface{}{"owner": "a", "token": "x"},
+		},
+		After: map[string]interface{}{
+			"password":      "new-secret",
+			"instance_type": "t2.small",
+			"tags":          map[string]interface{}{"owner": "b", "token": "y"},
+		},
+		BeforeSensitive: map[string]interface{}{"password": true, "tags": map[string]interface{}{"token": true}},
+		AfterSensitive:  true,
+	}
+
+	changes := ExtractChanges(change)
+
+	if changes["password"].Before != SensitiveValue || changes["password"].After != SensitiveValue {
+		t.Errorf("Expected password to be redacted, got %+v", changes["password"])
+	}
+	if changes["tags"].Before != SensitiveValue {
+		t.Errorf("Expected partially sensitive tags to be redacted, got %+v", changes["tags"])
+	}
+	if changes["instance_type"].Before != "t2.micro" || changes["instance_type"].After != SensitiveValue {
+		t.Errorf("Expected only the sensitive side of instance_type to be redacted, got %+v", changes["instance_type"])
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"t2.micro", "t2.micro"},
+		{3, "3"},
+		{true, "true"},
+		{[]interface{}{"a", "b"}, "[a b]"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatValue(tt.value); got != tt.expected {
+			t.Errorf("FormatValue(%v) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestGroupByStatus(t *testing.T) {
+	changes := []tfplan.ResourceChange{
+		{Address: "aws_instance.a", Change: tfplan.Change{Actions: []string{"create"}}},
+		{Address: "aws_instance.b", Change: tfplan.Change{Actions: []string{"delete"}}},
+		{Address: "aws_instance.c", Change: tfplan.Change{Actions: []string{"create"}}},
+		{Address: "aws_instance.d", Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+		{Address: "aws_instance.e", Change: tfplan.Change{Actions: []string{"read"}}},
+	}
+
+	groups := GroupByStatus(changes)
+
+	if len(groups[StatusAdded]) != 2 {
+		t.Errorf("Expected 2 added changes, got %d", len(groups[StatusAdded]))
+	}
+	if groups[StatusAdded][0].Address != "aws_instance.a" || groups[StatusAdded][1].Address != "aws_instance.c" {
+		t.Error("Expected plan order to be preserved within a group")
+	}
+	if len(groups[StatusRemoved]) != 1 {
+		t.Errorf("Expected 1 removed change, got %d", len(groups[StatusRemoved]))
+	}
+	if len(groups[StatusReplaced]) != 1 {
+		t.Errorf("Expected 1 replaced change, got %d", len(groups[StatusReplaced]))
+	}
+	if len(groups[StatusUnknown]) != 1 {
+		t.Errorf("Expected 1 unknown change, got %d", len(groups[StatusUnknown]))
+	}
+}
+
+func TestResourceAddress(t *testing.T) {
+	rc := tfplan.ResourceChange{Type: "aws_instance", Name: "web"}
+	if got := ResourceAddress(rc); got != "aws_instance.web" {
+		t.Errorf("ResourceAddress() = %s, expected aws_instance.web", got)
+	}
+
+	rc.Address = "module.app.aws_instance.web"
+	if got := ResourceAddress(rc); got != "module.app.aws_instance.web" {
+		t.Errorf("ResourceAddress() = %s, expected module.app.aws_instance.web", got)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	if got := ShortSHA("abc123def456789"); got != "abc123de" {
+		t.Errorf("ShortSHA() = %s, expected abc123de", got)
+	}
+	if got := ShortSHA("abc"); got != "abc" {
+		t.Errorf("ShortSHA() = %s, expected abc", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s        string
+		max      int
+		expected string
+	}{
+		{"aws_instance.web", 20, "aws_instance.web"},
+		{"aws_instance.web", 10, "aws_ins..."},
+		{"aws_s3_bucket.données", 21, "aws_s3_bucket.données"},
+		{"aws_s3_bucket.données", 20, "aws_s3_bucket.don..."},
+		{"ééééé", 4, "é..."},
+		{"ééééé", 2, "éé"},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.max)
+		if got != tt.expected {
+			t.Errorf("Truncate(%q, %d) = %q, expected %q", tt.s, tt.max, got, tt.expected)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) returned invalid UTF-8", tt.s, tt.max)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Change: tfplan.Change{Actions: []string{"create"}}},
+			{Change: tfplan.Change{Actions: []string{"create"}}},
+			{Change: tfplan.Change{Actions: []string{"update"}}},
+			{Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+		},
+		OutputChanges: map[string]tfplan.OutputChange{
+			"vpc_id": {Change: tfplan.Change{Actions: []string{"update"}}},
+		},
+	}
+
+	s := Summarize(plan)
+
+	expected := Summary{Resources: 4, Outputs: 1, Added: 2, Changed: 1, Replaced: 1}
+	if s != expected {
+		t.Errorf("Summarize() = %+v, expected %+v", s, expected)
+	}
+	if !s.HasDestructiveChanges() {
+		t.Error("Expected replacement to count as destructive")
+	}
+	if (Summary{Added: 1, Changed: 1}).HasDestructiveChanges() {
+		t.Error("Expected creates and updates not to count as destructive")
+	}
+	if text := s.Text(); text != "Terraform plan changes detected: 4 resource(s), 1 output(s) changed" {
+		t.Errorf("Text() = %q", text)
+	}
+}
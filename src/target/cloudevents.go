@@ -0,0 +1,168 @@
+package target
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// CloudEvents content modes.
+const (
+	CloudEventsStructured = "structured"
+	CloudEventsBinary     = "binary"
+)
+
+// CloudEvents types, one per message type.
+const (
+	CloudEventTypePlanChanged     = "io.infralog.plan.changed"
+	CloudEventTypeResourceChanged = "io.infralog.resource.changed"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+
+	defaultCloudEventsSource  = "infralog"
+	defaultCloudEventsSubject = "{{.Git.Branch}}"
+)
+
+// scpLikeURL matches git remotes in scp-like syntax, such as
+// git@github.com:company/infrastructure.git.
+var scpLikeURL = regexp.MustCompile(`^([^@/:]+@)?([^/:]+):([^/].*)$`)
+
+// CloudEvent is a CloudEvents 1.0 event in the structured JSON format.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+// CloudEvents encodes messages as CloudEvents for a target that opted in.
+type CloudEvents struct {
+	mode    string
+	source  string
+	subject *template.Template
+}
+
+// NewCloudEvents returns the CloudEvents encoder configured for a target, or
+// nil if CloudEvents are disabled.
+func NewCloudEvents(cfg config.CloudEventsConfig) (*CloudEvents, error) {
+	mode := strings.ToLower(cfg.Mode)
+	switch mode {
+	case "":
+		return nil, nil
+	case CloudEventsStructured, CloudEventsBinary:
+	default:
+		return nil, fmt.Errorf("invalid cloudevents mode: %s. Mode must be structured or binary", cfg.Mode)
+	}
+
+	subjectText := cfg.Subject
+	if subjectText == "" {
+		subjectText = defaultCloudEventsSubject
+	}
+	subject, err := ParseTemplate("cloudevents subject", subjectText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CloudEvents{mode: mode, source: cfg.Source, subject: subject}, nil
+}
+
+// NewEvent converts a message to a CloudEvent, keeping its id and data.
+func (c *CloudEvents) NewEvent(p *Payload, m Message) (CloudEvent, error) {
+	subject, err := ExecuteTemplate(c.subject, p)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	eventType := CloudEventTypePlanChanged
+	if m.Type == MessageTypeResourceChange {
+		eventType = CloudEventTypeResourceChanged
+	}
+
+	source := c.source
+	if source == "" {
+		source = cloudEventsSource(m.Source)
+	}
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              m.ID,
+		Source:          source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            m.Datetime.UTC(),
+		DataContentType: "application/json",
+		Data:            m.Data,
+	}, nil
+}
+
+// EncodeMessage returns the body and headers to publish for a message: the
+// infralog envelope and message attributes if ce is nil, otherwise a
+// CloudEvent in the configured mode. In binary mode the event attributes are
+// added as headers named with the transport binding's prefix (e.g. "ce-" for
+// HTTP) and the body is the event data. The content type is returned as the
+// "content-type" header.
+func EncodeMessage(p *Payload, m Message, ce *CloudEvents, prefix string) ([]byte, map[string]string, error) {
+	headers := make(map[string]string, len(m.Attributes)+6)
+	for k, v := range m.Attributes {
+		headers[k] = v
+	}
+
+	if ce == nil {
+		body, err := m.Body()
+		return body, headers, err
+	}
+
+	event, err := ce.NewEvent(p, m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ce.mode == CloudEventsStructured {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshaling cloudevent: %w", err)
+		}
+		headers["content-type"] = cloudEventsContentType
+		return body, headers, nil
+	}
+
+	body, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error marshaling cloudevent data: %w", err)
+	}
+	headers[prefix+"specversion"] = event.SpecVersion
+	headers[prefix+"id"] = event.ID
+	headers[prefix+"source"] = event.Source
+	headers[prefix+"type"] = event.Type
+	headers[prefix+"time"] = event.Time.Format(time.RFC3339)
+	if event.Subject != "" {
+		headers[prefix+"subject"] = event.Subject
+	}
+	headers["content-type"] = event.DataContentType
+	return body, headers, nil
+}
+
+// cloudEventsSource returns the repository URL as a valid URI reference,
+// converting scp-like git remotes to ssh URLs.
+func cloudEventsSource(repoURL string) string {
+	if repoURL == "" {
+		return defaultCloudEventsSource
+	}
+	if !strings.Contains(repoURL, "://") {
+		if m := scpLikeURL.FindStringSubmatch(repoURL); m != nil {
+			return "ssh://" + m[1] + m[2] + "/" + m[3]
+		}
+	}
+	return repoURL
+}
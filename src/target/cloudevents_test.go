@@ -0,0 +1,141 @@
+package target
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"testing"
+)
+
+func TestEncodeMessage_Structured(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.Metadata.Git.RepoURL = "https://github.com/company/infrastructure.git"
+
+	ce, err := NewCloudEvents(config.CloudEventsConfig{Mode: "structured"})
+	if err != nil {
+		t.Fatalf("NewCloudEvents() error = %v", err)
+	}
+
+	m := NewMessages(payload, false)[0]
+	body, headers, err := EncodeMessage(payload, m, ce, "ce-")
+	if err != nil {
+		t.Fatalf("EncodeMessage() error = %v", err)
+	}
+
+	if headers["content-type"] != "application/cloudevents+json" {
+		t.Errorf("Expected cloudevents content type, got %q", headers["content-type"])
+	}
+	if _, ok := headers["ce-id"]; ok {
+		t.Error("Expected no binary attributes in structured mode")
+	}
+
+	var event struct {
+		CloudEvent
+		Data Payload `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Failed to unmarshal event: %v", err)
+	}
+	expected := CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              m.ID,
+		Source:          "https://github.com/company/infrastructure.git",
+		Type:            CloudEventTypePlanChanged,
+		Subject:         "main",
+		Time:            payload.Datetime,
+		DataContentType: "application/json",
+	}
+	event.CloudEvent.Data = nil
+	if event.CloudEvent != expected {
+		t.Errorf("Event attributes = %+v, expected %+v", event.CloudEvent, expected)
+	}
+	if len(event.Data.Plan.ResourceChanges) != 3 {
+		t.Errorf("Expected the payload as data, got %+v", event.Data)
+	}
+}
+
+func TestEncodeMessage_Binary(t *testing.T) {
+	payload := markdownTestPayload(1)
+
+	ce, err := NewCloudEvents(config.CloudEventsConfig{
+		Mode:    "binary",
+		Source:  "/infra/production",
+		Subject: "production/{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("NewCloudEvents() error = %v", err)
+	}
+
+	m := NewMessages(payload, true)[2]
+	body, headers, err := EncodeMessage(payload, m, ce, "ce_")
+	if err != nil {
+		t.Fatalf("EncodeMessage() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"content-type":     "application/json",
+		"ce_specversion":   "1.0",
+		"ce_id":            m.ID,
+		"ce_source":        "/infra/production",
+		"ce_type":          CloudEventTypeResourceChanged,
+		"ce_subject":       "production/main",
+		"ce_time":          "2025-12-12T10:30:45Z",
+		"infralog-address": "aws_security_group.old",
+	}
+	for key, value := range expected {
+		if headers[key] != value {
+			t.Errorf("Expected header %s %q, got %q", key, value, headers[key])
+		}
+	}
+
+	var data ResourcePayload
+	if err := json.Unmarshal(body, &data); err != nil {
+		t.Fatalf("Failed to unmarshal data: %v", err)
+	}
+	if data.ResourceChange.Address != "aws_security_group.old" {
+		t.Errorf("Expected the resource payload as body, got %s", body)
+	}
+}
+
+func TestEncodeMessage_Disabled(t *testing.T) {
+	ce, err := NewCloudEvents(config.CloudEventsConfig{})
+	if err != nil || ce != nil {
+		t.Fatalf("Expected CloudEvents to be disabled, got %v, %v", ce, err)
+	}
+
+	payload := markdownTestPayload(1)
+	m := NewMessages(payload, false)[0]
+	body, headers, err := EncodeMessage(payload, m, nil, "ce-")
+	if err != nil {
+		t.Fatalf("EncodeMessage() error = %v", err)
+	}
+
+	expected, _ := m.Body()
+	if string(body) != string(expected) || headers["content-type"] != "application/json" {
+		t.Errorf("Expected the infralog envelope, got %s", body)
+	}
+}
+
+func TestNewCloudEvents_InvalidMode(t *testing.T) {
+	if _, err := NewCloudEvents(config.CloudEventsConfig{Mode: "batched"}); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+}
+
+func TestCloudEventsSource(t *testing.T) {
+	tests := []struct {
+		repoURL  string
+		expected string
+	}{
+		{"", "infralog"},
+		{"https://github.com/company/infrastructure.git", "https://github.com/company/infrastructure.git"},
+		{"git@github.com:company/infrastructure.git", "ssh://git@github.com/company/infrastructure.git"},
+		{"github.com:company/infrastructure.git", "ssh://github.com/company/infrastructure.git"},
+		{"ssh://git@github.com/company/infrastructure.git", "ssh://git@github.com/company/infrastructure.git"},
+	}
+
+	for _, tt := range tests {
+		if got := cloudEventsSource(tt.repoURL); got != tt.expected {
+			t.Errorf("cloudEventsSource(%q) = %q, expected %q", tt.repoURL, got, tt.expected)
+		}
+	}
+}
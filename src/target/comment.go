@@ -0,0 +1,66 @@
+package target
+
+import "strings"
+
+// CommentMarker returns the hidden marker that identifies comments posted by
+// Infralog. A non-empty id distinguishes comments from multiple plans posted
+// to the same pull request.
+func CommentMarker(id string) string {
+	if id == "" {
+		return "<!-- infralog -->"
+	}
+	return "<!-- infralog:" + id + " -->"
+}
+
+// ReferenceCommentMarker returns a marker in the form of an empty markdown
+// link reference definition, for platforms that escape HTML comments. Like
+// CommentMarker, it is not shown in the rendered comment.
+func ReferenceCommentMarker(id string) string {
+	if id == "" {
+		return "[//]: # (infralog)"
+	}
+	return "[//]: # (infralog:" + id + ")"
+}
+
+// CommentStore is implemented by code review targets that post comments to a
+// pull or merge request.
+type CommentStore interface {
+	// FindComment returns the ID of the first comment whose body contains the
+	// marker, or an empty string if there is none.
+	FindComment(marker string) (string, error)
+	CreateComment(body string) error
+	UpdateComment(id, body string) error
+}
+
+// UpsertComment updates the comment containing the marker, or creates a new
+// one, so that repeated runs keep a single sticky comment up to date.
+func UpsertComment(store CommentStore, marker, body string) error {
+	if !strings.Contains(body, marker) {
+		body = marker + "\n" + body
+	}
+
+	id, err := store.FindComment(marker)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return store.CreateComment(body)
+	}
+	return store.UpdateComment(id, body)
+}
+
+// UpdateExistingComment updates the comment containing the marker, but
+// doesn't create one if there is none. It is used for plans without changes,
+// so a comment left by an earlier plan no longer shows stale changes, while
+// pull requests that never changed infrastructure get no comment at all.
+func UpdateExistingComment(store CommentStore, marker, body string) error {
+	if !strings.Contains(body, marker) {
+		body = marker + "\n" + body
+	}
+
+	id, err := store.FindComment(marker)
+	if err != nil || id == "" {
+		return err
+	}
+	return store.UpdateComment(id, body)
+}
@@ -0,0 +1,94 @@
+package target
+
+import (
+	"strings"
+	"testing"
+)
+
+type fakeCommentStore struct {
+	comments map[string]string
+	created  []string
+}
+
+func (s *fakeCommentStore) FindComment(marker string) (string, error) {
+	for id, body := range s.comments {
+		if strings.Contains(body, marker) {
+			return id, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *fakeCommentStore) CreateComment(body string) error {
+	s.created = append(s.created, body)
+	return nil
+}
+
+func (s *fakeCommentStore) UpdateComment(id, body string) error {
+	s.comments[id] = body
+	return nil
+}
+
+func TestCommentMarker(t *testing.T) {
+	if got := CommentMarker(""); got != "<!-- infralog -->" {
+		t.Errorf("CommentMarker(\"\") = %q", got)
+	}
+	if got := CommentMarker("prod"); got != "<!-- infralog:prod -->" {
+		t.Errorf("CommentMarker(\"prod\") = %q", got)
+	}
+}
+
+func TestReferenceCommentMarker(t *testing.T) {
+	if got := ReferenceCommentMarker(""); got != "[//]: # (infralog)" {
+		t.Errorf("ReferenceCommentMarker(\"\") = %q", got)
+	}
+	if got := ReferenceCommentMarker("prod"); got != "[//]: # (infralog:prod)" {
+		t.Errorf("ReferenceCommentMarker(\"prod\") = %q", got)
+	}
+}
+
+func TestUpsertComment(t *testing.T) {
+	store := &fakeCommentStore{comments: map[string]string{"1": "unrelated"}}
+	marker := CommentMarker("")
+
+	if err := UpsertComment(store, marker, "first"); err != nil {
+		t.Fatalf("UpsertComment() error = %v", err)
+	}
+	if len(store.created) != 1 || store.created[0] != marker+"\nfirst" {
+		t.Fatalf("Expected a new comment with marker, got %v", store.created)
+	}
+
+	store.comments["2"] = store.created[0]
+	if err := UpsertComment(store, marker, marker+"\nsecond"); err != nil {
+		t.Fatalf("UpsertComment() error = %v", err)
+	}
+	if len(store.created) != 1 {
+		t.Errorf("Expected existing comment to be updated, got %d created", len(store.created))
+	}
+	if store.comments["2"] != marker+"\nsecond" {
+		t.Errorf("Expected comment 2 to be updated, got %q", store.comments["2"])
+	}
+	if store.comments["1"] != "unrelated" {
+		t.Error("Expected unrelated comment to be left alone")
+	}
+}
+
+func TestUpdateExistingComment(t *testing.T) {
+	store := &fakeCommentStore{comments: map[string]string{}}
+	marker := CommentMarker("")
+
+	if err := UpdateExistingComment(store, marker, "no changes"); err != nil {
+		t.Fatalf("UpdateExistingComment() error = %v", err)
+	}
+	if len(store.created) != 0 {
+		t.Fatalf("Expected no comment to be created, got %v", store.created)
+	}
+
+	store.comments["1"] = marker + "\nchanges"
+	if err := UpdateExistingComment(store, marker, "no changes"); err != nil {
+		t.Fatalf("UpdateExistingComment() error = %v", err)
+	}
+	if store.comments["1"] != marker+"\nno changes" {
+		t.Errorf("Expected existing comment to be updated, got %q", store.comments["1"])
+	}
+}
@@ -0,0 +1,156 @@
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultSite  = "datadoghq.com"
+	defaultTitle = "Terraform changes{{if .Git.Branch}} on {{.Git.Branch}}{{end}}: {{.Summary.Resources}} resource(s)"
+
+	// Datadog truncates event text longer than 4000 characters.
+	maxTextLength = 4000
+
+	// Markdown event text is wrapped in %%% markers.
+	markdownStart = "%%% \n"
+	markdownEnd   = "\n %%%"
+
+	requestTimeout = 30 * time.Second
+)
+
+// Event alert types.
+const (
+	AlertTypeInfo    = "info"
+	AlertTypeWarning = "warning"
+)
+
+type event struct {
+	Title        string   `json:"title"`
+	Text         string   `json:"text"`
+	DateHappened int64    `json:"date_happened"`
+	AlertType    string   `json:"alert_type"`
+	Tags         []string `json:"tags"`
+}
+
+type eventResponse struct {
+	Event struct {
+		URL string `json:"url"`
+	} `json:"event"`
+}
+
+type DatadogTarget struct {
+	url    string
+	apiKey string
+	title  *template.Template
+	tags   []string
+	client *http.Client
+	result string
+}
+
+func New(cfg config.DatadogConfig) (*DatadogTarget, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("datadog API key is required")
+	}
+
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		site := cfg.Site
+		if site == "" {
+			site = defaultSite
+		}
+		apiURL = "https://api." + site
+	}
+
+	titleText := cfg.Title
+	if titleText == "" {
+		titleText = defaultTitle
+	}
+	title, err := target.ParseTemplate("title", titleText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DatadogTarget{
+		url:    strings.TrimSuffix(apiURL, "/") + "/api/v1/events",
+		apiKey: cfg.APIKey,
+		title:  title,
+		tags:   cfg.Tags,
+		client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write posts an event for the plan.
+func (t *DatadogTarget) Write(p *target.Payload) error {
+	e, err := t.buildEvent(p)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error marshaling event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("datadog returned status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result eventResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	t.result = result.Event.URL
+	return nil
+}
+
+// Result returns the URL of the event posted by the last Write.
+func (t *DatadogTarget) Result() string {
+	return t.result
+}
+
+func (t *DatadogTarget) buildEvent(p *target.Payload) (event, error) {
+	title, err := target.ExecuteTemplate(t.title, p)
+	if err != nil {
+		return event{}, err
+	}
+
+	alertType := AlertTypeInfo
+	if target.Summarize(p.Plan).HasDestructiveChanges() {
+		alertType = AlertTypeWarning
+	}
+
+	text := target.RenderMarkdown(p, target.MarkdownOptions{
+		MaxLength: maxTextLength - len(markdownStart) - len(markdownEnd),
+	})
+
+	return event{
+		Title:        title,
+		Text:         markdownStart + text + markdownEnd,
+		DateHappened: p.Datetime.Unix(),
+		AlertType:    alertType,
+		Tags:         append(append([]string{"source:infralog"}, target.Tags(p)...), t.tags...),
+	}, nil
+}
@@ -0,0 +1,152 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target/targettest"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeDatadog returns a fake events API that records posted events.
+func newFakeDatadog(t *testing.T) (*httptest.Server, *[]event) {
+	t.Helper()
+
+	var events []event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/events" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("DD-API-KEY") != "dd-key" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["Forbidden"]}`))
+			return
+		}
+
+		var e event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		events = append(events, e)
+
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"ok","event":{"id":42,"url":"https://app.datadoghq.com/event/event?id=42"}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &events
+}
+
+func TestWrite_Success(t *testing.T) {
+	server, events := newFakeDatadog(t)
+
+	tgt, err := New(config.DatadogConfig{APIKey: "dd-key", APIURL: server.URL, Tags: []string{"team:platform"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_instance.web_a", "create"), targettest.Change("aws_instance.web_b", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*events))
+	}
+	e := (*events)[0]
+
+	if e.Title != "Terraform changes on main: 2 resource(s)" {
+		t.Errorf("Unexpected title %q", e.Title)
+	}
+	if e.AlertType != AlertTypeWarning {
+		t.Errorf("Expected warning for a destructive plan, got %q", e.AlertType)
+	}
+	if e.DateHappened != 1765535445 {
+		t.Errorf("Unexpected date %d", e.DateHappened)
+	}
+
+	expectedTags := "source:infralog,repo:company/infrastructure,branch:main,action:create,action:delete,team:platform"
+	if got := strings.Join(e.Tags, ","); got != expectedTags {
+		t.Errorf("Expected tags %s, got %s", expectedTags, got)
+	}
+
+	if !strings.HasPrefix(e.Text, "%%% \n") || !strings.HasSuffix(e.Text, "\n %%%") || !strings.Contains(e.Text, "`aws_instance.web_b`") {
+		t.Errorf("Unexpected text:\n%s", e.Text)
+	}
+
+	if tgt.Result() != "https://app.datadoghq.com/event/event?id=42" {
+		t.Errorf("Unexpected result %q", tgt.Result())
+	}
+}
+
+func TestWrite_Info(t *testing.T) {
+	server, events := newFakeDatadog(t)
+
+	tgt, err := New(config.DatadogConfig{APIKey: "dd-key", APIURL: server.URL, Title: "{{.Summary.Added}} added"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_instance.web_a", "create"), targettest.Change("aws_instance.web_b", "update"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	e := (*events)[0]
+	if e.AlertType != AlertTypeInfo || e.Title != "1 added" {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+}
+
+func TestWrite_TruncatesText(t *testing.T) {
+	server, events := newFakeDatadog(t)
+
+	tgt, err := New(config.DatadogConfig{APIKey: "dd-key", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var changes []tfplan.ResourceChange
+	for i := 0; i < 500; i++ {
+		changes = append(changes, targettest.Change(fmt.Sprintf("aws_instance.web_%d", i), "create"))
+	}
+	if err := tgt.Write(targettest.Payload(changes...)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if text := (*events)[0].Text; len(text) > maxTextLength || !strings.HasSuffix(text, "\n %%%") {
+		t.Errorf("Expected text of at most %d characters, got %d", maxTextLength, len(text))
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server, _ := newFakeDatadog(t)
+
+	tgt, err := New(config.DatadogConfig{APIKey: "wrong", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_instance.web", "create")))
+	if err == nil || !strings.Contains(err.Error(), "Forbidden") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.DatadogConfig{}); err == nil {
+		t.Error("Expected an error for a missing API key")
+	}
+	if _, err := New(config.DatadogConfig{APIKey: "dd-key", Title: "{{.Git"}); err == nil {
+		t.Error("Expected an error for an invalid title template")
+	}
+
+	tgt, err := New(config.DatadogConfig{APIKey: "dd-key", Site: "datadoghq.eu"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.url != "https://api.datadoghq.eu/api/v1/events" {
+		t.Errorf("Unexpected URL %q", tgt.url)
+	}
+}
@@ -0,0 +1,350 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Discord webhook limits.
+const (
+	maxEmbedsPerMessage  = 10
+	maxCharsPerMessage   = 6000
+	maxDescriptionLength = 4096
+	maxFieldValueLength  = 1024
+
+	// Maximum number of changed attributes listed per resource.
+	maxAttributes = 5
+
+	// Maximum number of attempts for a message that is rate limited.
+	maxAttempts = 5
+)
+
+// Embed colors by severity.
+const (
+	colorGreen  = 0x2ECC71
+	colorYellow = 0xF1C40F
+	colorRed    = 0xE74C3C
+	colorGrey   = 0x95A5A6
+)
+
+type DiscordTarget struct {
+	webhookURL string
+	username   string
+	avatarURL  string
+	sleep      func(time.Duration)
+}
+
+type discordMessage struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color"`
+	Fields      []field `json:"fields,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+type field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type rateLimitResponse struct {
+	RetryAfter float64 `json:"retry_after"`
+}
+
+func New(cfg config.DiscordConfig) (*DiscordTarget, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("discord webhook URL is required")
+	}
+
+	return &DiscordTarget{
+		webhookURL: cfg.WebhookURL,
+		username:   cfg.Username,
+		avatarURL:  cfg.AvatarURL,
+		sleep:      time.Sleep,
+	}, nil
+}
+
+func (t *DiscordTarget) Write(p *target.Payload) error {
+	messages := t.buildMessages(p)
+
+	for i, msg := range messages {
+		if err := t.send(msg); err != nil {
+			return fmt.Errorf("error sending discord message %d of %d: %w", i+1, len(messages), err)
+		}
+	}
+
+	return nil
+}
+
+// send posts a single message, waiting and retrying when Discord rate limits the request.
+func (t *DiscordTarget) send(msg discordMessage) error {
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshaling discord message: %w", err)
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := http.Post(t.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("error sending discord message: %w", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			t.sleep(retryAfter(resp.Header, body))
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("discord request failed with status code: %d", resp.StatusCode)
+		}
+
+		// Wait for the bucket to reset before the next message if it is exhausted
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if delay, ok := parseSeconds(resp.Header.Get("X-RateLimit-Reset-After")); ok {
+				t.sleep(delay)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("discord request still rate limited after %d attempts", maxAttempts)
+}
+
+// retryAfter returns how long to wait after a 429 response, preferring the
+// Retry-After header and falling back to the retry_after field of the body.
+func retryAfter(header http.Header, body []byte) time.Duration {
+	if delay, ok := parseSeconds(header.Get("Retry-After")); ok {
+		return delay
+	}
+
+	var rl rateLimitResponse
+	if err := json.Unmarshal(body, &rl); err == nil && rl.RetryAfter > 0 {
+		return time.Duration(rl.RetryAfter * float64(time.Second))
+	}
+
+	return time.Second
+}
+
+// parseSeconds parses a (possibly fractional) number of seconds.
+func parseSeconds(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// buildMessages renders the payload as embeds and packs them into as many
+// messages as needed to respect Discord's per-message limits.
+func (t *DiscordTarget) buildMessages(p *target.Payload) []discordMessage {
+	embeds := []embed{t.buildSummaryEmbed(p)}
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		group := groups[status]
+		if len(group) == 0 {
+			continue
+		}
+		title := fmt.Sprintf("%s (%d)", strings.ToUpper(status[:1])+status[1:], len(group))
+		embeds = append(embeds, splitEmbeds(title, statusColor(status), formatResourceLines(group, status))...)
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		embeds = append(embeds, splitEmbeds("Output Changes", colorGrey, formatOutputLines(p.Plan.OutputChanges))...)
+	}
+
+	var messages []discordMessage
+	var current []embed
+	currentChars := 0
+	for _, e := range embeds {
+		size := embedLength(e)
+		if len(current) > 0 && (len(current) >= maxEmbedsPerMessage || currentChars+size > maxCharsPerMessage) {
+			messages = append(messages, t.newMessage(current))
+			current = nil
+			currentChars = 0
+		}
+		current = append(current, e)
+		currentChars += size
+	}
+	if len(current) > 0 {
+		messages = append(messages, t.newMessage(current))
+	}
+
+	return messages
+}
+
+func (t *DiscordTarget) newMessage(embeds []embed) discordMessage {
+	return discordMessage{
+		Username:  t.username,
+		AvatarURL: t.avatarURL,
+		Embeds:    embeds,
+	}
+}
+
+func (t *DiscordTarget) buildSummaryEmbed(p *target.Payload) embed {
+	e := embed{
+		Title:       "Terraform Plan Changes",
+		Description: target.Summarize(p.Plan).Text(),
+		Color:       planColor(p.Plan),
+		Timestamp:   p.Datetime.Format(time.RFC3339),
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		if git.Committer != "" {
+			e.Fields = append(e.Fields, field{Name: "Committer", Value: target.Truncate(git.Committer, maxFieldValueLength), Inline: true})
+		}
+		if git.Branch != "" {
+			e.Fields = append(e.Fields, field{Name: "Branch", Value: target.Truncate("`"+git.Branch+"`", maxFieldValueLength), Inline: true})
+		}
+		if git.CommitSHA != "" {
+			e.Fields = append(e.Fields, field{Name: "Commit", Value: "`" + target.ShortSHA(git.CommitSHA) + "`", Inline: true})
+		}
+		if git.RepoURL != "" {
+			e.Fields = append(e.Fields, field{Name: "Repository", Value: target.Truncate(git.RepoURL, maxFieldValueLength)})
+		}
+	}
+
+	return e
+}
+
+// splitEmbeds distributes lines over as many embeds as needed to keep each
+// description within Discord's limit.
+func splitEmbeds(title string, color int, lines []string) []embed {
+	var embeds []embed
+	var sb strings.Builder
+
+	flush := func() {
+		if sb.Len() == 0 {
+			return
+		}
+		embedTitle := title
+		if len(embeds) > 0 {
+			embedTitle += " (continued)"
+		}
+		embeds = append(embeds, embed{Title: embedTitle, Description: sb.String(), Color: color})
+		sb.Reset()
+	}
+
+	for _, line := range lines {
+		line = target.Truncate(line, maxDescriptionLength-1)
+		if sb.Len()+len(line)+1 > maxDescriptionLength {
+			flush()
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	flush()
+
+	return embeds
+}
+
+func formatResourceLines(changes []tfplan.ResourceChange, status string) []string {
+	var lines []string
+	for _, rc := range changes {
+		line := fmt.Sprintf("`%s`", target.ResourceAddress(rc))
+
+		// Show changed attributes for updates
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			attrChanges := target.ExtractChanges(rc.Change)
+			for i, attr := range target.SortedAttributes(attrChanges) {
+				if i >= maxAttributes {
+					line += fmt.Sprintf("\n  • _...and %d more attributes_", len(attrChanges)-maxAttributes)
+					break
+				}
+				change := attrChanges[attr]
+				line += fmt.Sprintf("\n  • `%s`: `%v` → `%v`", attr, change.Before, change.After)
+			}
+		}
+
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func formatOutputLines(changes map[string]tfplan.OutputChange) []string {
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		oc := changes[name]
+		status := target.ActionsToStatus(oc.Change.Actions)
+		line := fmt.Sprintf("`%s` - %s", name, status)
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			line += fmt.Sprintf("\n  • `%v` → `%v`", oc.Change.Before, oc.Change.After)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// planColor returns the color of the most severe change in the plan.
+func planColor(plan *tfplan.Plan) int {
+	color := colorGrey
+	for _, rc := range plan.ResourceChanges {
+		switch statusColor(target.ActionsToStatus(rc.Change.Actions)) {
+		case colorRed:
+			return colorRed
+		case colorYellow:
+			color = colorYellow
+		case colorGreen:
+			if color == colorGrey {
+				color = colorGreen
+			}
+		}
+	}
+	return color
+}
+
+// statusColor maps a change status to an embed color. Replacements are
+// treated as destructive since the existing resource is deleted.
+func statusColor(status string) int {
+	switch status {
+	case target.StatusAdded:
+		return colorGreen
+	case target.StatusChanged:
+		return colorYellow
+	case target.StatusRemoved, target.StatusReplaced:
+		return colorRed
+	default:
+		return colorGrey
+	}
+}
+
+// embedLength counts the characters Discord includes in the per-message limit.
+func embedLength(e embed) int {
+	n := len(e.Title) + len(e.Description)
+	for _, f := range e.Fields {
+		n += len(f.Name) + len(f.Value)
+	}
+	return n
+}
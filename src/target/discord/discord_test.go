@@ -0,0 +1,250 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.DiscordConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with webhook URL",
+			cfg:         config.DiscordConfig{WebhookURL: "https://discord.com/api/webhooks/123/xxx"},
+			expectError: false,
+		},
+		{
+			name:        "Empty webhook URL",
+			cfg:         config.DiscordConfig{WebhookURL: ""},
+			expectError: true,
+		},
+		{
+			name: "Config with all options",
+			cfg: config.DiscordConfig{
+				WebhookURL: "https://discord.com/api/webhooks/123/xxx",
+				Username:   "Infralog",
+				AvatarURL:  "https://example.com/avatar.png",
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	var receivedBody discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected application/json content type, got %s", r.Header.Get("Content-Type"))
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discordTarget, err := New(config.DiscordConfig{WebhookURL: server.URL, Username: "Infralog"})
+	if err != nil {
+		t.Fatalf("Failed to create discord target: %v", err)
+	}
+
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{
+				Type: "aws_instance",
+				Name: "web",
+				Change: tfplan.Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "t2.micro"},
+					After:   map[string]interface{}{"instance_type": "t2.small"},
+				},
+			},
+		},
+	}
+	payload := target.NewPayload(plan)
+	if err := discordTarget.Write(payload); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	if receivedBody.Username != "Infralog" {
+		t.Errorf("Expected username Infralog, got %s", receivedBody.Username)
+	}
+	if len(receivedBody.Embeds) != 2 {
+		t.Fatalf("Expected summary and change embeds, got %d", len(receivedBody.Embeds))
+	}
+	if receivedBody.Embeds[0].Color != colorYellow {
+		t.Errorf("Expected yellow summary color, got %#x", receivedBody.Embeds[0].Color)
+	}
+	if !strings.Contains(receivedBody.Embeds[1].Description, "instance_type") {
+		t.Error("Expected attribute diff in change embed")
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	discordTarget, err := New(config.DiscordConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create discord target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{})
+	if err := discordTarget.Write(payload); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestWrite_RateLimited(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "1.5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 1.5}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "0.25")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discordTarget, err := New(config.DiscordConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create discord target: %v", err)
+	}
+
+	var sleeps []time.Duration
+	discordTarget.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_s3_bucket.data", Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+	})
+	if err := discordTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	if len(sleeps) != 2 || sleeps[0] != 1500*time.Millisecond || sleeps[1] != 250*time.Millisecond {
+		t.Errorf("Expected sleeps of [1.5s 250ms], got %v", sleeps)
+	}
+}
+
+func TestBuildMessages_SplitsLargePlans(t *testing.T) {
+	discordTarget := &DiscordTarget{}
+
+	var changes []tfplan.ResourceChange
+	for i := 0; i < 500; i++ {
+		changes = append(changes, tfplan.ResourceChange{
+			Address: fmt.Sprintf("aws_instance.server_%d", i),
+			Change: tfplan.Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"instance_type": "t2.micro"},
+				After:   map[string]interface{}{"instance_type": "t2.small"},
+			},
+		})
+	}
+	payload := &target.Payload{Plan: &tfplan.Plan{ResourceChanges: changes}, Datetime: time.Now().UTC()}
+
+	messages := discordTarget.buildMessages(payload)
+	if len(messages) < 2 {
+		t.Fatalf("Expected plan to be split across messages, got %d", len(messages))
+	}
+
+	resources := 0
+	for _, msg := range messages {
+		if len(msg.Embeds) > maxEmbedsPerMessage {
+			t.Errorf("Message has %d embeds, limit is %d", len(msg.Embeds), maxEmbedsPerMessage)
+		}
+		total := 0
+		for _, e := range msg.Embeds {
+			if len(e.Description) > maxDescriptionLength {
+				t.Errorf("Embed description has %d characters, limit is %d", len(e.Description), maxDescriptionLength)
+			}
+			total += embedLength(e)
+			resources += strings.Count(e.Description, "aws_instance.server_")
+		}
+		if total > maxCharsPerMessage {
+			t.Errorf("Message has %d characters, limit is %d", total, maxCharsPerMessage)
+		}
+	}
+	if resources != len(changes) {
+		t.Errorf("Expected all %d resources across messages, got %d", len(changes), resources)
+	}
+}
+
+func TestPlanColor(t *testing.T) {
+	tests := []struct {
+		name     string
+		actions  [][]string
+		expected int
+	}{
+		{"Creates only", [][]string{{"create"}}, colorGreen},
+		{"Create and update", [][]string{{"create"}, {"update"}}, colorYellow},
+		{"Delete wins", [][]string{{"update"}, {"delete"}, {"create"}}, colorRed},
+		{"Replace is destructive", [][]string{{"delete", "create"}}, colorRed},
+		{"No resources", nil, colorGrey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &tfplan.Plan{}
+			for _, actions := range tt.actions {
+				plan.ResourceChanges = append(plan.ResourceChanges, tfplan.ResourceChange{
+					Change: tfplan.Change{Actions: actions},
+				})
+			}
+			if got := planColor(plan); got != tt.expected {
+				t.Errorf("planColor() = %#x, expected %#x", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultIndex = "infralog-changes"
+
+	requestTimeout = 30 * time.Second
+)
+
+// bulkResponse is the part of a bulk API response used to detect failed
+// documents.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+type ElasticsearchTarget struct {
+	url      string
+	index    *template.Template
+	username string
+	password string
+	apiKey   string
+	client   *http.Client
+}
+
+func New(cfg config.ElasticsearchConfig) (*ElasticsearchTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("elasticsearch URL is required")
+	}
+	if cfg.APIKey != "" && cfg.Username != "" {
+		return nil, fmt.Errorf("elasticsearch api_key and username are mutually exclusive")
+	}
+
+	indexText := cfg.Index
+	if indexText == "" {
+		indexText = defaultIndex
+	}
+	index, err := target.ParseTemplate("index", indexText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ElasticsearchTarget{
+		url:      strings.TrimSuffix(cfg.URL, "/") + "/_bulk",
+		index:    index,
+		username: cfg.Username,
+		password: cfg.Password,
+		apiKey:   cfg.APIKey,
+		client:   &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write indexes one document per resource change with the bulk API.
+func (t *ElasticsearchTarget) Write(p *target.Payload) error {
+	documents := target.ResourceDocuments(p)
+	if len(documents) == 0 {
+		return nil
+	}
+
+	index, err := target.ExecuteTemplate(t.index, p)
+	if err != nil {
+		return err
+	}
+
+	body, err := buildBulkBody(index, documents)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case t.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+t.apiKey)
+	case t.username != "":
+		req.SetBasicAuth(t.username, t.password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bulk request failed with status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	// The bulk API reports failures per document with a 200 response.
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	if result.Errors {
+		failed := 0
+		var reason string
+		for _, item := range result.Items {
+			for _, r := range item {
+				if r.Status >= 300 {
+					failed++
+					if reason == "" {
+						reason = r.Error.Type + ": " + r.Error.Reason
+					}
+				}
+			}
+		}
+		return fmt.Errorf("error indexing %d of %d document(s) in %s: %s", failed, len(documents), index, reason)
+	}
+	return nil
+}
+
+// buildBulkBody returns the NDJSON bulk request creating the documents in
+// index. Documents get Elastic Common Schema style @timestamp fields, and are
+// created rather than indexed so the index may be a data stream.
+func buildBulkBody(index string, documents []map[string]string) ([]byte, error) {
+	action, err := json.Marshal(map[string]map[string]string{"create": {"_index": index}})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling bulk action: %w", err)
+	}
+
+	var b bytes.Buffer
+	for _, document := range documents {
+		doc := make(map[string]string, len(document))
+		for k, v := range document {
+			doc[k] = v
+		}
+		doc["@timestamp"] = doc["timestamp"]
+		delete(doc, "timestamp")
+
+		line, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling document: %w", err)
+		}
+		b.Write(action)
+		b.WriteByte('\n')
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
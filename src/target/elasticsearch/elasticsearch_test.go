@@ -0,0 +1,127 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var lines []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("Unexpected request %s with content type %q", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if r.Header.Get("Authorization") != "ApiKey a2V5" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("Failed to decode line: %v", err)
+			}
+			lines = append(lines, line)
+		}
+		w.Write([]byte(`{"took":3,"errors":false,"items":[{"create":{"status":201}},{"create":{"status":201}}]}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.ElasticsearchConfig{URL: server.URL, Index: `infralog-{{.Datetime.Format "2006.01"}}`, APIKey: "a2V5"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(lines) != 4 {
+		t.Fatalf("Expected 2 actions and 2 documents, got %d lines", len(lines))
+	}
+
+	action, _ := lines[2]["create"].(map[string]interface{})
+	if action["_index"] != "infralog-2025.12" {
+		t.Errorf("Unexpected action %v", lines[2])
+	}
+
+	doc := lines[3]
+	expected := map[string]string{
+		"address":    "aws_instance.web",
+		"type":       "aws_instance",
+		"action":     "removed",
+		"branch":     "main",
+		"sha":        "abc123def456",
+		"@timestamp": "2025-12-12T10:30:45Z",
+	}
+	for key, value := range expected {
+		if doc[key] != value {
+			t.Errorf("Expected %s %q, got %v", key, value, doc[key])
+		}
+	}
+	if _, ok := doc["timestamp"]; ok {
+		t.Error("Expected timestamp to be renamed to @timestamp")
+	}
+}
+
+func TestWrite_DocumentErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "elastic" || password != "secret" {
+			t.Errorf("Expected basic auth, got %q %q", user, password)
+		}
+		w.Write([]byte(`{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse field [type]"}}}]}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.ElasticsearchConfig{URL: server.URL, Username: "elastic", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("Expected document error, got %v", err)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"type":"security_exception"}}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.ElasticsearchConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "security_exception") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ElasticsearchConfig
+	}{
+		{"Missing URL", config.ElasticsearchConfig{}},
+		{"API key and username", config.ElasticsearchConfig{URL: "http://localhost:9200", APIKey: "a2V5", Username: "elastic"}},
+		{"Invalid index template", config.ElasticsearchConfig{URL: "http://localhost:9200", Index: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
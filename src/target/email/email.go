@@ -0,0 +1,292 @@
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TLS modes for the SMTP connection.
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "implicit"
+	TLSNone     = "none"
+)
+
+const (
+	defaultPort         = 587
+	defaultImplicitPort = 465
+	defaultSubject      = "[infralog] {{.Summary.Resources}} changes{{if .Git.Branch}} on {{.Git.Branch}}{{end}}"
+
+	// Bounds connecting to the SMTP server and the whole SMTP conversation,
+	// so an unresponsive server can't stall a run.
+	dialTimeout = 10 * time.Second
+	sendTimeout = 60 * time.Second
+)
+
+type EmailTarget struct {
+	host       string
+	port       int
+	username   string
+	password   string
+	tlsMode    string
+	from       string
+	to         []string
+	cc         []string
+	subject    *template.Template
+	attachPlan bool
+}
+
+func New(cfg config.EmailConfig) (*EmailTarget, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("email SMTP host is required")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("email from address is required")
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("at least one email recipient is required")
+	}
+
+	tlsMode := strings.ToLower(cfg.TLS)
+	switch tlsMode {
+	case "":
+		tlsMode = TLSStartTLS
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("invalid TLS mode: %s. TLS mode must be starttls, implicit or none", cfg.TLS)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultPort
+		if tlsMode == TLSImplicit {
+			port = defaultImplicitPort
+		}
+	}
+
+	subjectText := cfg.Subject
+	if subjectText == "" {
+		subjectText = defaultSubject
+	}
+	subject, err := target.ParseTemplate("subject", subjectText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailTarget{
+		host:       cfg.Host,
+		port:       port,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		tlsMode:    tlsMode,
+		from:       cfg.From,
+		to:         cfg.To,
+		cc:         cfg.CC,
+		subject:    subject,
+		attachPlan: cfg.AttachPlan,
+	}, nil
+}
+
+func (t *EmailTarget) Write(p *target.Payload) error {
+	msg, err := t.buildMessage(p)
+	if err != nil {
+		return err
+	}
+
+	if err := t.send(msg); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	return nil
+}
+
+// send delivers the message over SMTP using the configured TLS mode.
+func (t *EmailTarget) send(msg []byte) error {
+	addr := net.JoinHostPort(t.host, strconv.Itoa(t.port))
+	tlsConfig := &tls.Config{ServerName: t.host}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if t.tlsMode == TLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(sendTimeout)); err != nil {
+		conn.Close()
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if t.tlsMode == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
+	if t.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.username, t.password, t.host)); err != nil {
+			return fmt.Errorf("error authenticating: %w", err)
+		}
+	}
+
+	if err := client.Mail(t.from); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	for _, rcpt := range append(append([]string{}, t.to...), t.cc...) {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("error adding recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("error writing message data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error finishing message data: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders a multipart/mixed message with a multipart/alternative
+// text and HTML body and, optionally, the plan attached as JSON.
+func (t *EmailTarget) buildMessage(p *target.Payload) ([]byte, error) {
+	subject, err := target.ExecuteTemplate(t.subject, p)
+	if err != nil {
+		return nil, err
+	}
+
+	textBody := target.RenderText(p)
+	htmlBody, err := target.RenderHTML(p)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering email body: %w", err)
+	}
+
+	// Alternative text and HTML bodies
+	var alternative bytes.Buffer
+	altWriter := multipart.NewWriter(&alternative)
+	if err := writeQuotedPrintablePart(altWriter, "text/plain; charset=UTF-8", textBody); err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintablePart(altWriter, "text/html; charset=UTF-8", htmlBody); err != nil {
+		return nil, err
+	}
+	if err := altWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mixedWriter := multipart.NewWriter(&body)
+	part, err := mixedWriter.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + altWriter.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alternative.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if t.attachPlan {
+		planJSON, err := json.MarshalIndent(p.Plan, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling plan attachment: %w", err)
+		}
+		if err := writeAttachment(mixedWriter, "plan.json", "application/json", planJSON); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixedWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	writeHeader(&msg, "From", t.from)
+	writeHeader(&msg, "To", strings.Join(t.to, ", "))
+	if len(t.cc) > 0 {
+		writeHeader(&msg, "Cc", strings.Join(t.cc, ", "))
+	}
+	writeHeader(&msg, "Subject", mime.QEncoding.Encode("utf-8", subject))
+	writeHeader(&msg, "Date", p.Datetime.Format(time.RFC1123Z))
+	writeHeader(&msg, "MIME-Version", "1.0")
+	writeHeader(&msg, "Content-Type", "multipart/mixed; boundary="+mixedWriter.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func writeQuotedPrintablePart(w *multipart.Writer, contentType, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeAttachment(w *multipart.Writer, filename, contentType string, data []byte) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", contentType, filename)},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filename)},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wrap base64 lines at 76 characters as required by RFC 2045
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := part.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
@@ -0,0 +1,360 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer is a minimal in-process SMTP server that records the
+// envelope and data of the messages it receives.
+type fakeSMTPServer struct {
+	listener net.Listener
+	auth     string
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake SMTP server: %v", err)
+	}
+
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			s.auth = strings.TrimSpace(line[len("AUTH PLAIN"):])
+			reply("235 Authentication successful")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpts = append(s.rcpts, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var sb strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				sb.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			s.data = sb.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	valid := config.EmailConfig{
+		Host: "smtp.example.com",
+		From: "infralog@example.com",
+		To:   []string{"cab@example.com"},
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(cfg *config.EmailConfig)
+		expectError bool
+	}{
+		{"Valid config", func(cfg *config.EmailConfig) {}, false},
+		{"Missing host", func(cfg *config.EmailConfig) { cfg.Host = "" }, true},
+		{"Missing from", func(cfg *config.EmailConfig) { cfg.From = "" }, true},
+		{"Missing recipients", func(cfg *config.EmailConfig) { cfg.To = nil }, true},
+		{"Implicit TLS", func(cfg *config.EmailConfig) { cfg.TLS = "implicit" }, false},
+		{"Invalid TLS mode", func(cfg *config.EmailConfig) { cfg.TLS = "ssl" }, true},
+		{"Invalid subject template", func(cfg *config.EmailConfig) { cfg.Subject = "{{.Git.Branch" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			target, err := New(cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	emailTarget, err := New(config.EmailConfig{
+		Host: "smtp.example.com",
+		From: "infralog@example.com",
+		To:   []string{"cab@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email target: %v", err)
+	}
+
+	if emailTarget.port != 587 {
+		t.Errorf("Expected default port 587, got %d", emailTarget.port)
+	}
+	if emailTarget.tlsMode != TLSStartTLS {
+		t.Errorf("Expected default TLS mode starttls, got %s", emailTarget.tlsMode)
+	}
+
+	emailTarget, err = New(config.EmailConfig{
+		Host: "smtp.example.com",
+		TLS:  TLSImplicit,
+		From: "infralog@example.com",
+		To:   []string{"cab@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email target: %v", err)
+	}
+	if emailTarget.port != 465 {
+		t.Errorf("Expected default port 465 with implicit TLS, got %d", emailTarget.port)
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	emailTarget, err := New(config.EmailConfig{
+		Host:       "127.0.0.1",
+		Port:       server.port(),
+		TLS:        TLSNone,
+		Username:   "infralog",
+		Password:   "secret",
+		From:       "infralog@example.com",
+		To:         []string{"cab@example.com"},
+		CC:         []string{"audit@example.com"},
+		AttachPlan: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email target: %v", err)
+	}
+
+	payload := &target.Payload{
+		Plan: &tfplan.Plan{
+			FormatVersion: "1.2",
+			ResourceChanges: []tfplan.ResourceChange{
+				{Address: "aws_s3_bucket.data", Change: tfplan.Change{Actions: []string{"create"}}},
+				{
+					Address: "aws_instance.web",
+					Change: tfplan.Change{
+						Actions: []string{"update"},
+						Before:  map[string]interface{}{"instance_type": "t2.micro"},
+						After:   map[string]interface{}{"instance_type": "t2.small"},
+					},
+				},
+				{Address: "aws_security_group.old", Change: tfplan.Change{Actions: []string{"delete"}}},
+			},
+		},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &target.PayloadMetadata{Git: &git.Metadata{Branch: "main"}},
+	}
+	if err := emailTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	<-server.done
+
+	if server.auth == "" {
+		t.Error("Expected client to authenticate")
+	}
+	if server.from != "infralog@example.com" {
+		t.Errorf("Expected sender infralog@example.com, got %s", server.from)
+	}
+	if strings.Join(server.rcpts, ",") != "cab@example.com,audit@example.com" {
+		t.Errorf("Expected to and cc recipients, got %v", server.rcpts)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(server.data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	if got := msg.Header.Get("Subject"); got != "[infralog] 3 changes on main" {
+		t.Errorf("Unexpected subject: %q", got)
+	}
+	if got := msg.Header.Get("Cc"); got != "audit@example.com" {
+		t.Errorf("Unexpected Cc header: %q", got)
+	}
+
+	parts := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+	if !strings.HasPrefix(parts[0].contentType, "text/plain") || !strings.Contains(parts[0].body, "instance_type: t2.micro -> t2.small") {
+		t.Errorf("Expected plaintext body with attribute diff, got %+v", parts[0])
+	}
+	if !strings.HasPrefix(parts[1].contentType, "text/html") || !strings.Contains(parts[1].body, "<code>aws_instance.web</code>") {
+		t.Errorf("Expected HTML body with change table, got %+v", parts[1])
+	}
+	if len(parts) != 3 || parts[2].filename != "plan.json" || !strings.Contains(parts[2].body, `"format_version": "1.2"`) {
+		t.Errorf("Expected plan.json attachment, got %d parts", len(parts))
+	}
+}
+
+func TestWrite_ConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	emailTarget, err := New(config.EmailConfig{
+		Host: "127.0.0.1",
+		Port: port,
+		TLS:  TLSNone,
+		From: "infralog@example.com",
+		To:   []string{"cab@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email target: %v", err)
+	}
+
+	if err := emailTarget.Write(target.NewPayload(&tfplan.Plan{})); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestWrite_StartTLSUnsupported(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	emailTarget, err := New(config.EmailConfig{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "infralog@example.com",
+		To:   []string{"cab@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email target: %v", err)
+	}
+
+	err = emailTarget.Write(target.NewPayload(&tfplan.Plan{}))
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Expected STARTTLS error, got %v", err)
+	}
+}
+
+type mimePart struct {
+	contentType string
+	filename    string
+	body        string
+}
+
+// readParts flattens a multipart/mixed body, expanding nested multipart/alternative parts.
+func readParts(t *testing.T, contentType string, body io.Reader) []mimePart {
+	t.Helper()
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("Failed to parse content type %q: %v", contentType, err)
+	}
+
+	var parts []mimePart
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+
+		partType := part.Header.Get("Content-Type")
+		if strings.HasPrefix(partType, "multipart/") {
+			parts = append(parts, readParts(t, partType, part)...)
+			continue
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("Failed to read part body: %v", err)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			data = decodeBase64(t, string(data))
+		}
+		parts = append(parts, mimePart{
+			contentType: partType,
+			filename:    part.FileName(),
+			body:        string(data),
+		})
+	}
+
+	return parts
+}
+
+func decodeBase64(t *testing.T, s string) []byte {
+	t.Helper()
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(s, "\r\n", ""))
+	if err != nil {
+		t.Fatalf("Failed to decode base64: %v", err)
+	}
+	return decoded
+}
@@ -0,0 +1,147 @@
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/awsutil"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+const (
+	defaultSource = "infralog"
+
+	putTimeout = 30 * time.Second
+)
+
+// Detail types, one per message type.
+const (
+	DetailTypePlanChanged     = "Terraform Plan Changed"
+	DetailTypeResourceChanged = "Terraform Resource Changed"
+)
+
+// detail is the event detail: the message envelope with a summary of the
+// plan, so that rules can match on counts and actions.
+type detail struct {
+	target.Message
+	Summary awsutil.Summary `json:"summary"`
+}
+
+type EventBridgeTarget struct {
+	eventBusName string
+	source       string
+	region       string
+	endpointURL  string
+	perResource  bool
+}
+
+func New(cfg config.EventBridgeConfig) (*EventBridgeTarget, error) {
+	if cfg.EventBusName == "" {
+		return nil, fmt.Errorf("eventbridge event bus name is required")
+	}
+
+	source := cfg.Source
+	if source == "" {
+		source = defaultSource
+	}
+
+	return &EventBridgeTarget{
+		eventBusName: cfg.EventBusName,
+		source:       source,
+		region:       cfg.Region,
+		endpointURL:  cfg.EndpointURL,
+		perResource:  cfg.MessagePerResource,
+	}, nil
+}
+
+// Write puts the payload on the event bus, split into one event per resource
+// change if configured or if the plan exceeds the event size limit.
+func (t *EventBridgeTarget) Write(p *target.Payload) error {
+	entries, err := t.buildEntries(p)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), putTimeout)
+	defer cancel()
+
+	awsCfg, err := awsutil.LoadConfig(ctx, t.region)
+	if err != nil {
+		return err
+	}
+	client := eventbridge.NewFromConfig(awsCfg, func(o *eventbridge.Options) {
+		if t.endpointURL != "" {
+			o.BaseEndpoint = aws.String(t.endpointURL)
+		}
+	})
+
+	for _, batch := range target.Batch(entries, awsutil.MaxBatchEntries, awsutil.MaxMessageSize, entrySize) {
+		out, err := client.PutEvents(ctx, &eventbridge.PutEventsInput{Entries: batch})
+		if err != nil {
+			return fmt.Errorf("error putting events on event bus %s: %w", t.eventBusName, err)
+		}
+		if out.FailedEntryCount > 0 {
+			for _, e := range out.Entries {
+				if e.ErrorCode != nil {
+					return fmt.Errorf("error putting %d event(s) on event bus %s: %s: %s", out.FailedEntryCount, t.eventBusName, aws.ToString(e.ErrorCode), aws.ToString(e.ErrorMessage))
+				}
+			}
+			return fmt.Errorf("error putting %d event(s) on event bus %s", out.FailedEntryCount, t.eventBusName)
+		}
+	}
+	return nil
+}
+
+func (t *EventBridgeTarget) buildEntries(p *target.Payload) ([]types.PutEventsRequestEntry, error) {
+	summary := awsutil.Summarize(p)
+
+	messages, err := awsutil.Split(p, t.perResource, func(m target.Message) (int, error) {
+		entry, err := t.newEntry(m, summary)
+		return entrySize(entry), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]types.PutEventsRequestEntry, 0, len(messages))
+	for _, m := range messages {
+		entry, err := t.newEntry(m, summary)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (t *EventBridgeTarget) newEntry(m target.Message, summary awsutil.Summary) (types.PutEventsRequestEntry, error) {
+	body, err := json.Marshal(detail{Message: m, Summary: summary})
+	if err != nil {
+		return types.PutEventsRequestEntry{}, fmt.Errorf("error marshaling event detail: %w", err)
+	}
+
+	detailType := DetailTypePlanChanged
+	if m.Type == target.MessageTypeResourceChange {
+		detailType = DetailTypeResourceChanged
+	}
+
+	return types.PutEventsRequestEntry{
+		EventBusName: aws.String(t.eventBusName),
+		Source:       aws.String(t.source),
+		DetailType:   aws.String(detailType),
+		Detail:       aws.String(string(body)),
+		Time:         aws.Time(m.Datetime),
+	}, nil
+}
+
+// entrySize returns the size of an entry as calculated by EventBridge.
+func entrySize(e types.PutEventsRequestEntry) int {
+	// The time counts as 14 bytes.
+	return 14 + len(aws.ToString(e.Source)) + len(aws.ToString(e.DetailType)) + len(aws.ToString(e.Detail))
+}
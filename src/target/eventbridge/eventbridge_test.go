@@ -0,0 +1,166 @@
+package eventbridge
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/awsutil"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type entry struct {
+	EventBusName string
+	Source       string
+	DetailType   string
+	Detail       string
+}
+
+func setAWSEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+}
+
+// newTestServer returns a fake EventBridge endpoint that records PutEvents
+// entries and fails every entry if fail is set.
+func newTestServer(t *testing.T, fail bool) (*httptest.Server, *[]entry) {
+	t.Helper()
+
+	var entries []entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "AWSEvents.PutEvents" {
+			t.Errorf("Expected PutEvents, got %q", target)
+		}
+
+		var req struct{ Entries []entry }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		entries = append(entries, req.Entries...)
+
+		type result struct {
+			EventId      string `json:",omitempty"`
+			ErrorCode    string `json:",omitempty"`
+			ErrorMessage string `json:",omitempty"`
+		}
+		resp := struct {
+			FailedEntryCount int
+			Entries          []result
+		}{}
+		for range req.Entries {
+			if fail {
+				resp.FailedEntryCount++
+				resp.Entries = append(resp.Entries, result{ErrorCode: "InternalFailure", ErrorMessage: "boom"})
+			} else {
+				resp.Entries = append(resp.Entries, result{EventId: "1"})
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &entries
+}
+
+func TestWrite_Success(t *testing.T) {
+	setAWSEnv(t)
+	server, entries := newTestServer(t, false)
+
+	tgt, err := New(config.EventBridgeConfig{EventBusName: "infra", EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*entries) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*entries))
+	}
+	e := (*entries)[0]
+	if e.EventBusName != "infra" || e.Source != "infralog" || e.DetailType != DetailTypePlanChanged {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+
+	var got struct {
+		Type    string          `json:"type"`
+		Data    target.Payload  `json:"data"`
+		Summary awsutil.Summary `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(e.Detail), &got); err != nil {
+		t.Fatalf("Failed to unmarshal detail: %v", err)
+	}
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected detail: %+v", got)
+	}
+	if got.Summary.Resources != 2 || got.Summary.Removed != 1 || len(got.Summary.Actions) != 2 {
+		t.Errorf("Unexpected summary: %+v", got.Summary)
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	setAWSEnv(t)
+	server, entries := newTestServer(t, false)
+
+	tgt, err := New(config.EventBridgeConfig{
+		EventBusName:       "infra",
+		Source:             "company.infrastructure",
+		EndpointURL:        server.URL,
+		MessagePerResource: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*entries) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(*entries))
+	}
+	for i, expected := range []string{"aws_s3_bucket.data", "aws_instance.web"} {
+		e := (*entries)[i]
+		if e.Source != "company.infrastructure" || e.DetailType != DetailTypeResourceChanged {
+			t.Errorf("Event %d: unexpected event %+v", i, e)
+		}
+
+		var got struct {
+			Data target.ResourcePayload `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(e.Detail), &got); err != nil {
+			t.Fatalf("Failed to unmarshal detail: %v", err)
+		}
+		if got.Data.ResourceChange.Address != expected {
+			t.Errorf("Event %d: expected %s, got %s", i, expected, got.Data.ResourceChange.Address)
+		}
+	}
+}
+
+func TestWrite_Failed(t *testing.T) {
+	setAWSEnv(t)
+	server, _ := newTestServer(t, true)
+
+	tgt, err := New(config.EventBridgeConfig{EventBusName: "infra", EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected an error for failed entries")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(config.EventBridgeConfig{}); err == nil {
+		t.Error("Expected an error for a missing event bus name")
+	}
+}
@@ -0,0 +1,139 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"os"
+	osexec "os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 60 * time.Second
+
+	// maxStderrLength is the number of trailing stderr bytes kept for errors.
+	maxStderrLength = 4096
+)
+
+type ExecTarget struct {
+	command string
+	args    []string
+	timeout time.Duration
+	stdout  io.Writer
+}
+
+func New(cfg config.ExecConfig) (*ExecTarget, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("exec command is required")
+	}
+	if cfg.TimeoutSeconds < 0 {
+		return nil, fmt.Errorf("invalid timeout: %d. Timeout must be positive", cfg.TimeoutSeconds)
+	}
+
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+
+	return &ExecTarget{
+		command: cfg.Command,
+		args:    cfg.Args,
+		timeout: timeout,
+		stdout:  os.Stdout,
+	}, nil
+}
+
+// Write runs the command with the payload as JSON on stdin. The command's
+// stdout is passed through, and its stderr is included in the returned error
+// when it exits with a non-zero status or times out.
+func (t *ExecTarget) Write(p *target.Payload) error {
+	jsonData, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+
+	stderr := &tailBuffer{limit: maxStderrLength}
+	cmd := osexec.CommandContext(ctx, t.command, t.args...)
+	cmd.Stdin = bytes.NewReader(jsonData)
+	cmd.Stdout = t.stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), environment(p)...)
+	// Don't wait forever for children that inherited the output pipes.
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command %s timed out after %s%s", t.command, t.timeout, stderr.suffix())
+	}
+
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("command %s failed with exit code %d%s", t.command, exitErr.ExitCode(), stderr.suffix())
+	}
+	if err != nil {
+		return fmt.Errorf("error running command %s: %w", t.command, err)
+	}
+	return nil
+}
+
+// environment returns the INFRALOG_* variables describing the payload, so
+// simple scripts don't need to parse the JSON on stdin.
+func environment(p *target.Payload) []string {
+	s := target.Summarize(p.Plan)
+	env := []string{
+		"INFRALOG_RESOURCES=" + strconv.Itoa(s.Resources),
+		"INFRALOG_OUTPUTS=" + strconv.Itoa(s.Outputs),
+		"INFRALOG_ADDED=" + strconv.Itoa(s.Added),
+		"INFRALOG_CHANGED=" + strconv.Itoa(s.Changed),
+		"INFRALOG_REPLACED=" + strconv.Itoa(s.Replaced),
+		"INFRALOG_REMOVED=" + strconv.Itoa(s.Removed),
+		"INFRALOG_DESTRUCTIVE=" + strconv.FormatBool(s.HasDestructiveChanges()),
+		"INFRALOG_DATETIME=" + p.Datetime.UTC().Format(time.RFC3339),
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		env = append(env,
+			"INFRALOG_BRANCH="+git.Branch,
+			"INFRALOG_COMMIT_SHA="+git.CommitSHA,
+			"INFRALOG_COMMITTER="+git.Committer,
+			"INFRALOG_REPO_URL="+git.RepoURL,
+		)
+	}
+
+	return env
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+// suffix formats the captured output for appending to an error message.
+func (b *tailBuffer) suffix() string {
+	out := strings.TrimSpace(string(b.buf))
+	if out == "" {
+		return ""
+	}
+	return ": " + out
+}
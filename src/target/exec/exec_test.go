@@ -0,0 +1,95 @@
+package exec
+
+import (
+	"bytes"
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTarget(t *testing.T, cfg config.ExecConfig) (*ExecTarget, *bytes.Buffer) {
+	t.Helper()
+
+	tgt, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	stdout := &bytes.Buffer{}
+	tgt.stdout = stdout
+	return tgt, stdout
+}
+
+func TestWrite_Success(t *testing.T) {
+	tgt, stdout := newTestTarget(t, config.ExecConfig{
+		Command: "sh",
+		Args:    []string{"-c", `echo "$INFRALOG_BRANCH $INFRALOG_COMMIT_SHA $INFRALOG_RESOURCES $INFRALOG_REMOVED $INFRALOG_DESTRUCTIVE"; cat`},
+	})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	env, stdin, _ := strings.Cut(stdout.String(), "\n")
+	if env != "main abc123def456 2 1 true" {
+		t.Errorf("Unexpected environment: %q", env)
+	}
+
+	var got target.Payload
+	if err := json.Unmarshal([]byte(stdin), &got); err != nil {
+		t.Fatalf("Expected payload JSON on stdin: %v", err)
+	}
+	if len(got.Plan.ResourceChanges) != 2 || got.Metadata.Git.Branch != "main" {
+		t.Errorf("Unexpected payload: %+v", got)
+	}
+}
+
+func TestWrite_Failure(t *testing.T) {
+	tgt, _ := newTestTarget(t, config.ExecConfig{
+		Command: "sh",
+		Args:    []string{"-c", "echo 'ticket service unavailable' >&2; exit 3"},
+	})
+
+	err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil {
+		t.Fatal("Expected an error for a non-zero exit code")
+	}
+	if !strings.Contains(err.Error(), "exit code 3: ticket service unavailable") {
+		t.Errorf("Expected exit code and stderr in error, got %v", err)
+	}
+}
+
+func TestWrite_Timeout(t *testing.T) {
+	tgt, _ := newTestTarget(t, config.ExecConfig{Command: "sleep", Args: []string{"10"}})
+	tgt.timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the command to be killed, took %s", elapsed)
+	}
+}
+
+func TestWrite_CommandNotFound(t *testing.T) {
+	tgt, _ := newTestTarget(t, config.ExecConfig{Command: "infralog-command-that-does-not-exist"})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected an error for a missing command")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{limit: 5}
+	b.Write([]byte("abc"))
+	b.Write([]byte("defg"))
+
+	if got := string(b.buf); got != "cdefg" {
+		t.Errorf("Expected last 5 bytes, got %q", got)
+	}
+}
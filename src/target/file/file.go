@@ -0,0 +1,159 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/fsutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Output formats.
+const (
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+const dirMode = 0o755
+
+type FileTarget struct {
+	path   *template.Template
+	format string
+	gzip   bool
+	result string
+}
+
+func New(cfg config.FileConfig) (*FileTarget, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	format := strings.ToLower(cfg.Format)
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatNDJSON, FormatMarkdown, FormatHTML:
+	default:
+		return nil, fmt.Errorf("invalid format: %s. Format must be json, ndjson, markdown or html", cfg.Format)
+	}
+
+	path, err := target.ParseTemplate("path", cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileTarget{
+		path:   path,
+		format: format,
+		gzip:   cfg.Gzip,
+	}, nil
+}
+
+// Write renders the payload and writes it to the file named by the path
+// template. NDJSON payloads are appended to the file, other formats replace it.
+func (t *FileTarget) Write(p *target.Payload) error {
+	path, err := target.ExecuteTemplate(t.path, p)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("path template rendered an empty path")
+	}
+
+	data, err := t.render(p)
+	if err != nil {
+		return err
+	}
+
+	if t.gzip {
+		if data, err = compress(data); err != nil {
+			return fmt.Errorf("error compressing %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return fmt.Errorf("error creating directory for %s: %w", path, err)
+	}
+
+	if t.format == FormatNDJSON {
+		err = appendFile(path, data)
+	} else {
+		err = fsutil.WriteFileAtomic(path, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.result = path
+	return nil
+}
+
+// Result returns the path written by the last Write.
+func (t *FileTarget) Result() string {
+	return t.result
+}
+
+func (t *FileTarget) render(p *target.Payload) ([]byte, error) {
+	switch t.format {
+	case FormatNDJSON:
+		data, err := json.Marshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling payload: %w", err)
+		}
+		return append(data, '\n'), nil
+	case FormatMarkdown:
+		return []byte(target.RenderMarkdown(p, target.MarkdownOptions{Collapsible: true})), nil
+	case FormatHTML:
+		body, err := target.RenderHTML(p)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering html: %w", err)
+		}
+		return []byte(body), nil
+	default:
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling payload: %w", err)
+		}
+		return append(data, '\n'), nil
+	}
+}
+
+// compress gzips data. Compressed NDJSON records are appended as separate
+// gzip members, which gzip readers decompress as one continuous stream.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// appendFile appends data to path with a single write in append mode, so
+// records from concurrent runs are not interleaved.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, fsutil.FileMode)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", path, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWrite_JSON(t *testing.T) {
+	dir := t.TempDir()
+	tgt, err := New(config.FileConfig{Path: dir + "/reports/{{.Git.Branch}}/{{.Datetime}}.json"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	path := filepath.Join(dir, "reports", "main", "20251212T103045Z.json")
+	if tgt.Result() != path {
+		t.Errorf("Result() = %q, expected %q", tgt.Result(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	var got target.Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal file: %v", err)
+	}
+	if got.Metadata.Git.Branch != "main" || len(got.Plan.ResourceChanges) != 1 {
+		t.Errorf("Unexpected payload: %+v", got)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the report in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestWrite_NDJSONAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.ndjson")
+	tgt, err := New(config.FileConfig{Path: path, Format: "ndjson"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"))); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+	assertNDJSONLines(t, f, 2)
+}
+
+func TestWrite_GzipNDJSONAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.ndjson.gz")
+	tgt, err := New(config.FileConfig{Path: path, Format: "ndjson", Gzip: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"))); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to create gzip reader: %v", err)
+	}
+	assertNDJSONLines(t, zr, 3)
+}
+
+func assertNDJSONLines(t *testing.T, r io.Reader, expected int) {
+	t.Helper()
+
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var p target.Payload
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			t.Errorf("Line %d is not a valid payload: %v", lines+1, err)
+		}
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if lines != expected {
+		t.Errorf("Expected %d lines, got %d", expected, lines)
+	}
+}
+
+func TestWrite_Rendered(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"markdown", "### Terraform Plan Changes"},
+		{"html", "<!DOCTYPE html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report")
+			if err := os.WriteFile(path, []byte("previous report"), 0o644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			tgt, err := New(config.FileConfig{Path: path, Format: tt.format})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"))); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if !strings.HasPrefix(string(data), tt.expected) || strings.Contains(string(data), "previous report") {
+				t.Errorf("Expected file to be replaced with a %s report, got:\n%s", tt.format, data)
+			}
+		})
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.FileConfig
+	}{
+		{"Missing path", config.FileConfig{}},
+		{"Invalid format", config.FileConfig{Path: "report.xml", Format: "xml"}},
+		{"Invalid template", config.FileConfig{Path: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
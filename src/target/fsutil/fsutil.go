@@ -0,0 +1,43 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileMode is the mode of files written by file-based targets.
+const FileMode = 0o644
+
+// WriteFileAtomic writes data to a temporary file in the destination
+// directory, syncs it and renames it into place, so readers never see a
+// partial file. The temporary name starts with a dot and ends with .tmp, so
+// it is ignored by tools that only read files with a given extension, such
+// as the node_exporter textfile collector.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), FileMode); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error renaming temporary file to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package github
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"os"
+	"strings"
+	"time"
+)
+
+// Check run conclusions.
+const (
+	ConclusionNeutral        = "neutral"
+	ConclusionSuccess        = "success"
+	ConclusionActionRequired = "action_required"
+)
+
+const (
+	defaultCheckName      = "Infralog"
+	defaultAnnotationPath = "."
+
+	// GitHub accepts at most 50 annotations per request and limits the
+	// output summary to 65535 characters.
+	maxAnnotationsPerRequest = 50
+	maxSummaryLength         = 65535
+)
+
+type CheckRunTarget struct {
+	client
+	name           string
+	sha            string
+	detailsURL     string
+	annotationPath string
+}
+
+type checkRun struct {
+	ID          int64          `json:"id,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	HeadSHA     string         `json:"head_sha,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Conclusion  string         `json:"conclusion,omitempty"`
+	CompletedAt string         `json:"completed_at,omitempty"`
+	DetailsURL  string         `json:"details_url,omitempty"`
+	Output      checkRunOutput `json:"output"`
+}
+
+type checkRunOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+type annotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title"`
+	Message         string `json:"message"`
+}
+
+func NewCheckRun(cfg config.GitHubCheckConfig) (*CheckRunTarget, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("github token is required")
+	}
+
+	c, err := newClient(cfg.Token, cfg.BaseURL, cfg.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = defaultCheckName
+	}
+
+	annotationPath := cfg.AnnotationPath
+	if annotationPath == "" {
+		annotationPath = defaultAnnotationPath
+	}
+
+	detailsURL := cfg.DetailsURL
+	if detailsURL == "" {
+		detailsURL = workflowRunURL()
+	}
+
+	return &CheckRunTarget{
+		client:         c,
+		name:           name,
+		sha:            cfg.SHA,
+		detailsURL:     detailsURL,
+		annotationPath: annotationPath,
+	}, nil
+}
+
+// workflowRunURL returns the URL of the current GitHub Actions run, or an
+// empty string outside GitHub Actions.
+func workflowRunURL() string {
+	server, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repository == "" || runID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+}
+
+// ReportsEmptyPlans reports that a check run is created for plans without
+// changes, so that every commit gets a conclusion.
+func (t *CheckRunTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write creates a completed check run for the commit. Annotations beyond the
+// per-request limit are added by updating the check run in batches.
+func (t *CheckRunTarget) Write(p *target.Payload) error {
+	sha := t.sha
+	if sha == "" && p.Metadata != nil && p.Metadata.Git != nil {
+		sha = p.Metadata.Git.CommitSHA
+	}
+	if sha == "" {
+		return fmt.Errorf("github check run requires a commit SHA: none found in git metadata")
+	}
+
+	summary := target.Summarize(p.Plan)
+	output := checkRunOutput{
+		Title:   checkTitle(summary),
+		Summary: target.RenderMarkdown(p, target.MarkdownOptions{Collapsible: true, MaxLength: maxSummaryLength}),
+	}
+	annotations := t.buildAnnotations(p)
+
+	first := annotations
+	if len(first) > maxAnnotationsPerRequest {
+		first = first[:maxAnnotationsPerRequest]
+	}
+	output.Annotations = first
+
+	var created checkRun
+	err := t.api.Do("POST", fmt.Sprintf("%s/repos/%s/check-runs", t.baseURL, t.repository), checkRun{
+		Name:        t.name,
+		HeadSHA:     sha,
+		Status:      "completed",
+		Conclusion:  conclusion(summary),
+		CompletedAt: p.Datetime.Format(time.RFC3339),
+		DetailsURL:  t.detailsURL,
+		Output:      output,
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("error creating github check run: %w", err)
+	}
+
+	for i := len(first); i < len(annotations); i += maxAnnotationsPerRequest {
+		end := min(i+maxAnnotationsPerRequest, len(annotations))
+		output.Annotations = annotations[i:end]
+
+		url := fmt.Sprintf("%s/repos/%s/check-runs/%d", t.baseURL, t.repository, created.ID)
+		if err := t.api.Do("PATCH", url, checkRun{Output: output}, nil); err != nil {
+			return fmt.Errorf("error adding github check run annotations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// conclusion returns neutral for plans without changes, action_required for
+// plans that remove or replace resources, and success otherwise.
+func conclusion(s target.Summary) string {
+	switch {
+	case s.Resources == 0 && s.Outputs == 0:
+		return ConclusionNeutral
+	case s.HasDestructiveChanges():
+		return ConclusionActionRequired
+	default:
+		return ConclusionSuccess
+	}
+}
+
+func checkTitle(s target.Summary) string {
+	if s.Resources == 0 && s.Outputs == 0 {
+		return "No changes"
+	}
+
+	var parts []string
+	for _, c := range []struct {
+		n     int
+		label string
+	}{
+		{s.Added, "to add"},
+		{s.Changed, "to change"},
+		{s.Replaced, "to replace"},
+		{s.Removed, "to destroy"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.label))
+		}
+	}
+	if s.Outputs > 0 {
+		parts = append(parts, fmt.Sprintf("%d output(s) changed", s.Outputs))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildAnnotations creates one annotation per resource change. Plans carry no
+// source locations, so all annotations point at the configured path.
+func (t *CheckRunTarget) buildAnnotations(p *target.Payload) []annotation {
+	var annotations []annotation
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		for _, rc := range groups[status] {
+			level := "notice"
+			if status == target.StatusRemoved || status == target.StatusReplaced {
+				level = "warning"
+			}
+
+			message := fmt.Sprintf("%s will be %s", target.ResourceAddress(rc), status)
+			changes := target.ExtractChanges(rc.Change)
+			if (status == target.StatusChanged || status == target.StatusReplaced) && len(changes) > 0 {
+				message += "\nChanged attributes: " + strings.Join(target.SortedAttributes(changes), ", ")
+			}
+
+			annotations = append(annotations, annotation{
+				Path:            t.annotationPath,
+				StartLine:       1,
+				EndLine:         1,
+				AnnotationLevel: level,
+				Title:           target.ResourceAddress(rc),
+				Message:         message,
+			})
+		}
+	}
+
+	return annotations
+}
@@ -0,0 +1,229 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func checkRunPayload(changes []tfplan.ResourceChange) *target.Payload {
+	return &target.Payload{
+		Plan:     &tfplan.Plan{ResourceChanges: changes},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &target.PayloadMetadata{Git: &git.Metadata{CommitSHA: "abc123def456"}},
+	}
+}
+
+func TestNewCheckRun(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "")
+
+	if _, err := NewCheckRun(config.GitHubCheckConfig{Repository: "example/infra"}); err == nil {
+		t.Error("Expected an error for a missing token")
+	}
+	if _, err := NewCheckRun(config.GitHubCheckConfig{Token: "ghs_token"}); err == nil {
+		t.Error("Expected an error for a missing repository")
+	}
+
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{Token: "ghs_token", Repository: "example/infra"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if checkTarget.name != "Infralog" || checkTarget.annotationPath != "." {
+		t.Errorf("Expected defaults, got name %q and annotation path %q", checkTarget.name, checkTarget.annotationPath)
+	}
+	if !checkTarget.ReportsEmptyPlans() {
+		t.Error("Expected check runs to report empty plans")
+	}
+}
+
+func TestNewCheckRun_DetailsURLFromActions(t *testing.T) {
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "example/infra")
+	t.Setenv("GITHUB_RUN_ID", "1234")
+
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{Token: "ghs_token"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if checkTarget.detailsURL != "https://github.com/example/infra/actions/runs/1234" {
+		t.Errorf("Unexpected details URL: %s", checkTarget.detailsURL)
+	}
+}
+
+func TestConclusion(t *testing.T) {
+	tests := []struct {
+		name     string
+		summary  target.Summary
+		expected string
+	}{
+		{"No changes", target.Summary{}, ConclusionNeutral},
+		{"Only outputs", target.Summary{Outputs: 1}, ConclusionSuccess},
+		{"Safe changes", target.Summary{Resources: 2, Added: 1, Changed: 1}, ConclusionSuccess},
+		{"Replacement", target.Summary{Resources: 1, Replaced: 1}, ConclusionActionRequired},
+		{"Removal", target.Summary{Resources: 2, Added: 1, Removed: 1}, ConclusionActionRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conclusion(tt.summary); got != tt.expected {
+				t.Errorf("conclusion() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckRunWrite_Success(t *testing.T) {
+	var received checkRun
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/example/infra/check-runs" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(checkRun{ID: 1})
+	}))
+	defer server.Close()
+
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{
+		Token:          "ghs_token",
+		BaseURL:        server.URL,
+		Repository:     "example/infra",
+		AnnotationPath: "main.tf",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create check run target: %v", err)
+	}
+
+	payload := checkRunPayload([]tfplan.ResourceChange{
+		{Address: "aws_s3_bucket.logs", Change: tfplan.Change{Actions: []string{"create"}}},
+		{
+			Address: "aws_instance.web",
+			Change: tfplan.Change{
+				Actions: []string{"delete", "create"},
+				Before:  map[string]interface{}{"ami": "ami-1"},
+				After:   map[string]interface{}{"ami": "ami-2"},
+			},
+		},
+	})
+	if err := checkTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if received.HeadSHA != "abc123def456" {
+		t.Errorf("Expected head SHA from git metadata, got %s", received.HeadSHA)
+	}
+	if received.Status != "completed" || received.Conclusion != ConclusionActionRequired {
+		t.Errorf("Expected completed action_required check, got %s/%s", received.Status, received.Conclusion)
+	}
+	if received.CompletedAt != "2025-12-12T10:30:45Z" {
+		t.Errorf("Unexpected completed_at: %s", received.CompletedAt)
+	}
+	if received.Output.Title != "1 to add, 1 to replace" {
+		t.Errorf("Unexpected title: %q", received.Output.Title)
+	}
+	if !strings.Contains(received.Output.Summary, "`aws_instance.web` | replaced") {
+		t.Errorf("Expected markdown summary, got:\n%s", received.Output.Summary)
+	}
+
+	if len(received.Output.Annotations) != 2 {
+		t.Fatalf("Expected 2 annotations, got %d", len(received.Output.Annotations))
+	}
+	replaced := received.Output.Annotations[1]
+	if replaced.Path != "main.tf" || replaced.AnnotationLevel != "warning" || replaced.Title != "aws_instance.web" {
+		t.Errorf("Unexpected annotation: %+v", replaced)
+	}
+	if !strings.Contains(replaced.Message, "Changed attributes: ami") {
+		t.Errorf("Expected changed attributes in annotation, got %q", replaced.Message)
+	}
+	if received.Output.Annotations[0].AnnotationLevel != "notice" {
+		t.Errorf("Expected notice level for creates, got %s", received.Output.Annotations[0].AnnotationLevel)
+	}
+}
+
+func TestCheckRunWrite_AnnotationBatches(t *testing.T) {
+	var requests []string
+	var annotationCounts []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var run checkRun
+		json.NewDecoder(r.Body).Decode(&run)
+		annotationCounts = append(annotationCounts, len(run.Output.Annotations))
+		json.NewEncoder(w).Encode(checkRun{ID: 77})
+	}))
+	defer server.Close()
+
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{Token: "ghs_token", BaseURL: server.URL, Repository: "example/infra"})
+	if err != nil {
+		t.Fatalf("Failed to create check run target: %v", err)
+	}
+
+	var changes []tfplan.ResourceChange
+	for i := 0; i < 120; i++ {
+		changes = append(changes, tfplan.ResourceChange{
+			Address: fmt.Sprintf("aws_s3_bucket.b%d", i),
+			Change:  tfplan.Change{Actions: []string{"create"}},
+		})
+	}
+	if err := checkTarget.Write(checkRunPayload(changes)); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	expected := []string{
+		"POST /repos/example/infra/check-runs",
+		"PATCH /repos/example/infra/check-runs/77",
+		"PATCH /repos/example/infra/check-runs/77",
+	}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+	if fmt.Sprint(annotationCounts) != "[50 50 20]" {
+		t.Errorf("Expected annotations in batches of 50, got %v", annotationCounts)
+	}
+}
+
+func TestCheckRunWrite_NoChanges(t *testing.T) {
+	var received checkRun
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(checkRun{ID: 1})
+	}))
+	defer server.Close()
+
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{
+		Token:      "ghs_token",
+		BaseURL:    server.URL,
+		Repository: "example/infra",
+		SHA:        "feedface",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create check run target: %v", err)
+	}
+
+	if err := checkTarget.Write(checkRunPayload(nil)); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if received.Conclusion != ConclusionNeutral || received.Output.Title != "No changes" {
+		t.Errorf("Expected neutral check with no changes, got %s/%q", received.Conclusion, received.Output.Title)
+	}
+	if received.HeadSHA != "feedface" {
+		t.Errorf("Expected configured SHA to take precedence, got %s", received.HeadSHA)
+	}
+}
+
+func TestCheckRunWrite_MissingSHA(t *testing.T) {
+	checkTarget, err := NewCheckRun(config.GitHubCheckConfig{Token: "ghs_token", Repository: "example/infra"})
+	if err != nil {
+		t.Fatalf("Failed to create check run target: %v", err)
+	}
+	if err := checkTarget.Write(&target.Payload{Plan: &tfplan.Plan{}}); err == nil {
+		t.Error("Expected an error without a commit SHA")
+	}
+}
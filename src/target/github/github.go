@@ -0,0 +1,188 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultBaseURL = "https://api.github.com"
+
+	// GitHub rejects comment bodies longer than 65536 characters.
+	maxCommentLength = 65536
+
+	commentsPerPage = 100
+)
+
+var pullRefPattern = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+type GitHubTarget struct {
+	client
+	pullRequest int
+	marker      string
+}
+
+// client holds the connection details shared by the GitHub targets.
+type client struct {
+	baseURL    string
+	repository string
+	api        *target.APIClient
+}
+
+type comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+func New(cfg config.GitHubConfig) (*GitHubTarget, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("github token is required")
+	}
+
+	c, err := newClient(cfg.Token, cfg.BaseURL, cfg.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequest := cfg.PullRequest
+	if pullRequest == 0 {
+		pullRequest = pullRequestFromEnv()
+	}
+	if pullRequest <= 0 {
+		return nil, fmt.Errorf("github pull request number is required: set it in the config or run in a pull_request workflow")
+	}
+
+	return &GitHubTarget{
+		client:      c,
+		pullRequest: pullRequest,
+		marker:      target.CommentMarker(cfg.Marker),
+	}, nil
+}
+
+// newClient resolves the repository and API URL, falling back to the
+// GITHUB_REPOSITORY and GITHUB_API_URL variables set by GitHub Actions.
+func newClient(token, baseURL, repository string) (client, error) {
+	if repository == "" {
+		repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if owner, name, ok := strings.Cut(repository, "/"); !ok || owner == "" || name == "" {
+		return client{}, fmt.Errorf("github repository must be in owner/repo form, got %q", repository)
+	}
+
+	if baseURL == "" {
+		baseURL = os.Getenv("GITHUB_API_URL")
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		repository: repository,
+		api: target.NewAPIClient(func(req *http.Request) {
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		}),
+	}, nil
+}
+
+// pullRequestFromEnv determines the pull request number in GitHub Actions,
+// first from GITHUB_REF (refs/pull/<number>/merge) and then from the event
+// payload at GITHUB_EVENT_PATH. Returns 0 if neither is available.
+func pullRequestFromEnv() int {
+	if m := pullRefPattern.FindStringSubmatch(os.Getenv("GITHUB_REF")); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			return n
+		}
+	}
+
+	eventPath := os.Getenv("GITHUB_EVENT_PATH")
+	if eventPath == "" {
+		return 0
+	}
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		return 0
+	}
+
+	var event struct {
+		Number      int `json:"number"`
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return 0
+	}
+	if event.PullRequest.Number != 0 {
+		return event.PullRequest.Number
+	}
+	return event.Number
+}
+
+// ReportsEmptyPlans reports that plans without changes are written too, so
+// a comment left by an earlier plan can be updated.
+func (t *GitHubTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write posts the plan summary as a pull request comment, updating the
+// previous Infralog comment if there is one. Plans without changes only
+// update an existing comment.
+func (t *GitHubTarget) Write(p *target.Payload) error {
+	body := target.RenderMarkdown(p, target.MarkdownOptions{
+		Marker:      t.marker,
+		Collapsible: true,
+		MaxLength:   maxCommentLength,
+	})
+
+	post := target.UpsertComment
+	if !p.Plan.HasChanges() {
+		post = target.UpdateExistingComment
+	}
+	if err := post(t, t.marker, body); err != nil {
+		return fmt.Errorf("error posting github comment: %w", err)
+	}
+	return nil
+}
+
+// FindComment returns the ID of the pull request comment containing the marker.
+func (t *GitHubTarget) FindComment(marker string) (string, error) {
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=%d&page=%d",
+			t.baseURL, t.repository, t.pullRequest, commentsPerPage, page)
+
+		var comments []comment
+		if err := t.api.Do("GET", url, nil, &comments); err != nil {
+			return "", err
+		}
+
+		for _, c := range comments {
+			if strings.Contains(c.Body, marker) {
+				return strconv.FormatInt(c.ID, 10), nil
+			}
+		}
+
+		if len(comments) < commentsPerPage {
+			return "", nil
+		}
+	}
+}
+
+func (t *GitHubTarget) CreateComment(body string) error {
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", t.baseURL, t.repository, t.pullRequest)
+	return t.api.Do("POST", url, comment{Body: body}, nil)
+}
+
+func (t *GitHubTarget) UpdateComment(id, body string) error {
+	url := fmt.Sprintf("%s/repos/%s/issues/comments/%s", t.baseURL, t.repository, id)
+	return t.api.Do("PATCH", url, comment{Body: body}, nil)
+}
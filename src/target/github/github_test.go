@@ -0,0 +1,324 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub is a minimal in-memory implementation of the issue comments API.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []comment
+	nextID   int64
+	requests []string
+	auth     string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	f.auth = r.Header.Get("Authorization")
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/repos/example/infra/issues/42/comments":
+		json.NewEncoder(w).Encode(f.comments)
+	case r.Method == "POST" && r.URL.Path == "/repos/example/infra/issues/42/comments":
+		var c comment
+		json.NewDecoder(r.Body).Decode(&c)
+		f.nextID++
+		c.ID = f.nextID
+		f.comments = append(f.comments, c)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/repos/example/infra/issues/comments/"):
+		var c comment
+		json.NewDecoder(r.Body).Decode(&c)
+		for i := range f.comments {
+			if r.URL.Path == fmt.Sprintf("/repos/example/infra/issues/comments/%d", f.comments[i].ID) {
+				f.comments[i].Body = c.Body
+				json.NewEncoder(w).Encode(f.comments[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "")
+	t.Setenv("GITHUB_REF", "")
+	t.Setenv("GITHUB_EVENT_PATH", "")
+
+	tests := []struct {
+		name        string
+		cfg         config.GitHubConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config",
+			cfg:         config.GitHubConfig{Token: "ghp_token", Repository: "example/infra", PullRequest: 42},
+			expectError: false,
+		},
+		{
+			name:        "Missing token",
+			cfg:         config.GitHubConfig{Repository: "example/infra", PullRequest: 42},
+			expectError: true,
+		},
+		{
+			name:        "Invalid repository",
+			cfg:         config.GitHubConfig{Token: "ghp_token", Repository: "infra", PullRequest: 42},
+			expectError: true,
+		},
+		{
+			name:        "Missing pull request",
+			cfg:         config.GitHubConfig{Token: "ghp_token", Repository: "example/infra"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNew_FromActionsEnvironment(t *testing.T) {
+	t.Setenv("GITHUB_REPOSITORY", "example/infra")
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3/")
+	t.Setenv("GITHUB_EVENT_PATH", "")
+
+	t.Setenv("GITHUB_REF", "refs/pull/17/merge")
+	ghTarget, err := New(config.GitHubConfig{Token: "ghp_token"})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+	if ghTarget.pullRequest != 17 {
+		t.Errorf("Expected pull request 17 from GITHUB_REF, got %d", ghTarget.pullRequest)
+	}
+	if ghTarget.repository != "example/infra" {
+		t.Errorf("Expected repository from GITHUB_REPOSITORY, got %s", ghTarget.repository)
+	}
+	if ghTarget.baseURL != "https://github.example.com/api/v3" {
+		t.Errorf("Expected base URL from GITHUB_API_URL, got %s", ghTarget.baseURL)
+	}
+
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(`{"action":"synchronize","pull_request":{"number":23}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_REF", "refs/heads/feature")
+	t.Setenv("GITHUB_EVENT_PATH", eventPath)
+	ghTarget, err = New(config.GitHubConfig{Token: "ghp_token"})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+	if ghTarget.pullRequest != 23 {
+		t.Errorf("Expected pull request 23 from the event payload, got %d", ghTarget.pullRequest)
+	}
+}
+
+func TestWrite_CreatesThenUpdatesComment(t *testing.T) {
+	fake := &fakeGitHub{comments: []comment{{ID: 100, Body: "LGTM"}}, nextID: 100}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ghTarget, err := New(config.GitHubConfig{
+		Token:       "ghp_token",
+		BaseURL:     server.URL,
+		Repository:  "example/infra",
+		PullRequest: 42,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+
+	if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.comments) != 2 {
+		t.Fatalf("Expected a new comment to be created, got %d comments", len(fake.comments))
+	}
+	if fake.auth != "Bearer ghp_token" {
+		t.Errorf("Expected bearer token authorization, got %q", fake.auth)
+	}
+
+	if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.comments) != 2 {
+		t.Fatalf("Expected the existing comment to be updated, got %d comments", len(fake.comments))
+	}
+	if fake.comments[0].Body != "LGTM" {
+		t.Error("Expected unrelated comment to be left alone")
+	}
+
+	body := fake.comments[1].Body
+	if !strings.HasPrefix(body, "<!-- infralog -->") {
+		t.Errorf("Expected hidden marker, got %q", body)
+	}
+	if !strings.Contains(body, "| 🔴 | `aws_instance.web` | removed |") {
+		t.Errorf("Expected updated comment body, got:\n%s", body)
+	}
+
+	last := fake.requests[len(fake.requests)-1]
+	if last != "PATCH /repos/example/infra/issues/comments/101" {
+		t.Errorf("Expected last request to edit comment 101, got %s", last)
+	}
+}
+
+func TestWrite_NoChanges(t *testing.T) {
+	fake := &fakeGitHub{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ghTarget, err := New(config.GitHubConfig{
+		Token:       "ghp_token",
+		BaseURL:     server.URL,
+		Repository:  "example/infra",
+		PullRequest: 42,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+	if !ghTarget.ReportsEmptyPlans() {
+		t.Error("Expected github target to report empty plans")
+	}
+
+	if err := ghTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.comments) != 0 {
+		t.Fatalf("Expected no comment for a plan without changes, got %d", len(fake.comments))
+	}
+
+	if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if err := ghTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.comments) != 1 {
+		t.Fatalf("Expected the existing comment to be updated, got %d comments", len(fake.comments))
+	}
+	if !strings.Contains(fake.comments[0].Body, "No changes.") {
+		t.Errorf("Expected a no changes comment, got:\n%s", fake.comments[0].Body)
+	}
+}
+
+func TestWrite_SeparateMarkers(t *testing.T) {
+	fake := &fakeGitHub{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	for _, marker := range []string{"staging", "prod"} {
+		ghTarget, err := New(config.GitHubConfig{
+			Token:       "ghp_token",
+			BaseURL:     server.URL,
+			Repository:  "example/infra",
+			PullRequest: 42,
+			Marker:      marker,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create github target: %v", err)
+		}
+		if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+	}
+
+	if len(fake.comments) != 2 {
+		t.Errorf("Expected one comment per marker, got %d", len(fake.comments))
+	}
+}
+
+func TestWrite_Pagination(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			pages = append(pages, r.URL.Query().Get("page"))
+			var comments []comment
+			if r.URL.Query().Get("page") == "1" {
+				for i := 0; i < commentsPerPage; i++ {
+					comments = append(comments, comment{ID: int64(i), Body: "noise"})
+				}
+			} else {
+				comments = []comment{{ID: 500, Body: "<!-- infralog -->\nold"}}
+			}
+			json.NewEncoder(w).Encode(comments)
+		case "PATCH":
+			if r.URL.Path != "/repos/example/infra/issues/comments/500" {
+				t.Errorf("Unexpected update path %s", r.URL.Path)
+			}
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	ghTarget, err := New(config.GitHubConfig{
+		Token:       "ghp_token",
+		BaseURL:     server.URL,
+		Repository:  "example/infra",
+		PullRequest: 42,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+	if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if strings.Join(pages, ",") != "1,2" {
+		t.Errorf("Expected two pages to be fetched, got %v", pages)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	ghTarget, err := New(config.GitHubConfig{
+		Token:       "ghp_token",
+		BaseURL:     server.URL,
+		Repository:  "example/infra",
+		PullRequest: 42,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create github target: %v", err)
+	}
+	if err := ghTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
@@ -0,0 +1,159 @@
+package gitlab
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultBaseURL = "https://gitlab.com/api/v4"
+
+	// GitLab rejects note bodies longer than 1000000 characters.
+	maxNoteLength = 1000000
+
+	notesPerPage = 100
+)
+
+type GitLabTarget struct {
+	baseURL      string
+	projectID    string
+	mergeRequest int
+	marker       string
+	destroyLabel string
+	api          *target.APIClient
+}
+
+type note struct {
+	ID     int64  `json:"id"`
+	Body   string `json:"body"`
+	System bool   `json:"system,omitempty"`
+}
+
+type labelUpdate struct {
+	AddLabels    string `json:"add_labels,omitempty"`
+	RemoveLabels string `json:"remove_labels,omitempty"`
+}
+
+func New(cfg config.GitLabConfig) (*GitLabTarget, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("gitlab token is required")
+	}
+
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = os.Getenv("CI_PROJECT_ID")
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("gitlab project ID is required: set it in the config or run in a merge request pipeline")
+	}
+
+	mergeRequest := cfg.MergeRequest
+	if mergeRequest == 0 {
+		mergeRequest, _ = strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID"))
+	}
+	if mergeRequest <= 0 {
+		return nil, fmt.Errorf("gitlab merge request IID is required: set it in the config or run in a merge request pipeline")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("CI_API_V4_URL")
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &GitLabTarget{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		projectID:    projectID,
+		mergeRequest: mergeRequest,
+		marker:       target.CommentMarker(cfg.Marker),
+		destroyLabel: cfg.DestroyLabel,
+		api: target.NewAPIClient(func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", cfg.Token)
+		}),
+	}, nil
+}
+
+// ReportsEmptyPlans reports that plans without changes are written too, so
+// a note left by an earlier plan and the destroy label can be updated.
+func (t *GitLabTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write posts the plan summary as a merge request note, updating the previous
+// Infralog note if there is one, and sets or clears the destroy label. Plans
+// without changes only update an existing note.
+func (t *GitLabTarget) Write(p *target.Payload) error {
+	body := target.RenderMarkdown(p, target.MarkdownOptions{
+		Marker:      t.marker,
+		Collapsible: true,
+		MaxLength:   maxNoteLength,
+	})
+
+	post := target.UpsertComment
+	if !p.Plan.HasChanges() {
+		post = target.UpdateExistingComment
+	}
+	if err := post(t, t.marker, body); err != nil {
+		return fmt.Errorf("error posting gitlab note: %w", err)
+	}
+
+	if t.destroyLabel != "" {
+		if err := t.updateLabels(target.Summarize(p.Plan).HasDestructiveChanges()); err != nil {
+			return fmt.Errorf("error updating gitlab merge request labels: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindComment returns the ID of the merge request note containing the marker.
+func (t *GitLabTarget) FindComment(marker string) (string, error) {
+	for page := 1; ; page++ {
+		endpoint := fmt.Sprintf("%s/notes?sort=asc&per_page=%d&page=%d", t.mergeRequestURL(), notesPerPage, page)
+
+		var notes []note
+		if err := t.api.Do("GET", endpoint, nil, &notes); err != nil {
+			return "", err
+		}
+
+		for _, n := range notes {
+			if !n.System && strings.Contains(n.Body, marker) {
+				return strconv.FormatInt(n.ID, 10), nil
+			}
+		}
+
+		if len(notes) < notesPerPage {
+			return "", nil
+		}
+	}
+}
+
+func (t *GitLabTarget) CreateComment(body string) error {
+	return t.api.Do("POST", t.mergeRequestURL()+"/notes", note{Body: body}, nil)
+}
+
+func (t *GitLabTarget) UpdateComment(id, body string) error {
+	return t.api.Do("PUT", t.mergeRequestURL()+"/notes/"+id, note{Body: body}, nil)
+}
+
+// updateLabels adds the destroy label when the plan removes or replaces
+// resources and removes it otherwise, so the label tracks the latest plan.
+func (t *GitLabTarget) updateLabels(destructive bool) error {
+	update := labelUpdate{RemoveLabels: t.destroyLabel}
+	if destructive {
+		update = labelUpdate{AddLabels: t.destroyLabel}
+	}
+	return t.api.Do("PUT", t.mergeRequestURL(), update, nil)
+}
+
+func (t *GitLabTarget) mergeRequestURL() string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%d", t.baseURL, url.PathEscape(t.projectID), t.mergeRequest)
+}
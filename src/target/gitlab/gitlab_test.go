@@ -0,0 +1,274 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const mergeRequestPath = "/projects/123/merge_requests/7"
+
+// fakeGitLab is a minimal in-memory implementation of the merge request notes API.
+type fakeGitLab struct {
+	mu     sync.Mutex
+	notes  []note
+	nextID int64
+	labels []labelUpdate
+	token  string
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.token = r.Header.Get("PRIVATE-TOKEN")
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == mergeRequestPath+"/notes":
+		json.NewEncoder(w).Encode(f.notes)
+	case r.Method == "POST" && r.URL.Path == mergeRequestPath+"/notes":
+		var n note
+		json.NewDecoder(r.Body).Decode(&n)
+		f.nextID++
+		n.ID = f.nextID
+		f.notes = append(f.notes, n)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(n)
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, mergeRequestPath+"/notes/"):
+		var n note
+		json.NewDecoder(r.Body).Decode(&n)
+		id := strings.TrimPrefix(r.URL.Path, mergeRequestPath+"/notes/")
+		for i := range f.notes {
+			if id == strconv.FormatInt(f.notes[i].ID, 10) {
+				f.notes[i].Body = n.Body
+				json.NewEncoder(w).Encode(f.notes[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == "PUT" && r.URL.Path == mergeRequestPath:
+		var update labelUpdate
+		json.NewDecoder(r.Body).Decode(&update)
+		f.labels = append(f.labels, update)
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("CI_PROJECT_ID", "")
+	t.Setenv("CI_MERGE_REQUEST_IID", "")
+
+	tests := []struct {
+		name        string
+		cfg         config.GitLabConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config",
+			cfg:         config.GitLabConfig{Token: "glpat-token", ProjectID: "123", MergeRequest: 7},
+			expectError: false,
+		},
+		{
+			name:        "Missing token",
+			cfg:         config.GitLabConfig{ProjectID: "123", MergeRequest: 7},
+			expectError: true,
+		},
+		{
+			name:        "Missing project",
+			cfg:         config.GitLabConfig{Token: "glpat-token", MergeRequest: 7},
+			expectError: true,
+		},
+		{
+			name:        "Missing merge request",
+			cfg:         config.GitLabConfig{Token: "glpat-token", ProjectID: "123"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNew_FromCIEnvironment(t *testing.T) {
+	t.Setenv("CI_PROJECT_ID", "123")
+	t.Setenv("CI_MERGE_REQUEST_IID", "7")
+	t.Setenv("CI_API_V4_URL", "https://gitlab.example.com/api/v4")
+
+	glTarget, err := New(config.GitLabConfig{Token: "glpat-token"})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+	if glTarget.mergeRequestURL() != "https://gitlab.example.com/api/v4/projects/123/merge_requests/7" {
+		t.Errorf("Unexpected merge request URL: %s", glTarget.mergeRequestURL())
+	}
+}
+
+func TestMergeRequestURL_ProjectPath(t *testing.T) {
+	t.Setenv("CI_API_V4_URL", "")
+
+	glTarget, err := New(config.GitLabConfig{Token: "glpat-token", ProjectID: "group/infra", MergeRequest: 7})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+	if glTarget.mergeRequestURL() != "https://gitlab.com/api/v4/projects/group%2Finfra/merge_requests/7" {
+		t.Errorf("Expected project path to be escaped, got %s", glTarget.mergeRequestURL())
+	}
+}
+
+func TestWrite_CreatesThenUpdatesNote(t *testing.T) {
+	fake := &fakeGitLab{
+		notes:  []note{{ID: 1, Body: "<!-- infralog --> mentioned by the system", System: true}},
+		nextID: 1,
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	glTarget, err := New(config.GitLabConfig{
+		Token:        "glpat-token",
+		BaseURL:      server.URL,
+		ProjectID:    "123",
+		MergeRequest: 7,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "update"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(fake.notes) != 2 {
+		t.Fatalf("Expected one note to be created and then updated, got %d notes", len(fake.notes))
+	}
+	if fake.token != "glpat-token" {
+		t.Errorf("Expected PRIVATE-TOKEN header, got %q", fake.token)
+	}
+	if !strings.Contains(fake.notes[1].Body, "| 🟡 | `aws_instance.web` | changed |") {
+		t.Errorf("Expected updated note body, got:\n%s", fake.notes[1].Body)
+	}
+	if len(fake.labels) != 0 {
+		t.Errorf("Expected no label updates without destroy_label, got %v", fake.labels)
+	}
+}
+
+func TestWrite_DestroyLabel(t *testing.T) {
+	fake := &fakeGitLab{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	glTarget, err := New(config.GitLabConfig{
+		Token:        "glpat-token",
+		BaseURL:      server.URL,
+		ProjectID:    "123",
+		MergeRequest: 7,
+		DestroyLabel: "infra:destroy",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	expected := []labelUpdate{{AddLabels: "infra:destroy"}, {RemoveLabels: "infra:destroy"}}
+	if len(fake.labels) != 2 || fake.labels[0] != expected[0] || fake.labels[1] != expected[1] {
+		t.Errorf("Expected label to be added then removed, got %+v", fake.labels)
+	}
+}
+
+func TestWrite_NoChanges(t *testing.T) {
+	fake := &fakeGitLab{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	glTarget, err := New(config.GitLabConfig{
+		Token:        "glpat-token",
+		BaseURL:      server.URL,
+		ProjectID:    "123",
+		MergeRequest: 7,
+		DestroyLabel: "infra:destroy",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+	if !glTarget.ReportsEmptyPlans() {
+		t.Error("Expected gitlab target to report empty plans")
+	}
+
+	if err := glTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.notes) != 0 {
+		t.Fatalf("Expected no note for a plan without changes, got %d", len(fake.notes))
+	}
+
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if err := glTarget.Write(targettest.Payload()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(fake.notes) != 1 {
+		t.Fatalf("Expected the existing note to be updated, got %d notes", len(fake.notes))
+	}
+	if !strings.Contains(fake.notes[0].Body, "No changes.") {
+		t.Errorf("Expected a no changes note, got:\n%s", fake.notes[0].Body)
+	}
+	if last := fake.labels[len(fake.labels)-1]; last != (labelUpdate{RemoveLabels: "infra:destroy"}) {
+		t.Errorf("Expected the destroy label to be removed, got %+v", last)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	glTarget, err := New(config.GitLabConfig{
+		Token:        "glpat-token",
+		BaseURL:      server.URL,
+		ProjectID:    "123",
+		MergeRequest: 7,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gitlab target: %v", err)
+	}
+	if err := glTarget.Write(targettest.Payload(targettest.Change("aws_instance.web", "create"))); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
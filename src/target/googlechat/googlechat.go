@@ -0,0 +1,276 @@
+package googlechat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	cardID = "infralog-plan"
+
+	// Maximum number of changed attributes listed per resource.
+	maxAttributes = 5
+
+	// Thread key used when none is configured, so plans for the same branch
+	// share a thread.
+	defaultThreadKey = "{{.Git.Branch}}"
+)
+
+type GoogleChatTarget struct {
+	webhookURL string
+	threadKey  *template.Template
+}
+
+type chatMessage struct {
+	CardsV2 []cardV2 `json:"cardsV2"`
+}
+
+type cardV2 struct {
+	CardID string `json:"cardId"`
+	Card   card   `json:"card"`
+}
+
+type card struct {
+	Header   cardHeader `json:"header"`
+	Sections []section  `json:"sections"`
+}
+
+type cardHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type section struct {
+	Header                    string   `json:"header,omitempty"`
+	Collapsible               bool     `json:"collapsible,omitempty"`
+	UncollapsibleWidgetsCount int      `json:"uncollapsibleWidgetsCount,omitempty"`
+	Widgets                   []widget `json:"widgets"`
+}
+
+type widget struct {
+	DecoratedText *decoratedText `json:"decoratedText,omitempty"`
+	TextParagraph *textParagraph `json:"textParagraph,omitempty"`
+}
+
+type decoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+}
+
+type textParagraph struct {
+	Text string `json:"text"`
+}
+
+func New(cfg config.GoogleChatConfig) (*GoogleChatTarget, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("google chat webhook URL is required")
+	}
+
+	t := &GoogleChatTarget{
+		webhookURL: cfg.WebhookURL,
+	}
+
+	threadKey := cfg.ThreadKey
+	if threadKey == "" {
+		threadKey = defaultThreadKey
+	}
+	tmpl, err := target.ParseTemplate("thread_key", threadKey)
+	if err != nil {
+		return nil, err
+	}
+	t.threadKey = tmpl
+
+	return t, nil
+}
+
+func (t *GoogleChatTarget) Write(p *target.Payload) error {
+	requestURL, err := t.requestURL(p)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(t.buildMessage(p))
+	if err != nil {
+		return fmt.Errorf("error marshaling google chat message: %w", err)
+	}
+
+	resp, err := http.Post(requestURL, "application/json; charset=UTF-8", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending google chat message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("google chat request failed with status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// requestURL returns the webhook URL, with the thread key parameters added
+// when the thread key renders to a non-empty value.
+func (t *GoogleChatTarget) requestURL(p *target.Payload) (string, error) {
+	key, err := target.ExecuteTemplate(t.threadKey, p)
+	if err != nil {
+		return "", err
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return t.webhookURL, nil
+	}
+
+	u, err := url.Parse(t.webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid google chat webhook URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("threadKey", key)
+	query.Set("messageReplyOption", "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func (t *GoogleChatTarget) buildMessage(p *target.Payload) chatMessage {
+	var sections []section
+
+	// Context - timestamp and git metadata
+	contextWidgets := []widget{
+		decorated("Time", p.Datetime.Format("2006-01-02 15:04:05 UTC")),
+	}
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		if git.Committer != "" {
+			contextWidgets = append(contextWidgets, decorated("Committer", git.Committer))
+		}
+		if git.Branch != "" {
+			contextWidgets = append(contextWidgets, decorated("Branch", git.Branch))
+		}
+		if git.CommitSHA != "" {
+			contextWidgets = append(contextWidgets, decorated("Commit", target.ShortSHA(git.CommitSHA)))
+		}
+		if git.RepoURL != "" {
+			contextWidgets = append(contextWidgets, decorated("Repository", git.RepoURL))
+		}
+	}
+	sections = append(sections, section{
+		Header:  "Git Context",
+		Widgets: contextWidgets,
+	})
+
+	// Resource changes, one collapsible section per status
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		group := groups[status]
+		if len(group) == 0 {
+			continue
+		}
+		sections = append(sections, buildResourceSection(status, group))
+	}
+
+	// Output changes
+	if len(p.Plan.OutputChanges) > 0 {
+		sections = append(sections, buildOutputSection(p.Plan.OutputChanges))
+	}
+
+	return chatMessage{
+		CardsV2: []cardV2{
+			{
+				CardID: cardID,
+				Card: card{
+					Header: cardHeader{
+						Title:    "Terraform Plan Changes",
+						Subtitle: target.Summarize(p.Plan).Text(),
+					},
+					Sections: sections,
+				},
+			},
+		},
+	}
+}
+
+func buildResourceSection(status string, changes []tfplan.ResourceChange) section {
+	widgets := make([]widget, 0, len(changes))
+	for _, rc := range changes {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("<b>%s</b>", html.EscapeString(target.ResourceAddress(rc))))
+
+		// Show changed attributes for updates
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			attrChanges := target.ExtractChanges(rc.Change)
+			for i, attr := range target.SortedAttributes(attrChanges) {
+				if i >= maxAttributes {
+					sb.WriteString(fmt.Sprintf("<br>• <i>...and %d more attributes</i>", len(attrChanges)-maxAttributes))
+					break
+				}
+				change := attrChanges[attr]
+				sb.WriteString(fmt.Sprintf("<br>• %s: %s → %s",
+					html.EscapeString(attr),
+					html.EscapeString(fmt.Sprintf("%v", change.Before)),
+					html.EscapeString(fmt.Sprintf("%v", change.After))))
+			}
+		}
+
+		widgets = append(widgets, widget{TextParagraph: &textParagraph{Text: sb.String()}})
+	}
+
+	return section{
+		Header:                    fmt.Sprintf(`<font color="%s">%s (%d)</font>`, statusColor(status), strings.ToUpper(status[:1])+status[1:], len(changes)),
+		Collapsible:               len(widgets) > 5,
+		UncollapsibleWidgetsCount: min(len(widgets), 5),
+		Widgets:                   widgets,
+	}
+}
+
+func buildOutputSection(changes map[string]tfplan.OutputChange) section {
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	widgets := make([]widget, 0, len(names))
+	for _, name := range names {
+		oc := changes[name]
+		status := target.ActionsToStatus(oc.Change.Actions)
+		text := status
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			text = fmt.Sprintf("%v → %v", oc.Change.Before, oc.Change.After)
+		}
+		widgets = append(widgets, decorated(name, text))
+	}
+
+	return section{
+		Header:  "Output Changes",
+		Widgets: widgets,
+	}
+}
+
+func decorated(label, text string) widget {
+	return widget{DecoratedText: &decoratedText{TopLabel: label, Text: html.EscapeString(text)}}
+}
+
+// statusColor maps a change status to a hex color for section headers.
+func statusColor(status string) string {
+	switch status {
+	case target.StatusAdded:
+		return "#188038"
+	case target.StatusRemoved:
+		return "#d93025"
+	case target.StatusChanged, target.StatusReplaced:
+		return "#e37400"
+	default:
+		return "#5f6368"
+	}
+}
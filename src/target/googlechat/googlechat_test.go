@@ -0,0 +1,227 @@
+package googlechat
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.GoogleChatConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with webhook URL",
+			cfg:         config.GoogleChatConfig{WebhookURL: "https://chat.googleapis.com/v1/spaces/AAA/messages"},
+			expectError: false,
+		},
+		{
+			name:        "Empty webhook URL",
+			cfg:         config.GoogleChatConfig{WebhookURL: ""},
+			expectError: true,
+		},
+		{
+			name: "Config with thread key",
+			cfg: config.GoogleChatConfig{
+				WebhookURL: "https://chat.googleapis.com/v1/spaces/AAA/messages",
+				ThreadKey:  "{{.Git.Branch}}",
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid thread key template",
+			cfg: config.GoogleChatConfig{
+				WebhookURL: "https://chat.googleapis.com/v1/spaces/AAA/messages",
+				ThreadKey:  "{{.Git.Branch",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	var receivedBody chatMessage
+	var receivedQuery map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			t.Errorf("Expected application/json content type, got %s", r.Header.Get("Content-Type"))
+		}
+
+		receivedQuery = r.URL.Query()
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	chatTarget, err := New(config.GoogleChatConfig{WebhookURL: server.URL + "?key=k&token=t"})
+	if err != nil {
+		t.Fatalf("Failed to create google chat target: %v", err)
+	}
+
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{
+				Type: "aws_instance",
+				Name: "web",
+				Change: tfplan.Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "t2.micro"},
+					After:   map[string]interface{}{"instance_type": "t2.small"},
+				},
+			},
+		},
+	}
+	payload := &target.Payload{
+		Plan:     plan,
+		Datetime: time.Now().UTC(),
+		Metadata: &target.PayloadMetadata{Git: &git.Metadata{Branch: "main"}},
+	}
+	if err := chatTarget.Write(payload); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	if receivedQuery["key"][0] != "k" || receivedQuery["token"][0] != "t" {
+		t.Errorf("Expected webhook key and token to be preserved, got %v", receivedQuery)
+	}
+	if got := receivedQuery["threadKey"]; len(got) != 1 || got[0] != "main" {
+		t.Errorf("Expected the branch as default thread key, got %v", got)
+	}
+	if len(receivedBody.CardsV2) != 1 {
+		t.Fatalf("Expected 1 card, got %d", len(receivedBody.CardsV2))
+	}
+	card := receivedBody.CardsV2[0].Card
+	if card.Header.Title != "Terraform Plan Changes" {
+		t.Errorf("Unexpected card title: %s", card.Header.Title)
+	}
+	if len(card.Sections) != 2 {
+		t.Fatalf("Expected context and changed sections, got %d", len(card.Sections))
+	}
+	if !strings.Contains(card.Sections[1].Widgets[0].TextParagraph.Text, "instance_type") {
+		t.Error("Expected attribute diff in resource section")
+	}
+}
+
+func TestWrite_ThreadKey(t *testing.T) {
+	var receivedQuery map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedQuery = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	chatTarget, err := New(config.GoogleChatConfig{
+		WebhookURL: server.URL + "?key=k&token=t",
+		ThreadKey:  "infralog-{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create google chat target: %v", err)
+	}
+
+	payload := &target.Payload{
+		Plan:     &tfplan.Plan{},
+		Datetime: time.Now().UTC(),
+		Metadata: &target.PayloadMetadata{Git: &git.Metadata{Branch: "feature/vpc"}},
+	}
+	if err := chatTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if got := receivedQuery["threadKey"]; len(got) != 1 || got[0] != "infralog-feature/vpc" {
+		t.Errorf("Expected threadKey infralog-feature/vpc, got %v", got)
+	}
+	if got := receivedQuery["messageReplyOption"]; len(got) != 1 || got[0] != "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD" {
+		t.Errorf("Expected messageReplyOption to fall back to a new thread, got %v", got)
+	}
+
+	// Without git metadata the default key renders empty and no thread is used.
+	chatTarget, err = New(config.GoogleChatConfig{WebhookURL: server.URL + "?key=k&token=t"})
+	if err != nil {
+		t.Fatalf("Failed to create google chat target: %v", err)
+	}
+	payload.Metadata = nil
+	if err := chatTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if _, exists := receivedQuery["threadKey"]; exists {
+		t.Errorf("Expected no thread key when it renders empty, got %v", receivedQuery)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	chatTarget, err := New(config.GoogleChatConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create google chat target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{})
+	if err := chatTarget.Write(payload); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestBuildResourceSection_EscapesHTML(t *testing.T) {
+	changes := []tfplan.ResourceChange{
+		{
+			Address: `aws_instance.web["<a>"]`,
+			Change: tfplan.Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"user_data": "<script>"},
+				After:   map[string]interface{}{"user_data": "ok"},
+			},
+		},
+	}
+
+	s := buildResourceSection(target.StatusChanged, changes)
+
+	text := s.Widgets[0].TextParagraph.Text
+	if strings.Contains(text, "<script>") || strings.Contains(text, `"<a>"`) {
+		t.Errorf("Expected values to be HTML-escaped, got %s", text)
+	}
+	if !strings.Contains(s.Header, "Changed (1)") {
+		t.Errorf("Unexpected section header: %s", s.Header)
+	}
+}
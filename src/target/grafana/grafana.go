@@ -0,0 +1,124 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// maxResources is the number of resource changes listed in an annotation.
+	maxResources = 10
+
+	requestTimeout = 30 * time.Second
+)
+
+type annotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	PanelID      int      `json:"panelId,omitempty"`
+	Time         int64    `json:"time"` // Unix time in milliseconds
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+type GrafanaTarget struct {
+	url          string
+	token        string
+	dashboardUID string
+	panelID      int
+	tags         []string
+	client       *http.Client
+}
+
+func New(cfg config.GrafanaConfig) (*GrafanaTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("grafana URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("grafana token is required")
+	}
+	if cfg.PanelID != 0 && cfg.DashboardUID == "" {
+		return nil, fmt.Errorf("grafana dashboard UID is required with a panel ID")
+	}
+
+	return &GrafanaTarget{
+		url:          strings.TrimSuffix(cfg.URL, "/") + "/api/annotations",
+		token:        cfg.Token,
+		dashboardUID: cfg.DashboardUID,
+		panelID:      cfg.PanelID,
+		tags:         cfg.Tags,
+		client:       &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write creates an annotation at the time of the run.
+func (t *GrafanaTarget) Write(p *target.Payload) error {
+	jsonData, err := json.Marshal(t.buildAnnotation(p))
+	if err != nil {
+		return fmt.Errorf("error marshaling annotation: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("grafana returned status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (t *GrafanaTarget) buildAnnotation(p *target.Payload) annotation {
+	return annotation{
+		DashboardUID: t.dashboardUID,
+		PanelID:      t.panelID,
+		Time:         p.Datetime.UnixMilli(),
+		Tags:         append(append([]string{"infralog"}, target.Tags(p)...), t.tags...),
+		Text:         buildText(p),
+	}
+}
+
+// buildText returns the annotation text: a summary line with the git
+// context, followed by the first resource changes.
+func buildText(p *target.Payload) string {
+	s := target.Summarize(p.Plan)
+
+	var sb strings.Builder
+	sb.WriteString("Terraform plan")
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		g := p.Metadata.Git
+		if g.Branch != "" {
+			sb.WriteString(" on " + g.Branch)
+		}
+		if g.CommitSHA != "" {
+			sb.WriteString(" (" + target.ShortSHA(g.CommitSHA) + ")")
+		}
+	}
+	fmt.Fprintf(&sb, ": %d resource(s) changed (%d added, %d changed, %d replaced, %d removed)",
+		s.Resources, s.Added, s.Changed, s.Replaced, s.Removed)
+
+	for i, rc := range p.Plan.ResourceChanges {
+		if i == maxResources {
+			fmt.Fprintf(&sb, "\n... and %d more", len(p.Plan.ResourceChanges)-maxResources)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%s %s", target.ResourceAddress(rc), target.ActionsToStatus(rc.Change.Actions))
+	}
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target/targettest"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var got annotation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/annotations" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer glsa_token" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("Failed to decode annotation: %v", err)
+		}
+		w.Write([]byte(`{"id":1,"message":"Annotation added"}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.GrafanaConfig{URL: server.URL + "/", Token: "glsa_token", DashboardUID: "infra", PanelID: 2, Tags: []string{"env:prod"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete"), targettest.Change("aws_s3_bucket.data", "create"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if got.DashboardUID != "infra" || got.PanelID != 2 || got.Time != 1765535445000 {
+		t.Errorf("Unexpected annotation: %+v", got)
+	}
+
+	expectedTags := "infralog,repo:company/infrastructure,branch:main,action:create,action:delete,env:prod"
+	if tags := strings.Join(got.Tags, ","); tags != expectedTags {
+		t.Errorf("Expected tags %s, got %s", expectedTags, tags)
+	}
+
+	expectedText := "Terraform plan on main (abc123de): 2 resource(s) changed (1 added, 0 changed, 0 replaced, 1 removed)\naws_instance.web removed\naws_s3_bucket.data added"
+	if got.Text != expectedText {
+		t.Errorf("Unexpected text:\n%s", got.Text)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"invalid API key"}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.GrafanaConfig{URL: server.URL, Token: "wrong"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "invalid API key") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestBuildText_LimitsResources(t *testing.T) {
+	var changes []tfplan.ResourceChange
+	for i := 0; i < 15; i++ {
+		changes = append(changes, targettest.Change(fmt.Sprintf("aws_s3_bucket.b%d", i), "create"))
+	}
+	text := buildText(targettest.Payload(changes...))
+
+	lines := strings.Split(text, "\n")
+	if len(lines) != 1+maxResources+1 {
+		t.Fatalf("Expected summary, %d resources and a note, got %d lines", maxResources, len(lines))
+	}
+	if lines[len(lines)-1] != "... and 5 more" {
+		t.Errorf("Unexpected note %q", lines[len(lines)-1])
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.GrafanaConfig
+	}{
+		{"Missing URL", config.GrafanaConfig{Token: "glsa_token"}},
+		{"Missing token", config.GrafanaConfig{URL: "https://grafana.example.com"}},
+		{"Panel without dashboard", config.GrafanaConfig{URL: "https://grafana.example.com", Token: "glsa_token", PanelID: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package jira
+
+import (
+	"fmt"
+	"infralog/target"
+	"sort"
+)
+
+// node is an Atlassian Document Format node.
+type node struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []node                 `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []mark                 `json:"marks,omitempty"`
+}
+
+type mark struct {
+	Type string `json:"type"`
+}
+
+func text(s string, marks ...string) node {
+	n := node{Type: "text", Text: s}
+	for _, m := range marks {
+		n.Marks = append(n.Marks, mark{Type: m})
+	}
+	return n
+}
+
+func paragraph(content ...node) node {
+	return node{Type: "paragraph", Content: content}
+}
+
+func heading(level int, s string) node {
+	return node{Type: "heading", Attrs: map[string]interface{}{"level": level}, Content: []node{text(s)}}
+}
+
+func bulletList(items ...node) node {
+	return node{Type: "bulletList", Content: items}
+}
+
+func listItem(content ...node) node {
+	return node{Type: "listItem", Content: content}
+}
+
+// buildDocument renders the plan summary as an ADF document: the summary,
+// git context, resource changes grouped by action with changed attributes,
+// and output changes.
+func buildDocument(p *target.Payload) node {
+	s := target.Summarize(p.Plan)
+	content := []node{
+		paragraph(text(fmt.Sprintf("Terraform plan changes: %d resource(s), %d output(s)", s.Resources, s.Outputs), "strong")),
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		var items []node
+		for _, field := range []struct{ label, value string }{
+			{"Branch", git.Branch},
+			{"Commit", target.ShortSHA(git.CommitSHA)},
+			{"Committer", git.Committer},
+			{"Repository", git.RepoURL},
+		} {
+			if field.value != "" {
+				items = append(items, listItem(paragraph(text(field.label+": ", "strong"), text(field.value, "code"))))
+			}
+		}
+		if len(items) > 0 {
+			content = append(content, bulletList(items...))
+		}
+	}
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		changes := groups[status]
+		if len(changes) == 0 {
+			continue
+		}
+
+		content = append(content, heading(3, fmt.Sprintf("%s (%d)", statusTitle(status), len(changes))))
+
+		var items []node
+		for _, rc := range changes {
+			item := listItem(paragraph(text(target.ResourceAddress(rc), "code")))
+
+			if status == target.StatusChanged || status == target.StatusReplaced {
+				attrs := target.ExtractChanges(rc.Change)
+				var attrItems []node
+				for _, name := range target.SortedAttributes(attrs) {
+					attrItems = append(attrItems, listItem(paragraph(
+						text(name, "code"),
+						text(fmt.Sprintf(": %s → %s", target.FormatValue(attrs[name].Before), target.FormatValue(attrs[name].After))),
+					)))
+				}
+				if len(attrItems) > 0 {
+					item.Content = append(item.Content, bulletList(attrItems...))
+				}
+			}
+
+			items = append(items, item)
+		}
+		content = append(content, bulletList(items...))
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		names := make([]string, 0, len(p.Plan.OutputChanges))
+		for name := range p.Plan.OutputChanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		content = append(content, heading(3, fmt.Sprintf("Outputs (%d)", len(names))))
+		var items []node
+		for _, name := range names {
+			status := target.ActionsToStatus(p.Plan.OutputChanges[name].Change.Actions)
+			items = append(items, listItem(paragraph(text(name, "code"), text(" - "+status))))
+		}
+		content = append(content, bulletList(items...))
+	}
+
+	return node{Type: "doc", Version: 1, Content: content}
+}
+
+func statusTitle(status string) string {
+	switch status {
+	case target.StatusAdded:
+		return "Added"
+	case target.StatusChanged:
+		return "Changed"
+	case target.StatusReplaced:
+		return "Replaced"
+	case target.StatusRemoved:
+		return "Removed"
+	default:
+		return "Unknown"
+	}
+}
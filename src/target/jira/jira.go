@@ -0,0 +1,229 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	defaultIssueType = "Task"
+	defaultSummary   = "Terraform changes{{if .Git.Branch}} on {{.Git.Branch}}{{end}}: {{.Summary.Resources}} resource(s)"
+
+	// Jira rejects summaries longer than 255 characters.
+	maxSummaryLength = 255
+
+	// Jira Cloud's REST API v3 takes descriptions and comments in Atlassian
+	// Document Format. Data Center only has v2, which takes wiki markup.
+	cloudAPIPath      = "/rest/api/3"
+	dataCenterAPIPath = "/rest/api/2"
+)
+
+type JiraTarget struct {
+	url          string
+	cloud        bool
+	project      string
+	issueType    string
+	summary      *template.Template
+	labels       []string
+	customFields map[string]interface{}
+	issueKey     *regexp.Regexp
+	result       string
+	api          *target.APIClient
+}
+
+type issueFields struct {
+	Project     map[string]string `json:"project"`
+	IssueType   map[string]string `json:"issuetype"`
+	Summary     string            `json:"summary"`
+	Description interface{}       `json:"description"`
+	Labels      []string          `json:"labels,omitempty"`
+}
+
+func New(cfg config.JiraConfig) (*JiraTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("jira URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("jira token is required")
+	}
+
+	// Issue keys are upper case, whatever case the project is configured in.
+	project := strings.ToUpper(cfg.Project)
+	if project == "" {
+		return nil, fmt.Errorf("jira project is required")
+	}
+
+	issueType := cfg.IssueType
+	if issueType == "" {
+		issueType = defaultIssueType
+	}
+
+	summaryText := cfg.Summary
+	if summaryText == "" {
+		summaryText = defaultSummary
+	}
+	summary, err := target.ParseTemplate("summary", summaryText)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, label := range cfg.Labels {
+		if strings.ContainsAny(label, " \t") {
+			return nil, fmt.Errorf("invalid label: %q. Jira labels cannot contain spaces", label)
+		}
+	}
+
+	return &JiraTarget{
+		url:          strings.TrimSuffix(cfg.URL, "/"),
+		cloud:        cfg.Email != "",
+		project:      project,
+		issueType:    issueType,
+		summary:      summary,
+		labels:       cfg.Labels,
+		customFields: normalizeFields(cfg.CustomFields),
+		issueKey:     regexp.MustCompile(`\b` + regexp.QuoteMeta(project) + `-[1-9][0-9]*\b`),
+		// Jira Cloud authenticates with an email and API token, Data Center
+		// with a personal access token.
+		api: target.NewAPIClient(func(req *http.Request) {
+			if cfg.Email != "" {
+				req.SetBasicAuth(cfg.Email, cfg.Token)
+			} else {
+				req.Header.Set("Authorization", "Bearer "+cfg.Token)
+			}
+		}),
+	}, nil
+}
+
+// Write comments on the issue referenced by the branch name or commit
+// message, or creates a new issue if there is none.
+func (t *JiraTarget) Write(p *target.Payload) error {
+	if key := t.findIssueKey(p); key != "" {
+		if err := t.addComment(key, p); err != nil {
+			return fmt.Errorf("error commenting on jira issue %s: %w", key, err)
+		}
+		t.result = "commented on " + key
+		return nil
+	}
+
+	key, err := t.createIssue(p)
+	if err != nil {
+		return fmt.Errorf("error creating jira issue: %w", err)
+	}
+	t.result = "created " + key
+	return nil
+}
+
+// Result returns the key of the issue created or commented on by the last Write.
+func (t *JiraTarget) Result() string {
+	return t.result
+}
+
+// findIssueKey returns the first issue key of the configured project found in
+// the branch name, then the commit message.
+func (t *JiraTarget) findIssueKey(p *target.Payload) string {
+	if p.Metadata == nil || p.Metadata.Git == nil {
+		return ""
+	}
+
+	for _, s := range []string{p.Metadata.Git.Branch, p.Metadata.Git.CommitMessage} {
+		// Branch names are often lower case, e.g. feature/ops-123-add-vpc
+		if key := t.issueKey.FindString(strings.ToUpper(s)); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// createIssue creates a new issue and returns its key.
+func (t *JiraTarget) createIssue(p *target.Payload) (string, error) {
+	summary, err := target.ExecuteTemplate(t.summary, p)
+	if err != nil {
+		return "", err
+	}
+	summary = target.Truncate(summary, maxSummaryLength)
+
+	// Standard fields are encoded first, then custom fields are merged in.
+	fieldsJSON, err := json.Marshal(issueFields{
+		Project:     map[string]string{"key": t.project},
+		IssueType:   map[string]string{"name": t.issueType},
+		Summary:     summary,
+		Description: t.description(p),
+		Labels:      t.labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling issue: %w", err)
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(fieldsJSON, &fields); err != nil {
+		return "", err
+	}
+	for name, value := range t.customFields {
+		fields[name] = value
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := t.api.Do("POST", t.apiURL()+"/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+func (t *JiraTarget) addComment(key string, p *target.Payload) error {
+	return t.api.Do("POST", t.apiURL()+"/issue/"+key+"/comment", map[string]interface{}{"body": t.description(p)}, nil)
+}
+
+// apiURL returns the REST API URL: v3 for Jira Cloud, v2 for Data Center.
+func (t *JiraTarget) apiURL() string {
+	if t.cloud {
+		return t.url + cloudAPIPath
+	}
+	return t.url + dataCenterAPIPath
+}
+
+// description renders the plan summary for an issue description or comment:
+// an ADF document for Jira Cloud, wiki markup for Data Center.
+func (t *JiraTarget) description(p *target.Payload) interface{} {
+	if t.cloud {
+		return buildDocument(p)
+	}
+	return buildWikiText(p)
+}
+
+// normalizeFields converts the map[interface{}]interface{} values produced by
+// the YAML decoder into JSON-encodable maps.
+func normalizeFields(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		result[k] = normalizeValue(v)
+	}
+	return result
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		return normalizeFields(v)
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeValue(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
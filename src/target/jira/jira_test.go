@@ -0,0 +1,307 @@
+package jira
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeJira records the issues and comments created through the REST API.
+type fakeJira struct {
+	issues   []map[string]interface{}
+	comments map[string][]interface{}
+	apiPath  string
+	username string
+	password string
+	bearer   string
+}
+
+func newFakeJira(t *testing.T) (*fakeJira, *httptest.Server) {
+	fake := &fakeJira{comments: map[string][]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.username, fake.password, _ = r.BasicAuth()
+		fake.bearer = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		// Cloud uses REST API v3, Data Center v2; both have the same paths.
+		path := r.URL.Path
+		for _, prefix := range []string{"/rest/api/3", "/rest/api/2"} {
+			if strings.HasPrefix(path, prefix+"/") {
+				fake.apiPath, path = prefix, strings.TrimPrefix(path, prefix)
+			}
+		}
+
+		switch {
+		case r.Method == "POST" && path == "/issue":
+			fields := body["fields"].(map[string]interface{})
+			if fields["summary"] == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors":{"summary":"You must specify a summary of the issue."}}`))
+				return
+			}
+			fake.issues = append(fake.issues, fields)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"10001","key":"OPS-1"}`))
+		case r.Method == "POST" && strings.HasPrefix(path, "/issue/") && strings.HasSuffix(path, "/comment"):
+			key := strings.TrimSuffix(strings.TrimPrefix(path, "/issue/"), "/comment")
+			fake.comments[key] = append(fake.comments[key], body["body"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"20001"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return fake, server
+}
+
+// jiraPayload returns a payload that adds a bucket and resizes an instance,
+// committed to branch with the commit message.
+func jiraPayload(branch, message string) *target.Payload {
+	update := targettest.Change("aws_instance.web", "update")
+	update.Change.Before = map[string]interface{}{"instance_type": "t2.micro"}
+	update.Change.After = map[string]interface{}{"instance_type": "t2.small"}
+
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), update)
+	p.Metadata.Git.Branch = branch
+	p.Metadata.Git.CommitMessage = message
+	return p
+}
+
+func TestNew(t *testing.T) {
+	valid := config.JiraConfig{URL: "https://example.atlassian.net", Token: "jira-token", Project: "OPS"}
+
+	tests := []struct {
+		name        string
+		modify      func(cfg *config.JiraConfig)
+		expectError bool
+	}{
+		{"Valid config", func(cfg *config.JiraConfig) {}, false},
+		{"Missing URL", func(cfg *config.JiraConfig) { cfg.URL = "" }, true},
+		{"Missing token", func(cfg *config.JiraConfig) { cfg.Token = "" }, true},
+		{"Missing project", func(cfg *config.JiraConfig) { cfg.Project = "" }, true},
+		{"Label with spaces", func(cfg *config.JiraConfig) { cfg.Labels = []string{"infra change"} }, true},
+		{"Invalid summary template", func(cfg *config.JiraConfig) { cfg.Summary = "{{.Git.Branch" }, true},
+		{"Lowercase project", func(cfg *config.JiraConfig) { cfg.Project = "ops" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			target, err := New(cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestFindIssueKey(t *testing.T) {
+	jiraTarget, err := New(config.JiraConfig{URL: "https://jira.example.com", Token: "t", Project: "OPS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch   string
+		message  string
+		expected string
+	}{
+		{"feature/OPS-123-add-vpc", "", "OPS-123"},
+		{"feature/ops-42-lowercase", "", "OPS-42"},
+		{"main", "OPS-7: rotate certificates", "OPS-7"},
+		{"feature/OPS-1", "OPS-2: commit key", "OPS-1"},
+		{"feature/DEV-9-other-project", "", ""},
+		{"feature/TOPS-5", "", ""},
+		{"main", "no issue here", ""},
+	}
+
+	for _, tt := range tests {
+		if got := jiraTarget.findIssueKey(jiraPayload(tt.branch, tt.message)); got != tt.expected {
+			t.Errorf("findIssueKey(%q, %q) = %q, want %q", tt.branch, tt.message, got, tt.expected)
+		}
+	}
+}
+
+func TestFindIssueKey_LowercaseProject(t *testing.T) {
+	jiraTarget, err := New(config.JiraConfig{URL: "https://jira.example.com", Token: "t", Project: "ops"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, branch := range []string{"feature/ops-42-add-vpc", "feature/OPS-42-add-vpc"} {
+		if got := jiraTarget.findIssueKey(jiraPayload(branch, "")); got != "OPS-42" {
+			t.Errorf("findIssueKey(%q) = %q, want OPS-42", branch, got)
+		}
+	}
+	if jiraTarget.project != "OPS" {
+		t.Errorf("Expected project key OPS for new issues, got %q", jiraTarget.project)
+	}
+}
+
+func TestWrite_CreatesIssue(t *testing.T) {
+	fake, server := newFakeJira(t)
+	defer server.Close()
+
+	jiraTarget, err := New(config.JiraConfig{
+		URL:       server.URL,
+		Email:     "ci@example.com",
+		Token:     "jira-token",
+		Project:   "OPS",
+		IssueType: "Change",
+		Labels:    []string{"terraform"},
+		CustomFields: map[string]interface{}{
+			"customfield_10010": map[interface{}]interface{}{"value": "Production"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create jira target: %v", err)
+	}
+
+	if err := jiraTarget.Write(jiraPayload("main", "Resize web instance")); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if fake.username != "ci@example.com" || fake.password != "jira-token" {
+		t.Errorf("Expected basic auth with email and API token, got %q/%q", fake.username, fake.password)
+	}
+	if fake.apiPath != "/rest/api/3" {
+		t.Errorf("Expected REST API v3 for Jira Cloud, got %s", fake.apiPath)
+	}
+	if len(fake.issues) != 1 {
+		t.Fatalf("Expected 1 issue to be created, got %d", len(fake.issues))
+	}
+	if jiraTarget.Result() != "created OPS-1" {
+		t.Errorf("Unexpected result: %q", jiraTarget.Result())
+	}
+
+	issue := fake.issues[0]
+	if issue["summary"] != "Terraform changes on main: 2 resource(s)" {
+		t.Errorf("Unexpected summary: %v", issue["summary"])
+	}
+	if issue["project"].(map[string]interface{})["key"] != "OPS" {
+		t.Errorf("Unexpected project: %v", issue["project"])
+	}
+	if issue["issuetype"].(map[string]interface{})["name"] != "Change" {
+		t.Errorf("Unexpected issue type: %v", issue["issuetype"])
+	}
+	if labels := issue["labels"].([]interface{}); len(labels) != 1 || labels[0] != "terraform" {
+		t.Errorf("Unexpected labels: %v", labels)
+	}
+	if custom := issue["customfield_10010"].(map[string]interface{}); custom["value"] != "Production" {
+		t.Errorf("Unexpected custom field: %v", custom)
+	}
+
+	description := issue["description"].(map[string]interface{})
+	if description["type"] != "doc" || description["version"] != float64(1) {
+		t.Errorf("Expected ADF document, got %v", description)
+	}
+	descriptionJSON, _ := json.Marshal(description)
+	for _, s := range []string{`"text":"aws_instance.web"`, `"text":"instance_type"`, `": t2.micro → t2.small"`, `"text":"Changed (1)"`} {
+		if !strings.Contains(string(descriptionJSON), s) {
+			t.Errorf("Expected description to contain %s, got %s", s, descriptionJSON)
+		}
+	}
+}
+
+func TestWrite_CommentsOnExistingIssue(t *testing.T) {
+	fake, server := newFakeJira(t)
+	defer server.Close()
+
+	jiraTarget, err := New(config.JiraConfig{URL: server.URL, Token: "jira-pat", Project: "OPS"})
+	if err != nil {
+		t.Fatalf("Failed to create jira target: %v", err)
+	}
+
+	if err := jiraTarget.Write(jiraPayload("feature/OPS-123-add-vpc", "")); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if fake.bearer != "jira-pat" {
+		t.Errorf("Expected personal access token without email, got %q", fake.bearer)
+	}
+	if fake.apiPath != "/rest/api/2" {
+		t.Errorf("Expected REST API v2 for Jira Data Center, got %s", fake.apiPath)
+	}
+	if len(fake.issues) != 0 {
+		t.Errorf("Expected no issue to be created, got %d", len(fake.issues))
+	}
+	if len(fake.comments["OPS-123"]) != 1 {
+		t.Fatalf("Expected a comment on OPS-123, got %v", fake.comments)
+	}
+	if jiraTarget.Result() != "commented on OPS-123" {
+		t.Errorf("Unexpected result: %q", jiraTarget.Result())
+	}
+	body, ok := fake.comments["OPS-123"][0].(string)
+	if !ok || !strings.Contains(body, "h3. Changed (1)") {
+		t.Errorf("Expected wiki markup comment body, got %v", fake.comments["OPS-123"][0])
+	}
+}
+
+func TestWrite_DataCenterCreatesIssue(t *testing.T) {
+	fake, server := newFakeJira(t)
+	defer server.Close()
+
+	jiraTarget, err := New(config.JiraConfig{URL: server.URL, Token: "jira-pat", Project: "OPS"})
+	if err != nil {
+		t.Fatalf("Failed to create jira target: %v", err)
+	}
+
+	if err := jiraTarget.Write(jiraPayload("main", "Resize web instance")); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if fake.apiPath != "/rest/api/2" {
+		t.Errorf("Expected REST API v2 for Jira Data Center, got %s", fake.apiPath)
+	}
+	if len(fake.issues) != 1 {
+		t.Fatalf("Expected 1 issue to be created, got %d", len(fake.issues))
+	}
+	description, ok := fake.issues[0]["description"].(string)
+	if !ok {
+		t.Fatalf("Expected a wiki markup description, got %v", fake.issues[0]["description"])
+	}
+	for _, s := range []string{"*Terraform plan changes: 2 resource(s), 0 output(s)*", "* *Branch:* {{main}}", "h3. Changed (1)", "* {{aws_instance.web}}", "** {{instance_type}}: t2.micro → t2.small"} {
+		if !strings.Contains(description, s) {
+			t.Errorf("Expected description to contain %q, got:\n%s", s, description)
+		}
+	}
+}
+
+func TestWrite_ErrorIncludesResponse(t *testing.T) {
+	_, server := newFakeJira(t)
+	defer server.Close()
+
+	jiraTarget, err := New(config.JiraConfig{URL: server.URL, Token: "jira-pat", Project: "OPS", Summary: "{{.Git.CommitMessage}}"})
+	if err != nil {
+		t.Fatalf("Failed to create jira target: %v", err)
+	}
+
+	err = jiraTarget.Write(jiraPayload("main", ""))
+	if err == nil || !strings.Contains(err.Error(), "You must specify a summary") {
+		t.Errorf("Expected error with Jira's message, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package jira
+
+import (
+	"fmt"
+	"infralog/target"
+	"sort"
+	"strings"
+)
+
+// buildWikiText renders the plan summary in Jira wiki markup, for Data Center
+// instances whose REST API v2 doesn't accept ADF. The content matches
+// buildDocument.
+func buildWikiText(p *target.Payload) string {
+	var sb strings.Builder
+
+	s := target.Summarize(p.Plan)
+	sb.WriteString(fmt.Sprintf("*Terraform plan changes: %d resource(s), %d output(s)*\n", s.Resources, s.Outputs))
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		var lines []string
+		for _, field := range []struct{ label, value string }{
+			{"Branch", git.Branch},
+			{"Commit", target.ShortSHA(git.CommitSHA)},
+			{"Committer", git.Committer},
+			{"Repository", git.RepoURL},
+		} {
+			if field.value != "" {
+				lines = append(lines, fmt.Sprintf("* *%s:* %s", field.label, monospace(field.value)))
+			}
+		}
+		if len(lines) > 0 {
+			sb.WriteString("\n" + strings.Join(lines, "\n") + "\n")
+		}
+	}
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		changes := groups[status]
+		if len(changes) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\nh3. %s (%d)\n", statusTitle(status), len(changes)))
+		for _, rc := range changes {
+			sb.WriteString("* " + monospace(target.ResourceAddress(rc)) + "\n")
+
+			if status == target.StatusChanged || status == target.StatusReplaced {
+				attrs := target.ExtractChanges(rc.Change)
+				for _, name := range target.SortedAttributes(attrs) {
+					sb.WriteString(fmt.Sprintf("** %s: %s → %s\n", monospace(name), target.FormatValue(attrs[name].Before), target.FormatValue(attrs[name].After)))
+				}
+			}
+		}
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		names := make([]string, 0, len(p.Plan.OutputChanges))
+		for name := range p.Plan.OutputChanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString(fmt.Sprintf("\nh3. Outputs (%d)\n", len(names)))
+		for _, name := range names {
+			status := target.ActionsToStatus(p.Plan.OutputChanges[name].Change.Actions)
+			sb.WriteString(fmt.Sprintf("* %s - %s\n", monospace(name), status))
+		}
+	}
+
+	return sb.String()
+}
+
+// monospace formats s as inline code. Braces would end the {{...}} block
+// early, so they are escaped.
+func monospace(s string) string {
+	s = strings.NewReplacer("{", `\{`, "}", `\}`).Replace(s)
+	return "{{" + s + "}}"
+}
@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SASL mechanisms.
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+)
+
+const (
+	// Keys messages by repository and branch, the unit a plan is made for, so
+	// the plans for one workspace stay in order within a partition.
+	defaultKey = "{{.Git.RepoURL}}/{{.Git.Branch}}"
+
+	writeTimeout = 30 * time.Second
+)
+
+var acks = map[string]kafkago.RequiredAcks{
+	"all":    kafkago.RequireAll,
+	"leader": kafkago.RequireOne,
+	"none":   kafkago.RequireNone,
+}
+
+// messageWriter is the subset of kafka-go's Writer used by the target.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+type KafkaTarget struct {
+	key         *template.Template
+	perResource bool
+	cloudEvents *target.CloudEvents
+	newWriter   func() messageWriter
+}
+
+func New(cfg config.KafkaConfig) (*KafkaTarget, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers are required")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka topic is required")
+	}
+
+	ackMode := strings.ToLower(cfg.Acks)
+	if ackMode == "" {
+		ackMode = "all"
+	}
+	requiredAcks, ok := acks[ackMode]
+	if !ok {
+		return nil, fmt.Errorf("invalid acks: %s. Acks must be all, leader or none", cfg.Acks)
+	}
+
+	keyText := cfg.Key
+	if keyText == "" {
+		keyText = defaultKey
+	}
+	key, err := target.ParseTemplate("key", keyText)
+	if err != nil {
+		return nil, err
+	}
+
+	cloudEvents, err := target.NewCloudEvents(cfg.CloudEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &kafkago.Transport{}
+	if cfg.TLS {
+		if transport.TLS, err = tlsConfig(cfg.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.SASL.Mechanism != "" {
+		if transport.SASL, err = saslMechanism(cfg.SASL); err != nil {
+			return nil, err
+		}
+	}
+
+	return &KafkaTarget{
+		key:         key,
+		perResource: cfg.MessagePerResource,
+		cloudEvents: cloudEvents,
+		newWriter: func() messageWriter {
+			return &kafkago.Writer{
+				Addr:         kafkago.TCP(cfg.Brokers...),
+				Topic:        cfg.Topic,
+				Balancer:     &kafkago.Murmur2Balancer{},
+				RequiredAcks: requiredAcks,
+				BatchTimeout: 10 * time.Millisecond,
+				Transport:    transport,
+			}
+		},
+	}, nil
+}
+
+// Write publishes the payload, or one message per resource change, and waits
+// for the configured acknowledgements.
+func (t *KafkaTarget) Write(p *target.Payload) error {
+	msgs, err := t.buildMessages(p)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	w := t.newWriter()
+	if err := w.WriteMessages(ctx, msgs...); err != nil {
+		w.Close()
+		return fmt.Errorf("error publishing to kafka: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error closing kafka writer: %w", err)
+	}
+	return nil
+}
+
+func (t *KafkaTarget) buildMessages(p *target.Payload) ([]kafkago.Message, error) {
+	key, err := target.ExecuteTemplate(t.key, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []kafkago.Message
+	for _, m := range target.NewMessages(p, t.perResource) {
+		value, attributes, err := target.EncodeMessage(p, m, t.cloudEvents, "ce_")
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, kafkago.Message{Key: []byte(key), Value: value, Headers: headers(attributes)})
+	}
+	return msgs, nil
+}
+
+// headers converts message attributes to Kafka headers, sorted by name.
+func headers(attributes map[string]string) []kafkago.Header {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	headers := make([]kafkago.Header, 0, len(keys))
+	for _, key := range keys {
+		headers = append(headers, kafkago.Header{Key: key, Value: []byte(attributes[key])})
+	}
+	return headers
+}
+
+func tlsConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading kafka CA file: %w", err)
+	}
+	cfg.RootCAs = x509.NewCertPool()
+	if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in kafka CA file %s", caFile)
+	}
+	return cfg, nil
+}
+
+func saslMechanism(cfg config.KafkaSASLConfig) (sasl.Mechanism, error) {
+	switch strings.ToLower(cfg.Mechanism) {
+	case SASLPlain:
+		return plain.Mechanism{Username: cfg.Username, Password: cfg.Password}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, cfg.Username, cfg.Password)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, cfg.Username, cfg.Password)
+	default:
+		return nil, fmt.Errorf("invalid SASL mechanism: %s. Mechanism must be plain, scram-sha-256 or scram-sha-512", cfg.Mechanism)
+	}
+}
@@ -0,0 +1,210 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// fakeWriter records published messages in memory.
+type fakeWriter struct {
+	messages []kafkago.Message
+	closed   bool
+	err      error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func newTestTarget(t *testing.T, cfg config.KafkaConfig) (*KafkaTarget, *fakeWriter) {
+	t.Helper()
+
+	cfg.Brokers = []string{"localhost:9092"}
+	cfg.Topic = "infra-changes"
+	tgt, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	w := &fakeWriter{}
+	tgt.newWriter = func() messageWriter { return w }
+	return tgt, w
+}
+
+func headerValue(msg kafkago.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestWrite_Success(t *testing.T) {
+	tgt, w := newTestTarget(t, config.KafkaConfig{})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(w.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(w.messages))
+	}
+	if !w.closed {
+		t.Error("Expected writer to be closed")
+	}
+
+	msg := w.messages[0]
+	if string(msg.Key) != "git@github.com:company/infrastructure.git/main" {
+		t.Errorf("Expected repository URL and branch as key, got %q", msg.Key)
+	}
+
+	var got struct {
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Value, &got); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	if got.Type != target.MessageTypePlan {
+		t.Errorf("Expected plan message, got %q", got.Type)
+	}
+	if len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Expected the full plan, got %d resource changes", len(got.Data.Plan.ResourceChanges))
+	}
+
+	expectedHeaders := map[string]string{
+		"content-type":         "application/json",
+		"infralog-branch":      "main",
+		"infralog-commit-sha":  "abc123def456",
+		"infralog-resources":   "2",
+		"infralog-destructive": "true",
+		"infralog-datetime":    "2025-12-12T10:30:45Z",
+	}
+	for key, value := range expectedHeaders {
+		if got := headerValue(msg, key); got != value {
+			t.Errorf("Expected header %s %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	tgt, w := newTestTarget(t, config.KafkaConfig{
+		Key:                "{{.Git.Branch}}",
+		MessagePerResource: true,
+	})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(w.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(w.messages))
+	}
+
+	for i, expected := range []struct{ address, action string }{
+		{"aws_s3_bucket.data", target.StatusAdded},
+		{"aws_instance.web", target.StatusRemoved},
+	} {
+		msg := w.messages[i]
+		if string(msg.Key) != "main" {
+			t.Errorf("Unexpected key %q", msg.Key)
+		}
+		if headerValue(msg, "infralog-address") != expected.address || headerValue(msg, "infralog-action") != expected.action {
+			t.Errorf("Message %d headers = %v", i, msg.Headers)
+		}
+
+		var got struct {
+			Type string                 `json:"type"`
+			Data target.ResourcePayload `json:"data"`
+		}
+		if err := json.Unmarshal(msg.Value, &got); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		if got.Type != target.MessageTypeResourceChange || got.Data.ResourceChange.Address != expected.address || got.Data.Metadata.Git.Branch != "main" {
+			t.Errorf("Unexpected resource payload: %+v", got)
+		}
+	}
+}
+
+func TestWrite_Error(t *testing.T) {
+	tgt, w := newTestTarget(t, config.KafkaConfig{})
+	w.err = errors.New("leader not available")
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected an error")
+	}
+	if !w.closed {
+		t.Error("Expected writer to be closed after an error")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	valid := config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "infra-changes"}
+
+	tests := []struct {
+		name   string
+		modify func(*config.KafkaConfig)
+	}{
+		{"Missing brokers", func(c *config.KafkaConfig) { c.Brokers = nil }},
+		{"Missing topic", func(c *config.KafkaConfig) { c.Topic = "" }},
+		{"Invalid acks", func(c *config.KafkaConfig) { c.Acks = "two" }},
+		{"Invalid SASL mechanism", func(c *config.KafkaConfig) { c.SASL.Mechanism = "gssapi" }},
+		{"Missing CA file", func(c *config.KafkaConfig) { c.TLS = true; c.CAFile = "/nonexistent/ca.pem" }},
+		{"Invalid key template", func(c *config.KafkaConfig) { c.Key = "{{.Git" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if _, err := New(cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	for _, mechanism := range []string{SASLPlain, SASLScramSHA256, SASLScramSHA512} {
+		cfg := valid
+		cfg.SASL = config.KafkaSASLConfig{Mechanism: mechanism, Username: "infralog", Password: "secret"}
+		if _, err := New(cfg); err != nil {
+			t.Errorf("New() with %s error = %v", mechanism, err)
+		}
+	}
+}
+
+func TestWrite_CloudEventsBinary(t *testing.T) {
+	tgt, w := newTestTarget(t, config.KafkaConfig{CloudEvents: config.CloudEventsConfig{Mode: "binary"}})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	msg := w.messages[0]
+	if headerValue(msg, "ce_type") != target.CloudEventTypePlanChanged || headerValue(msg, "ce_specversion") != "1.0" {
+		t.Errorf("Expected CloudEvents headers, got %v", msg.Headers)
+	}
+	if headerValue(msg, "ce_source") != "ssh://git@github.com/company/infrastructure.git" {
+		t.Errorf("Unexpected ce_source %q", headerValue(msg, "ce_source"))
+	}
+
+	var got target.Payload
+	if err := json.Unmarshal(msg.Value, &got); err != nil || len(got.Plan.ResourceChanges) != 2 {
+		t.Errorf("Expected the payload as message value, got %s", msg.Value)
+	}
+}
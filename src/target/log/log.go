@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Outputs.
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+)
+
+type LogTarget struct {
+	out io.Writer
+}
+
+func New(cfg config.LogConfig) (*LogTarget, error) {
+	switch strings.ToLower(cfg.Output) {
+	case OutputStdout:
+		return &LogTarget{out: os.Stdout}, nil
+	case OutputStderr:
+		return &LogTarget{out: os.Stderr}, nil
+	default:
+		return nil, fmt.Errorf("invalid output: %s. Output must be stdout or stderr", cfg.Output)
+	}
+}
+
+// Write writes one JSON line per resource change followed by a summary line.
+func (t *LogTarget) Write(p *target.Payload) error {
+	var b bytes.Buffer
+	for _, r := range target.NewLogRecords(p) {
+		line, err := json.Marshal(entry(r))
+		if err != nil {
+			return fmt.Errorf("error marshaling log record: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	// Write all lines at once so they are not interleaved with other output.
+	if _, err := t.out.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("error writing log records: %w", err)
+	}
+	return nil
+}
+
+// entry returns the JSON object for a record: the time, level, message and
+// event, followed by the record's fields.
+func entry(r target.LogRecord) map[string]string {
+	e := map[string]string{
+		"time":  r.Datetime.UTC().Format(time.RFC3339),
+		"level": r.Severity,
+		"msg":   r.Message,
+		"event": r.Event,
+	}
+	for _, f := range r.Fields {
+		e[f.Name] = f.Value
+	}
+	return e
+}
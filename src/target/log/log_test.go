@@ -0,0 +1,80 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var out bytes.Buffer
+	tgt := &LogTarget{out: &out}
+
+	payload := &target.Payload{
+		Plan: &tfplan.Plan{
+			ResourceChanges: []tfplan.ResourceChange{
+				{Address: "aws_s3_bucket.data", Type: "aws_s3_bucket", Change: tfplan.Change{Actions: []string{"create"}}},
+				{Address: "aws_instance.web", Type: "aws_instance", Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+			},
+		},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &target.PayloadMetadata{
+			Git: &git.Metadata{Branch: "main", CommitSHA: "abc123def456"},
+		},
+	}
+
+	if err := tgt.Write(payload); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d:\n%s", len(lines), out.String())
+	}
+
+	var replaced map[string]string
+	if err := json.Unmarshal([]byte(lines[1]), &replaced); err != nil {
+		t.Fatalf("Failed to unmarshal line: %v", err)
+	}
+	expected := map[string]string{
+		"time":    "2025-12-12T10:30:45Z",
+		"level":   "warning",
+		"msg":     "aws_instance.web replaced",
+		"event":   "resource_change",
+		"address": "aws_instance.web",
+		"type":    "aws_instance",
+		"action":  "replaced",
+		"branch":  "main",
+		"sha":     "abc123def456",
+	}
+	for key, value := range expected {
+		if replaced[key] != value {
+			t.Errorf("Expected %s %q, got %q", key, value, replaced[key])
+		}
+	}
+
+	var summary map[string]string
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatalf("Failed to unmarshal line: %v", err)
+	}
+	if summary["event"] != "summary" || summary["resources"] != "2" || summary["replaced"] != "1" {
+		t.Errorf("Unexpected summary line: %v", summary)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, output := range []string{"stdout", "stderr", "STDERR"} {
+		if _, err := New(config.LogConfig{Output: output}); err != nil {
+			t.Errorf("New(%q) error = %v", output, err)
+		}
+	}
+	if _, err := New(config.LogConfig{Output: "/var/log/infralog.log"}); err == nil {
+		t.Error("Expected an error for an invalid output")
+	}
+}
@@ -0,0 +1,134 @@
+package target
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Log record events.
+const (
+	LogEventResourceChange = "resource_change"
+	LogEventSummary        = "summary"
+)
+
+// Log record severities. Records of changes that remove or replace resources
+// are warnings.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+)
+
+// LogField is a named value of a log record.
+type LogField struct {
+	Name  string
+	Value string
+}
+
+// LogRecord is a flat description of a resource change or of a whole plan, for
+// targets that feed logging and SIEM pipelines.
+type LogRecord struct {
+	Event    string
+	Severity string
+	Datetime time.Time
+	Message  string
+	Fields   []LogField // In a stable order; empty values are omitted
+}
+
+// NewLogRecords returns one record per resource change, in plan order,
+// followed by a summary record for the plan.
+func NewLogRecords(p *Payload) []LogRecord {
+	common := gitLogFields(p)
+
+	records := make([]LogRecord, 0, len(p.Plan.ResourceChanges)+1)
+	for _, rp := range SplitByResource(p) {
+		rc := rp.ResourceChange
+		address := ResourceAddress(rc)
+
+		severity := SeverityInfo
+		if rp.Action == StatusRemoved || rp.Action == StatusReplaced {
+			severity = SeverityWarning
+		}
+
+		fields := appendLogFields(nil,
+			LogField{"address", address},
+			LogField{"type", rc.Type},
+			LogField{"action", rp.Action},
+			LogField{"module", rc.ModuleAddress},
+		)
+		records = append(records, LogRecord{
+			Event:    LogEventResourceChange,
+			Severity: severity,
+			Datetime: p.Datetime,
+			Message:  fmt.Sprintf("%s %s", address, rp.Action),
+			Fields:   append(fields, common...),
+		})
+	}
+
+	s := Summarize(p.Plan)
+	severity := SeverityInfo
+	if s.HasDestructiveChanges() {
+		severity = SeverityWarning
+	}
+	fields := appendLogFields(nil,
+		LogField{"resources", strconv.Itoa(s.Resources)},
+		LogField{"added", strconv.Itoa(s.Added)},
+		LogField{"changed", strconv.Itoa(s.Changed)},
+		LogField{"replaced", strconv.Itoa(s.Replaced)},
+		LogField{"removed", strconv.Itoa(s.Removed)},
+		LogField{"outputs", strconv.Itoa(s.Outputs)},
+	)
+	records = append(records, LogRecord{
+		Event:    LogEventSummary,
+		Severity: severity,
+		Datetime: p.Datetime,
+		Message: fmt.Sprintf("Terraform plan: %d resource(s) changed (%d added, %d changed, %d replaced, %d removed), %d output(s) changed",
+			s.Resources, s.Added, s.Changed, s.Replaced, s.Removed, s.Outputs),
+		Fields: append(fields, common...),
+	})
+
+	return records
+}
+
+// ResourceDocuments returns one flat document per resource change, in plan
+// order, for targets that index changes for search. Documents hold the
+// fields of the resource change records, the time of the run as timestamp
+// and the message.
+func ResourceDocuments(p *Payload) []map[string]string {
+	var documents []map[string]string
+	for _, r := range NewLogRecords(p) {
+		if r.Event != LogEventResourceChange {
+			continue
+		}
+		document := make(map[string]string, len(r.Fields)+2)
+		for _, f := range r.Fields {
+			document[f.Name] = f.Value
+		}
+		document["timestamp"] = r.Datetime.UTC().Format(time.RFC3339)
+		document["message"] = r.Message
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+func gitLogFields(p *Payload) []LogField {
+	if p.Metadata == nil || p.Metadata.Git == nil {
+		return nil
+	}
+	g := p.Metadata.Git
+	return appendLogFields(nil,
+		LogField{"branch", g.Branch},
+		LogField{"sha", g.CommitSHA},
+		LogField{"committer", g.Committer},
+		LogField{"repo", g.RepoURL},
+	)
+}
+
+func appendLogFields(fields []LogField, add ...LogField) []LogField {
+	for _, f := range add {
+		if f.Value != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
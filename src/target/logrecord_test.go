@@ -0,0 +1,91 @@
+package target
+
+import (
+	"testing"
+)
+
+func logFieldMap(fields []LogField) map[string]string {
+	m := make(map[string]string, len(fields))
+	for _, f := range fields {
+		m[f.Name] = f.Value
+	}
+	return m
+}
+
+func TestNewLogRecords(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.Plan.ResourceChanges[2].Type = "aws_security_group"
+	payload.Plan.ResourceChanges[2].ModuleAddress = "module.network"
+
+	records := NewLogRecords(payload)
+	if len(records) != 4 {
+		t.Fatalf("Expected 3 resource records and a summary, got %d", len(records))
+	}
+
+	removed := records[2]
+	if removed.Event != LogEventResourceChange || removed.Severity != SeverityWarning || removed.Message != "aws_security_group.old removed" {
+		t.Errorf("Unexpected resource record: %+v", removed)
+	}
+	expected := []LogField{
+		{"address", "aws_security_group.old"},
+		{"type", "aws_security_group"},
+		{"action", "removed"},
+		{"module", "module.network"},
+		{"branch", "main"},
+		{"sha", "abc123def456"},
+	}
+	if len(removed.Fields) != len(expected) {
+		t.Fatalf("Expected fields %v, got %v", expected, removed.Fields)
+	}
+	for i, f := range expected {
+		if removed.Fields[i] != f {
+			t.Errorf("Field %d: expected %v, got %v", i, f, removed.Fields[i])
+		}
+	}
+
+	if records[0].Severity != SeverityInfo {
+		t.Errorf("Expected added resources to be info, got %s", records[0].Severity)
+	}
+	if _, ok := logFieldMap(records[0].Fields)["module"]; ok {
+		t.Error("Expected empty module to be omitted")
+	}
+
+	summary := records[3]
+	if summary.Event != LogEventSummary || summary.Severity != SeverityWarning || !summary.Datetime.Equal(payload.Datetime) {
+		t.Errorf("Unexpected summary record: %+v", summary)
+	}
+	fields := logFieldMap(summary.Fields)
+	for name, value := range map[string]string{"resources": "3", "added": "1", "replaced": "0", "removed": "1", "outputs": "1", "branch": "main"} {
+		if fields[name] != value {
+			t.Errorf("Expected summary field %s %q, got %q", name, value, fields[name])
+		}
+	}
+	if summary.Message != "Terraform plan: 3 resource(s) changed (1 added, 1 changed, 0 replaced, 1 removed), 1 output(s) changed" {
+		t.Errorf("Unexpected summary message %q", summary.Message)
+	}
+}
+
+func TestResourceDocuments(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.Metadata.Git.Committer = "Jane Doe"
+
+	documents := ResourceDocuments(payload)
+	if len(documents) != 3 {
+		t.Fatalf("Expected a document per resource change, got %d", len(documents))
+	}
+
+	expected := map[string]string{
+		"address":   "aws_security_group.old",
+		"action":    "removed",
+		"branch":    "main",
+		"sha":       "abc123def456",
+		"committer": "Jane Doe",
+		"timestamp": "2025-12-12T10:30:45Z",
+		"message":   "aws_security_group.old removed",
+	}
+	for key, value := range expected {
+		if documents[2][key] != value {
+			t.Errorf("Expected %s %q, got %q", key, value, documents[2][key])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pushPath = "/loki/api/v1/push"
+
+	defaultJob = "infralog"
+
+	requestTimeout = 30 * time.Second
+)
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // Unix time in nanoseconds and log line
+}
+
+type LokiTarget struct {
+	url      string
+	username string
+	password string
+	tenantID string
+	labels   map[string]string
+	client   *http.Client
+}
+
+func New(cfg config.LokiConfig) (*LokiTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("loki URL is required")
+	}
+
+	labels := map[string]string{"job": defaultJob}
+	for name, value := range cfg.Labels {
+		if name == "action" {
+			return nil, fmt.Errorf("loki label action is reserved")
+		}
+		labels[name] = value
+	}
+
+	url := strings.TrimSuffix(cfg.URL, "/")
+	if !strings.HasSuffix(url, pushPath) {
+		url += pushPath
+	}
+
+	return &LokiTarget{
+		url:      url,
+		username: cfg.Username,
+		password: cfg.Password,
+		tenantID: cfg.TenantID,
+		labels:   labels,
+		client:   &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write pushes one log line per resource change, in one stream per action.
+func (t *LokiTarget) Write(p *target.Payload) error {
+	streams, err := t.buildStreams(p)
+	if err != nil {
+		return err
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(map[string][]stream{"streams": streams})
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	if t.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", t.tenantID)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Loki explains rejected pushes, e.g. out of order entries, in the body
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("loki returned status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// buildStreams groups the resource change documents into streams labeled
+// with the action, so that queries can select deletions without parsing
+// lines. The remaining fields are in the JSON log line.
+func (t *LokiTarget) buildStreams(p *target.Payload) ([]stream, error) {
+	byAction := map[string]*stream{}
+	var actions []string
+
+	// Lines with the same timestamp are kept if their content differs, but
+	// offset them by a nanosecond to preserve plan order.
+	ts := p.Datetime.UnixNano()
+	for i, document := range target.ResourceDocuments(p) {
+		line, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling log line: %w", err)
+		}
+
+		action := document["action"]
+		s, ok := byAction[action]
+		if !ok {
+			labels := make(map[string]string, len(t.labels)+1)
+			for name, value := range t.labels {
+				labels[name] = value
+			}
+			labels["action"] = action
+			s = &stream{Stream: labels}
+			byAction[action] = s
+			actions = append(actions, action)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(ts+int64(i), 10), string(line)})
+	}
+
+	sort.Strings(actions)
+	streams := make([]stream, 0, len(actions))
+	for _, action := range actions {
+		streams = append(streams, *byAction[action])
+	}
+	return streams, nil
+}
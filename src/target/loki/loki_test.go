@@ -0,0 +1,106 @@
+package loki
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var req struct{ Streams []stream }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Scope-OrgID") != "platform" {
+			t.Errorf("Unexpected tenant %q", r.Header.Get("X-Scope-OrgID"))
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "123456" || password != "glc_token" {
+			t.Errorf("Expected basic auth, got %q %q", user, password)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.LokiConfig{
+		URL:      server.URL,
+		Username: "123456",
+		Password: "glc_token",
+		TenantID: "platform",
+		Labels:   map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(
+		targettest.Change("aws_s3_bucket.data", "create"),
+		targettest.Change("aws_instance.web", "delete"),
+		targettest.Change("aws_instance.db", "delete"),
+	)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(req.Streams) != 2 {
+		t.Fatalf("Expected a stream per action, got %d", len(req.Streams))
+	}
+
+	removed := req.Streams[1]
+	expectedLabels := map[string]string{"job": "infralog", "env": "prod", "action": "removed"}
+	for name, value := range expectedLabels {
+		if removed.Stream[name] != value {
+			t.Errorf("Expected label %s %q, got %q", name, value, removed.Stream[name])
+		}
+	}
+	if len(removed.Values) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(removed.Values))
+	}
+	if removed.Values[0][0] != "1765535445000000001" || removed.Values[1][0] != "1765535445000000002" {
+		t.Errorf("Expected timestamps in plan order, got %s and %s", removed.Values[0][0], removed.Values[1][0])
+	}
+
+	var line map[string]string
+	if err := json.Unmarshal([]byte(removed.Values[1][1]), &line); err != nil {
+		t.Fatalf("Failed to decode line: %v", err)
+	}
+	if line["address"] != "aws_instance.db" || line["branch"] != "main" || line["timestamp"] != "2025-12-12T10:30:45Z" {
+		t.Errorf("Unexpected line: %v", line)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("entry out of order"))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.LokiConfig{URL: server.URL + "/loki/api/v1/push"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(
+		targettest.Change("aws_s3_bucket.data", "create"),
+		targettest.Change("aws_instance.web", "delete"),
+		targettest.Change("aws_instance.db", "delete"),
+	))
+	if err == nil || !strings.Contains(err.Error(), "out of order") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(config.LokiConfig{}); err == nil {
+		t.Error("Expected an error for a missing URL")
+	}
+	if _, err := New(config.LokiConfig{URL: "http://localhost:3100", Labels: map[string]string{"action": "x"}}); err == nil {
+		t.Error("Expected an error for a reserved label")
+	}
+}
@@ -0,0 +1,183 @@
+package target
+
+import (
+	"fmt"
+	"infralog/tfplan"
+	"sort"
+	"strings"
+)
+
+// collapseThreshold is the number of diff lines above which a resource's
+// attribute diff is collapsed into a <details> block.
+const collapseThreshold = 10
+
+// MarkdownOptions controls how a plan is rendered as a code review comment.
+type MarkdownOptions struct {
+	// Marker is a hidden comment placed at the top of the body, used to find
+	// and update a previously posted comment.
+	Marker string
+	// Collapsible enables <details> blocks for large attribute diffs. Only set
+	// it for platforms that render HTML in markdown.
+	Collapsible bool
+	// MaxLength is the maximum body length accepted by the platform, or 0 for
+	// no limit. Attribute diffs are dropped, then the body is truncated, to fit.
+	MaxLength int
+}
+
+// RenderMarkdown renders the payload as a markdown comment for pull and merge
+// requests.
+func RenderMarkdown(p *Payload, opts MarkdownOptions) string {
+	body := renderMarkdown(p, opts, true)
+	if opts.MaxLength <= 0 || len(body) <= opts.MaxLength {
+		return body
+	}
+
+	body = renderMarkdown(p, opts, false)
+	if len(body) <= opts.MaxLength {
+		return body
+	}
+
+	note := "\n\n_Comment truncated: the plan is too large to display in full._\n"
+	cut := strings.LastIndex(body[:opts.MaxLength-len(note)], "\n")
+	if cut < 0 {
+		cut = opts.MaxLength - len(note)
+	}
+	return body[:cut] + note
+}
+
+func renderMarkdown(p *Payload, opts MarkdownOptions, withDiffs bool) string {
+	var sb strings.Builder
+
+	if opts.Marker != "" {
+		sb.WriteString(opts.Marker + "\n")
+	}
+	sb.WriteString("### Terraform Plan Changes\n\n")
+	if !p.Plan.HasChanges() {
+		sb.WriteString("No changes. The latest plan doesn't change any resources or outputs.\n")
+	} else {
+		sb.WriteString(markdownSummary(Summarize(p.Plan)) + "\n")
+	}
+
+	if len(p.Plan.ResourceChanges) > 0 {
+		sb.WriteString("\n| | Resource | Action |\n|---|---|---|\n")
+		groups := GroupByStatus(p.Plan.ResourceChanges)
+		for _, status := range StatusOrder {
+			for _, rc := range groups[status] {
+				sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", statusEmoji(status), ResourceAddress(rc), status))
+			}
+		}
+
+		if withDiffs {
+			for _, status := range StatusOrder {
+				if status != StatusChanged && status != StatusReplaced {
+					continue
+				}
+				for _, rc := range groups[status] {
+					writeResourceDiff(&sb, ResourceAddress(rc), rc.Change, opts.Collapsible)
+				}
+			}
+		} else {
+			sb.WriteString("\n_Attribute diffs omitted: the plan is too large to display in full._\n")
+		}
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		names := make([]string, 0, len(p.Plan.OutputChanges))
+		for name := range p.Plan.OutputChanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("\n| | Output | Action |\n|---|---|---|\n")
+		for _, name := range names {
+			status := ActionsToStatus(p.Plan.OutputChanges[name].Change.Actions)
+			sb.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", statusEmoji(status), name, status))
+		}
+	}
+
+	sb.WriteString("\n" + markdownFooter(p) + "\n")
+	return sb.String()
+}
+
+// writeResourceDiff writes the changed attributes of a resource as a diff
+// code block, collapsed when it exceeds collapseThreshold lines.
+func writeResourceDiff(sb *strings.Builder, address string, change tfplan.Change, collapsible bool) {
+	changes := ExtractChanges(change)
+	if len(changes) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, attr := range SortedAttributes(changes) {
+		lines = append(lines,
+			fmt.Sprintf("- %s: %s", attr, FormatValue(changes[attr].Before)),
+			fmt.Sprintf("+ %s: %s", attr, FormatValue(changes[attr].After)),
+		)
+	}
+	block := "```diff\n" + strings.Join(lines, "\n") + "\n```\n"
+
+	if collapsible && len(lines) > collapseThreshold {
+		sb.WriteString(fmt.Sprintf("\n<details><summary><code>%s</code> (%d attributes changed)</summary>\n\n", address, len(changes)))
+		sb.WriteString(block)
+		sb.WriteString("\n</details>\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\n**`%s`**\n\n", address))
+	sb.WriteString(block)
+}
+
+func markdownSummary(s Summary) string {
+	var counts []string
+	for _, c := range []struct {
+		n     int
+		label string
+	}{
+		{s.Added, StatusAdded},
+		{s.Changed, StatusChanged},
+		{s.Replaced, StatusReplaced},
+		{s.Removed, StatusRemoved},
+	} {
+		if c.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.n, c.label))
+		}
+	}
+
+	summary := fmt.Sprintf("**%d resource(s)**", s.Resources)
+	if len(counts) > 0 {
+		summary += ": " + strings.Join(counts, ", ")
+	}
+	if s.Outputs > 0 {
+		summary += fmt.Sprintf(" · **%d output(s)**", s.Outputs)
+	}
+	return summary
+}
+
+func markdownFooter(p *Payload) string {
+	var parts []string
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		if p.Metadata.Git.Branch != "" {
+			parts = append(parts, fmt.Sprintf("Branch `%s`", p.Metadata.Git.Branch))
+		}
+		if sha := ShortSHA(p.Metadata.Git.CommitSHA); sha != "" {
+			parts = append(parts, fmt.Sprintf("Commit `%s`", sha))
+		}
+	}
+	parts = append(parts, "Generated by Infralog at "+p.Datetime.Format("2006-01-02 15:04:05 UTC"))
+	return "_" + strings.Join(parts, " · ") + "_"
+}
+
+func statusEmoji(status string) string {
+	switch status {
+	case StatusAdded:
+		return "🟢"
+	case StatusChanged:
+		return "🟡"
+	case StatusReplaced:
+		return "🟠"
+	case StatusRemoved:
+		return "🔴"
+	default:
+		return "⚪"
+	}
+}
@@ -0,0 +1,112 @@
+package target
+
+import (
+	"fmt"
+	"infralog/git"
+	"infralog/tfplan"
+	"strings"
+	"testing"
+	"time"
+)
+
+func markdownTestPayload(updatedAttributes int) *Payload {
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	for i := 0; i < updatedAttributes; i++ {
+		before[fmt.Sprintf("tag_%02d", i)] = "old"
+		after[fmt.Sprintf("tag_%02d", i)] = "new"
+	}
+
+	return &Payload{
+		Plan: &tfplan.Plan{
+			ResourceChanges: []tfplan.ResourceChange{
+				{Address: "aws_s3_bucket.data", Change: tfplan.Change{Actions: []string{"create"}}},
+				{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"update"}, Before: before, After: after}},
+				{Address: "aws_security_group.old", Change: tfplan.Change{Actions: []string{"delete"}}},
+			},
+			OutputChanges: map[string]tfplan.OutputChange{
+				"instance_ip": {Change: tfplan.Change{Actions: []string{"update"}}},
+			},
+		},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &PayloadMetadata{
+			Git: &git.Metadata{Branch: "main", CommitSHA: "abc123def456"},
+		},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	body := RenderMarkdown(markdownTestPayload(1), MarkdownOptions{Marker: CommentMarker(""), Collapsible: true})
+
+	expected := []string{
+		"<!-- infralog -->\n",
+		"**3 resource(s)**: 1 added, 1 changed, 1 removed · **1 output(s)**",
+		"| 🟢 | `aws_s3_bucket.data` | added |",
+		"| 🔴 | `aws_security_group.old` | removed |",
+		"| 🟡 | `instance_ip` | changed |",
+		"```diff\n- tag_00: old\n+ tag_00: new\n```",
+		"Branch `main` · Commit `abc123de`",
+	}
+	for _, s := range expected {
+		if !strings.Contains(body, s) {
+			t.Errorf("Expected body to contain %q, got:\n%s", s, body)
+		}
+	}
+	if !strings.HasPrefix(body, "<!-- infralog -->") {
+		t.Error("Expected marker at the start of the body")
+	}
+	if strings.Contains(body, "<details>") {
+		t.Error("Expected small diffs not to be collapsed")
+	}
+}
+
+func TestRenderMarkdown_NoChanges(t *testing.T) {
+	body := RenderMarkdown(NewPayload(&tfplan.Plan{}), MarkdownOptions{Marker: CommentMarker("")})
+
+	if !strings.Contains(body, "No changes.") {
+		t.Errorf("Expected a no changes note, got:\n%s", body)
+	}
+	if strings.Contains(body, "resource(s)") {
+		t.Errorf("Expected no resource counts, got:\n%s", body)
+	}
+}
+
+func TestRenderMarkdown_CollapsesLargeDiffs(t *testing.T) {
+	payload := markdownTestPayload(8)
+
+	body := RenderMarkdown(payload, MarkdownOptions{Collapsible: true})
+	if !strings.Contains(body, "<details><summary><code>aws_instance.web</code> (8 attributes changed)</summary>") {
+		t.Errorf("Expected large diff to be collapsed, got:\n%s", body)
+	}
+
+	body = RenderMarkdown(payload, MarkdownOptions{})
+	if strings.Contains(body, "<details>") {
+		t.Error("Expected no <details> blocks when collapsing is disabled")
+	}
+}
+
+func TestRenderMarkdown_MaxLength(t *testing.T) {
+	payload := markdownTestPayload(200)
+
+	full := RenderMarkdown(payload, MarkdownOptions{})
+	limit := len(full) / 2
+
+	body := RenderMarkdown(payload, MarkdownOptions{MaxLength: limit})
+	if len(body) > limit {
+		t.Errorf("Expected body of at most %d characters, got %d", limit, len(body))
+	}
+	if !strings.Contains(body, "Attribute diffs omitted") {
+		t.Errorf("Expected diffs to be omitted, got:\n%s", body)
+	}
+	if !strings.Contains(body, "`aws_security_group.old`") {
+		t.Error("Expected resource table to be kept")
+	}
+
+	body = RenderMarkdown(payload, MarkdownOptions{MaxLength: 200})
+	if len(body) > 200 {
+		t.Errorf("Expected body of at most 200 characters, got %d", len(body))
+	}
+	if !strings.Contains(body, "Comment truncated") {
+		t.Errorf("Expected truncation note, got:\n%s", body)
+	}
+}
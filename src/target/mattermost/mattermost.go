@@ -0,0 +1,209 @@
+package mattermost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Maximum number of changed attributes listed per resource.
+const maxAttributes = 5
+
+type MattermostTarget struct {
+	webhookURL string
+	channel    string
+	username   string
+	iconURL    string
+	iconEmoji  string
+}
+
+type mattermostMessage struct {
+	Channel     string       `json:"channel,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	IconURL     string       `json:"icon_url,omitempty"`
+	IconEmoji   string       `json:"icon_emoji,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	Fallback string  `json:"fallback"`
+	Color    string  `json:"color,omitempty"`
+	Title    string  `json:"title,omitempty"`
+	Text     string  `json:"text,omitempty"`
+	Fields   []field `json:"fields,omitempty"`
+	Footer   string  `json:"footer,omitempty"`
+}
+
+type field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func New(cfg config.MattermostConfig) (*MattermostTarget, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("mattermost webhook URL is required")
+	}
+
+	return &MattermostTarget{
+		webhookURL: cfg.WebhookURL,
+		channel:    cfg.Channel,
+		username:   cfg.Username,
+		iconURL:    cfg.IconURL,
+		iconEmoji:  cfg.IconEmoji,
+	}, nil
+}
+
+func (t *MattermostTarget) Write(p *target.Payload) error {
+	msg := t.buildMessage(p)
+
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshaling mattermost message: %w", err)
+	}
+
+	resp, err := http.Post(t.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending mattermost message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("mattermost request failed with status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (t *MattermostTarget) buildMessage(p *target.Payload) mattermostMessage {
+	var fields []field
+
+	// Git metadata as short fields
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		if git.Committer != "" {
+			fields = append(fields, field{Title: "Committer", Value: git.Committer, Short: true})
+		}
+		if git.Branch != "" {
+			fields = append(fields, field{Title: "Branch", Value: fmt.Sprintf("`%s`", git.Branch), Short: true})
+		}
+		if git.CommitSHA != "" {
+			fields = append(fields, field{Title: "Commit", Value: fmt.Sprintf("`%s`", target.ShortSHA(git.CommitSHA)), Short: true})
+		}
+		if git.RepoURL != "" {
+			fields = append(fields, field{Title: "Repository", Value: git.RepoURL, Short: false})
+		}
+	}
+
+	var sections []string
+	if len(p.Plan.ResourceChanges) > 0 {
+		sections = append(sections, formatResourceChanges(p.Plan.ResourceChanges))
+	}
+	if len(p.Plan.OutputChanges) > 0 {
+		sections = append(sections, formatOutputChanges(p.Plan.OutputChanges))
+	}
+
+	fallback := target.Summarize(p.Plan).Text()
+
+	return mattermostMessage{
+		Channel:   t.channel,
+		Username:  t.username,
+		IconURL:   t.iconURL,
+		IconEmoji: t.iconEmoji,
+		Attachments: []attachment{
+			{
+				Fallback: fallback,
+				Color:    planColor(p.Plan),
+				Title:    "Terraform Plan Changes",
+				Text:     strings.Join(sections, "\n"),
+				Fields:   fields,
+				Footer:   p.Datetime.Format("2006-01-02 15:04:05 UTC"),
+			},
+		},
+	}
+}
+
+func formatResourceChanges(changes []tfplan.ResourceChange) string {
+	var sb strings.Builder
+	sb.WriteString("#### Resource Changes\n\n")
+
+	for _, rc := range changes {
+		status := target.ActionsToStatus(rc.Change.Actions)
+		sb.WriteString(fmt.Sprintf("%s `%s` - %s\n",
+			statusEmoji(status), target.ResourceAddress(rc), status))
+
+		// Show changed attributes for updates
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			attrChanges := target.ExtractChanges(rc.Change)
+			for i, attr := range target.SortedAttributes(attrChanges) {
+				if i >= maxAttributes {
+					sb.WriteString(fmt.Sprintf("    - _...and %d more attributes_\n", len(attrChanges)-maxAttributes))
+					break
+				}
+				change := attrChanges[attr]
+				sb.WriteString(fmt.Sprintf("    - `%s`: `%v` → `%v`\n", attr, change.Before, change.After))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func formatOutputChanges(changes map[string]tfplan.OutputChange) string {
+	var sb strings.Builder
+	sb.WriteString("#### Output Changes\n\n")
+
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oc := changes[name]
+		status := target.ActionsToStatus(oc.Change.Actions)
+		sb.WriteString(fmt.Sprintf("%s `%s` - %s\n", statusEmoji(status), name, status))
+
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			sb.WriteString(fmt.Sprintf("    - `%v` → `%v`\n", oc.Change.Before, oc.Change.After))
+		}
+	}
+
+	return sb.String()
+}
+
+// planColor returns the attachment color for the most severe change in the plan.
+func planColor(plan *tfplan.Plan) string {
+	summary := target.Summarize(plan)
+	switch {
+	case summary.HasDestructiveChanges():
+		return "#d24b4e"
+	case summary.Changed > 0:
+		return "#ffbc1f"
+	case summary.Added > 0:
+		return "#06d6a0"
+	default:
+		return "#8a8a8a"
+	}
+}
+
+func statusEmoji(status string) string {
+	switch status {
+	case target.StatusAdded:
+		return ":large_green_circle:"
+	case target.StatusRemoved:
+		return ":red_circle:"
+	case target.StatusChanged, target.StatusReplaced:
+		return ":large_yellow_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
@@ -0,0 +1,162 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.MattermostConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with webhook URL",
+			cfg:         config.MattermostConfig{WebhookURL: "https://mattermost.example.com/hooks/xxx"},
+			expectError: false,
+		},
+		{
+			name:        "Empty webhook URL",
+			cfg:         config.MattermostConfig{WebhookURL: ""},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_WithOptionalFields(t *testing.T) {
+	var receivedBody mattermostMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected application/json content type, got %s", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mattermostTarget, err := New(config.MattermostConfig{
+		WebhookURL: server.URL,
+		Channel:    "infra",
+		Username:   "Infralog Bot",
+		IconURL:    "https://example.com/icon.png",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create mattermost target: %v", err)
+	}
+
+	payload := &target.Payload{
+		Plan: &tfplan.Plan{
+			ResourceChanges: []tfplan.ResourceChange{
+				{
+					Type: "aws_instance",
+					Name: "web",
+					Change: tfplan.Change{
+						Actions: []string{"update"},
+						Before:  map[string]interface{}{"instance_type": "t2.micro"},
+						After:   map[string]interface{}{"instance_type": "t2.small"},
+					},
+				},
+			},
+		},
+		Datetime: time.Now().UTC(),
+		Metadata: &target.PayloadMetadata{
+			Git: &git.Metadata{Branch: "main", CommitSHA: "abc123def456789"},
+		},
+	}
+	if err := mattermostTarget.Write(payload); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	if receivedBody.Channel != "infra" {
+		t.Errorf("Expected channel infra, got %s", receivedBody.Channel)
+	}
+	if receivedBody.Username != "Infralog Bot" {
+		t.Errorf("Expected username Infralog Bot, got %s", receivedBody.Username)
+	}
+	if receivedBody.IconURL != "https://example.com/icon.png" {
+		t.Errorf("Expected icon_url to be set, got %s", receivedBody.IconURL)
+	}
+	if len(receivedBody.Attachments) != 1 {
+		t.Fatalf("Expected 1 attachment, got %d", len(receivedBody.Attachments))
+	}
+
+	att := receivedBody.Attachments[0]
+	if att.Fallback == "" {
+		t.Error("Expected non-empty fallback text")
+	}
+	if !strings.Contains(att.Text, "#### Resource Changes") || !strings.Contains(att.Text, "`instance_type`") {
+		t.Errorf("Expected markdown resource changes, got %q", att.Text)
+	}
+	if len(att.Fields) != 2 || att.Fields[1].Value != "`abc123de`" {
+		t.Errorf("Expected branch and short commit fields, got %+v", att.Fields)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mattermostTarget, err := New(config.MattermostConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create mattermost target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{})
+	if err := mattermostTarget.Write(payload); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestPlanColor(t *testing.T) {
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+	}
+	if got := planColor(plan); got != "#06d6a0" {
+		t.Errorf("Expected green for creates, got %s", got)
+	}
+
+	plan.ResourceChanges = append(plan.ResourceChanges, tfplan.ResourceChange{
+		Change: tfplan.Change{Actions: []string{"delete"}},
+	})
+	if got := planColor(plan); got != "#d24b4e" {
+		t.Errorf("Expected red for deletes, got %s", got)
+	}
+}
@@ -0,0 +1,162 @@
+package target
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MessageVersion is the version of the message envelope format.
+const MessageVersion = "1"
+
+// Message types.
+const (
+	MessageTypePlan           = "infralog.plan"
+	MessageTypeResourceChange = "infralog.resource_change"
+)
+
+// Message is the envelope published by message-bus targets. Every transport
+// publishes the same JSON body and attributes, so consumers can switch
+// transports without changing how they parse messages.
+type Message struct {
+	Version  string      `json:"version"`
+	ID       string      `json:"id"`
+	Type     string      `json:"type"`
+	Source   string      `json:"source,omitempty"` // Repository URL, when available
+	Datetime time.Time   `json:"datetime"`
+	Data     interface{} `json:"data"` // *Payload or ResourcePayload, depending on Type
+
+	// Attributes describe the message for routing and filtering without
+	// decoding the body. Transports publish them as headers or attributes.
+	Attributes map[string]string `json:"-"`
+}
+
+// NewMessages wraps the payload in a single plan message, or in one resource
+// change message per resource when perResource is set.
+func NewMessages(p *Payload, perResource bool) []Message {
+	attributes := messageAttributes(p)
+
+	if !perResource {
+		return []Message{newMessage(p, MessageTypePlan, p, attributes)}
+	}
+
+	resources := SplitByResource(p)
+	messages := make([]Message, 0, len(resources))
+	for _, rp := range resources {
+		resourceAttributes := make(map[string]string, len(attributes)+2)
+		for k, v := range attributes {
+			resourceAttributes[k] = v
+		}
+		resourceAttributes["infralog-address"] = ResourceAddress(rp.ResourceChange)
+		resourceAttributes["infralog-action"] = rp.Action
+
+		messages = append(messages, newMessage(p, MessageTypeResourceChange, rp, resourceAttributes))
+	}
+	return messages
+}
+
+// Body returns the JSON encoding of the message.
+func (m Message) Body() ([]byte, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling message: %w", err)
+	}
+	return body, nil
+}
+
+// CountAttributes returns the number of resources per action in the plan as
+// message attributes, for transports that filter on attribute values.
+func CountAttributes(p *Payload) map[string]string {
+	s := Summarize(p.Plan)
+	return map[string]string{
+		"infralog-added":    strconv.Itoa(s.Added),
+		"infralog-changed":  strconv.Itoa(s.Changed),
+		"infralog-replaced": strconv.Itoa(s.Replaced),
+		"infralog-removed":  strconv.Itoa(s.Removed),
+	}
+}
+
+// Batch groups items into batches of at most maxEntries items, or any number
+// if maxEntries is 0, with a total size of at most maxSize, for transports
+// that publish several messages per request. An item larger than maxSize is
+// put in a batch of its own; callers reject such items beforehand.
+func Batch[T any](items []T, maxEntries, maxSize int, size func(T) int) [][]T {
+	var batches [][]T
+	var batch []T
+	batchSize := 0
+
+	for _, item := range items {
+		n := size(item)
+		if (maxEntries > 0 && len(batch) == maxEntries) || (len(batch) > 0 && batchSize+n > maxSize) {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, item)
+		batchSize += n
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func newMessage(p *Payload, messageType string, data interface{}, attributes map[string]string) Message {
+	m := Message{
+		Version:    MessageVersion,
+		ID:         NewID(),
+		Type:       messageType,
+		Datetime:   p.Datetime,
+		Data:       data,
+		Attributes: attributes,
+	}
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		m.Source = p.Metadata.Git.RepoURL
+	}
+	m.Attributes["infralog-type"] = messageType
+	m.Attributes["infralog-id"] = m.ID
+	return m
+}
+
+func messageAttributes(p *Payload) map[string]string {
+	s := Summarize(p.Plan)
+	attributes := map[string]string{
+		"content-type":         "application/json",
+		"infralog-datetime":    p.Datetime.UTC().Format(time.RFC3339),
+		"infralog-resources":   strconv.Itoa(s.Resources),
+		"infralog-destructive": strconv.FormatBool(s.HasDestructiveChanges()),
+	}
+	if p.ID != "" {
+		attributes["infralog-run-id"] = p.ID
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		for key, value := range map[string]string{
+			"infralog-repo-url":   p.Metadata.Git.RepoURL,
+			"infralog-branch":     p.Metadata.Git.Branch,
+			"infralog-commit-sha": p.Metadata.Git.CommitSHA,
+		} {
+			if value != "" {
+				attributes[key] = value
+			}
+		}
+	}
+
+	return attributes
+}
+
+// NewID returns a random RFC 4122 version 4 UUID, used for message and run IDs.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("error generating id: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
@@ -0,0 +1,140 @@
+package target
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestNewMessages(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.ID = "3e0c6a4e-2f4b-4d9a-9c1e-7b2f5d8a6c10"
+
+	messages := NewMessages(payload, false)
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+
+	m := messages[0]
+	if m.Type != MessageTypePlan || m.Version != MessageVersion || m.Data != payload {
+		t.Errorf("Unexpected plan message: %+v", m)
+	}
+	expected := map[string]string{
+		"content-type":         "application/json",
+		"infralog-type":        MessageTypePlan,
+		"infralog-id":          m.ID,
+		"infralog-run-id":      payload.ID,
+		"infralog-branch":      "main",
+		"infralog-commit-sha":  "abc123def456",
+		"infralog-resources":   "3",
+		"infralog-destructive": "true",
+		"infralog-datetime":    "2025-12-12T10:30:45Z",
+	}
+	for key, value := range expected {
+		if m.Attributes[key] != value {
+			t.Errorf("Expected attribute %s %q, got %q", key, value, m.Attributes[key])
+		}
+	}
+	if _, ok := m.Attributes["infralog-repo-url"]; ok {
+		t.Error("Expected empty git fields to be omitted")
+	}
+
+	body, err := m.Body()
+	if err != nil {
+		t.Fatalf("Body() error = %v", err)
+	}
+	var decoded struct {
+		Version string          `json:"version"`
+		ID      string          `json:"id"`
+		Type    string          `json:"type"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal body: %v", err)
+	}
+	if decoded.ID != m.ID || decoded.Type != MessageTypePlan || len(decoded.Data) == 0 {
+		t.Errorf("Unexpected body: %s", body)
+	}
+}
+
+func TestNewMessages_PerResource(t *testing.T) {
+	messages := NewMessages(markdownTestPayload(1), true)
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+
+	seen := map[string]bool{}
+	for _, m := range messages {
+		if m.Type != MessageTypeResourceChange {
+			t.Errorf("Expected resource change message, got %s", m.Type)
+		}
+		if seen[m.ID] {
+			t.Errorf("Duplicate message id %s", m.ID)
+		}
+		seen[m.ID] = true
+	}
+
+	removed := messages[2]
+	if removed.Attributes["infralog-address"] != "aws_security_group.old" || removed.Attributes["infralog-action"] != StatusRemoved {
+		t.Errorf("Unexpected attributes: %v", removed.Attributes)
+	}
+	if messages[0].Attributes["infralog-address"] == removed.Attributes["infralog-address"] {
+		t.Error("Expected attributes not to be shared between messages")
+	}
+}
+
+func TestCountAttributes(t *testing.T) {
+	attributes := CountAttributes(markdownTestPayload(1))
+
+	expected := map[string]string{
+		"infralog-added":    "1",
+		"infralog-changed":  "1",
+		"infralog-replaced": "0",
+		"infralog-removed":  "1",
+	}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("Expected attribute %s %q, got %q", key, value, attributes[key])
+		}
+	}
+}
+
+func TestNewID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := NewID(); !uuid.MatchString(id) {
+		t.Errorf("Expected a version 4 UUID, got %q", id)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	const maxSize = 100
+
+	tests := []struct {
+		name       string
+		sizes      []int
+		maxEntries int
+		expected   []int
+	}{
+		{"Empty", nil, 10, nil},
+		{"Single batch", []int{1, 2, 3}, 10, []int{3}},
+		{"Entry limit", make([]int, 25), 10, []int{10, 10, 5}},
+		{"No entry limit", make([]int, 25), 0, []int{25}},
+		{"Size limit", []int{maxSize / 2, maxSize / 2, 1, maxSize}, 10, []int{2, 1, 1}},
+		{"Oversized item", []int{1, maxSize + 1, 1}, 10, []int{1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := Batch(tt.sizes, tt.maxEntries, maxSize, func(n int) int { return n })
+
+			if len(batches) != len(tt.expected) {
+				t.Fatalf("Expected %d batches, got %d", len(tt.expected), len(batches))
+			}
+			for i, batch := range batches {
+				if len(batch) != tt.expected[i] {
+					t.Errorf("Batch %d: expected %d items, got %d", i, tt.expected[i], len(batch))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"text/template"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const publishTimeout = 30 * time.Second
+
+// publisher publishes messages on a NATS connection.
+type publisher interface {
+	Publish(ctx context.Context, msg *natsgo.Msg) error
+	Close()
+}
+
+type NATSTarget struct {
+	subject     *template.Template
+	perResource bool
+	cloudEvents *target.CloudEvents
+	connect     func() (publisher, error)
+}
+
+func New(cfg config.NATSConfig) (*NATSTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("nats URL is required")
+	}
+	if cfg.Subject == "" {
+		return nil, fmt.Errorf("nats subject is required")
+	}
+
+	subject, err := target.ParseTemplate("subject", cfg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	cloudEvents, err := target.NewCloudEvents(cfg.CloudEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []natsgo.Option{natsgo.Name("infralog")}
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, natsgo.UserCredentials(cfg.CredentialsFile))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, natsgo.Token(cfg.Token))
+	}
+	if cfg.CAFile != "" {
+		opts = append(opts, natsgo.RootCAs(cfg.CAFile))
+	}
+
+	return &NATSTarget{
+		subject:     subject,
+		perResource: cfg.MessagePerResource,
+		cloudEvents: cloudEvents,
+		connect: func() (publisher, error) {
+			nc, err := natsgo.Connect(cfg.URL, opts...)
+			if err != nil {
+				return nil, err
+			}
+			if !cfg.JetStream {
+				return &corePublisher{nc: nc}, nil
+			}
+
+			js, err := jetstream.New(nc)
+			if err != nil {
+				nc.Close()
+				return nil, err
+			}
+			return &jetStreamPublisher{nc: nc, js: js}, nil
+		},
+	}, nil
+}
+
+// Write publishes the payload, or one message per resource change, to the
+// rendered subject.
+func (t *NATSTarget) Write(p *target.Payload) error {
+	subject, err := target.ExecuteTemplate(t.subject, p)
+	if err != nil {
+		return err
+	}
+
+	var msgs []*natsgo.Msg
+	for _, m := range target.NewMessages(p, t.perResource) {
+		body, attributes, err := target.EncodeMessage(p, m, t.cloudEvents, "ce-")
+		if err != nil {
+			return err
+		}
+
+		header := natsgo.Header{}
+		for key, value := range attributes {
+			header.Set(key, value)
+		}
+		// Lets JetStream streams drop duplicates of a retried publish.
+		header.Set(jetstream.MsgIDHeader, m.ID)
+
+		msgs = append(msgs, &natsgo.Msg{Subject: subject, Header: header, Data: body})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	pub, err := t.connect()
+	if err != nil {
+		return fmt.Errorf("error connecting to nats: %w", err)
+	}
+	defer pub.Close()
+
+	for _, msg := range msgs {
+		if err := pub.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("error publishing to nats subject %s: %w", subject, err)
+		}
+	}
+	return nil
+}
+
+// corePublisher publishes with core NATS, which is fire-and-forget: the
+// connection is flushed so that messages have at least reached the server.
+type corePublisher struct {
+	nc *natsgo.Conn
+}
+
+func (p *corePublisher) Publish(ctx context.Context, msg *natsgo.Msg) error {
+	if err := p.nc.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.nc.FlushWithContext(ctx)
+}
+
+func (p *corePublisher) Close() {
+	p.nc.Close()
+}
+
+// jetStreamPublisher publishes to a JetStream stream and waits for the
+// stream to acknowledge each message.
+type jetStreamPublisher struct {
+	nc *natsgo.Conn
+	js jetstream.JetStream
+}
+
+func (p *jetStreamPublisher) Publish(ctx context.Context, msg *natsgo.Msg) error {
+	_, err := p.js.PublishMsg(ctx, msg)
+	return err
+}
+
+func (p *jetStreamPublisher) Close() {
+	p.nc.Close()
+}
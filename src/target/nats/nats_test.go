@@ -0,0 +1,137 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"testing"
+
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// fakePublisher records published messages in memory.
+type fakePublisher struct {
+	messages []*natsgo.Msg
+	closed   bool
+	err      error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msg *natsgo.Msg) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakePublisher) Close() {
+	p.closed = true
+}
+
+func newTestTarget(t *testing.T, cfg config.NATSConfig) (*NATSTarget, *fakePublisher) {
+	t.Helper()
+
+	cfg.URL = "nats://localhost:4222"
+	tgt, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	pub := &fakePublisher{}
+	tgt.connect = func() (publisher, error) { return pub, nil }
+	return tgt, pub
+}
+
+func TestWrite_Success(t *testing.T) {
+	tgt, pub := newTestTarget(t, config.NATSConfig{Subject: "infra.changes.{{.Git.Branch}}"})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(pub.messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(pub.messages))
+	}
+	if !pub.closed {
+		t.Error("Expected connection to be closed")
+	}
+
+	msg := pub.messages[0]
+	if msg.Subject != "infra.changes.main" {
+		t.Errorf("Expected subject infra.changes.main, got %q", msg.Subject)
+	}
+
+	var got struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected message: %s", msg.Data)
+	}
+	if msg.Header.Get("Nats-Msg-Id") != got.ID {
+		t.Errorf("Expected Nats-Msg-Id %q, got %q", got.ID, msg.Header.Get("Nats-Msg-Id"))
+	}
+	if msg.Header.Get("infralog-branch") != "main" || msg.Header.Get("infralog-destructive") != "true" {
+		t.Errorf("Unexpected headers: %v", msg.Header)
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	tgt, pub := newTestTarget(t, config.NATSConfig{Subject: "infra.changes", MessagePerResource: true})
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(pub.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(pub.messages))
+	}
+	if pub.messages[1].Header.Get("infralog-address") != "aws_instance.web" || pub.messages[1].Header.Get("infralog-action") != target.StatusRemoved {
+		t.Errorf("Unexpected headers: %v", pub.messages[1].Header)
+	}
+	if pub.messages[0].Header.Get("Nats-Msg-Id") == pub.messages[1].Header.Get("Nats-Msg-Id") {
+		t.Error("Expected unique message ids")
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	tgt, pub := newTestTarget(t, config.NATSConfig{Subject: "infra.changes"})
+	pub.err = errors.New("no responders available for request")
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected a publish error")
+	}
+	if !pub.closed {
+		t.Error("Expected connection to be closed after an error")
+	}
+
+	tgt.connect = func() (publisher, error) { return nil, errors.New("connection refused") }
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected a connection error")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.NATSConfig
+	}{
+		{"Missing URL", config.NATSConfig{Subject: "infra.changes"}},
+		{"Missing subject", config.NATSConfig{URL: "nats://localhost:4222"}},
+		{"Invalid subject template", config.NATSConfig{URL: "nats://localhost:4222", Subject: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package opsgenie
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultURL = "https://api.opsgenie.com"
+
+	// Opsgenie field limits.
+	maxMessageLength     = 130
+	maxDescriptionLength = 15000
+
+	requestTimeout = 30 * time.Second
+)
+
+var validPriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+
+type OpsgenieTarget struct {
+	url        string
+	apiKey     string
+	priority   string
+	tags       []string
+	responders []responder
+	trigger    config.TriggerConfig
+	client     *http.Client
+	result     string
+}
+
+type alert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Responders  []responder       `json:"responders,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+}
+
+type responder struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func New(cfg config.OpsgenieConfig) (*OpsgenieTarget, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("opsgenie API key is required")
+	}
+
+	priority := strings.ToUpper(cfg.Priority)
+	if priority != "" && !slices.Contains(validPriorities, priority) {
+		return nil, fmt.Errorf("invalid priority: %s. Priority must be one of P1-P5", cfg.Priority)
+	}
+
+	responders, err := parseResponders(cfg.Responders)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.ValidateTrigger(cfg.Trigger); err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(cfg.URL, "/")
+	if url == "" {
+		url = defaultURL
+	}
+
+	return &OpsgenieTarget{
+		url:        url,
+		apiKey:     cfg.APIKey,
+		priority:   priority,
+		tags:       cfg.Tags,
+		responders: responders,
+		trigger:    cfg.Trigger.WithDefaults(),
+		client:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// parseResponders parses responders in "type:name" form, e.g. "team:platform"
+// or "user:jane@example.com".
+func parseResponders(values []string) ([]responder, error) {
+	var responders []responder
+	for _, value := range values {
+		kind, name, ok := strings.Cut(value, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid responder: %s. Responders must be in type:name form", value)
+		}
+
+		switch kind {
+		case "user":
+			responders = append(responders, responder{Type: kind, Username: name})
+		case "team", "escalation", "schedule":
+			responders = append(responders, responder{Type: kind, Name: name})
+		default:
+			return nil, fmt.Errorf("invalid responder type: %s. Type must be team, user, escalation or schedule", kind)
+		}
+	}
+	return responders, nil
+}
+
+// Write creates an Opsgenie alert if the plan contains changes matching the
+// trigger conditions. Plans without matching changes are ignored.
+func (t *OpsgenieTarget) Write(p *target.Payload) error {
+	changes := target.TriggeringChanges(p.Plan, t.trigger)
+	if len(changes) == 0 {
+		t.result = "no triggering changes"
+		return nil
+	}
+
+	jsonData, err := json.Marshal(t.buildAlert(p, changes))
+	if err != nil {
+		return fmt.Errorf("error marshaling opsgenie alert: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", t.url+"/v2/alerts", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+t.apiKey)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending opsgenie alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("opsgenie request failed with status code: %d", resp.StatusCode)
+	}
+
+	t.result = fmt.Sprintf("alert created for %d change(s)", len(changes))
+	return nil
+}
+
+// Result describes the outcome of the last Write, including when no alert
+// was raised because no change matched the trigger.
+func (t *OpsgenieTarget) Result() string {
+	return t.result
+}
+
+func (t *OpsgenieTarget) buildAlert(p *target.Payload, changes []tfplan.ResourceChange) alert {
+	details := map[string]string{}
+	var entity string
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		setDetail(details, "branch", git.Branch)
+		setDetail(details, "commit_sha", git.CommitSHA)
+		setDetail(details, "committer", git.Committer)
+		setDetail(details, "repo_url", git.RepoURL)
+		entity = git.RepoURL
+	}
+
+	tags := append([]string{"infralog"}, t.tags...)
+	groups := target.GroupByStatus(changes)
+	for _, status := range target.StatusOrder {
+		if len(groups[status]) > 0 {
+			tags = append(tags, "action:"+status)
+		}
+	}
+
+	return alert{
+		Message:     target.Truncate(fmt.Sprintf("Terraform plan will affect %d protected resource(s)", len(changes)), maxMessageLength),
+		Alias:       target.DedupKey(p, changes),
+		Description: target.Truncate(buildDescription(changes), maxDescriptionLength),
+		Responders:  t.responders,
+		Tags:        tags,
+		Details:     details,
+		Entity:      entity,
+		Source:      "Infralog",
+		Priority:    t.alertPriority(changes),
+	}
+}
+
+// alertPriority returns the configured priority, or derives it from the most
+// severe change: deletes are P1, replacements P2, updates P3 and creates P4.
+func (t *OpsgenieTarget) alertPriority(changes []tfplan.ResourceChange) string {
+	if t.priority != "" {
+		return t.priority
+	}
+
+	switch target.MostSevereStatus(changes) {
+	case target.StatusRemoved:
+		return "P1"
+	case target.StatusReplaced:
+		return "P2"
+	case target.StatusChanged:
+		return "P3"
+	default:
+		return "P4"
+	}
+}
+
+func buildDescription(changes []tfplan.ResourceChange) string {
+	var sb strings.Builder
+	sb.WriteString("The following resources match the alert conditions:\n\n")
+	for _, rc := range changes {
+		sb.WriteString(fmt.Sprintf("- %s (%s)\n", target.ResourceAddress(rc), target.ActionsToStatus(rc.Change.Actions)))
+	}
+	return sb.String()
+}
+
+func setDetail(details map[string]string, key, value string) {
+	if value != "" {
+		details[key] = value
+	}
+}
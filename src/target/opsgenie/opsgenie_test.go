@@ -0,0 +1,217 @@
+package opsgenie
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.OpsgenieConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with API key",
+			cfg:         config.OpsgenieConfig{APIKey: "genie-key"},
+			expectError: false,
+		},
+		{
+			name:        "Empty API key",
+			cfg:         config.OpsgenieConfig{},
+			expectError: true,
+		},
+		{
+			name:        "Valid priority",
+			cfg:         config.OpsgenieConfig{APIKey: "genie-key", Priority: "p2"},
+			expectError: false,
+		},
+		{
+			name:        "Invalid priority",
+			cfg:         config.OpsgenieConfig{APIKey: "genie-key", Priority: "P9"},
+			expectError: true,
+		},
+		{
+			name:        "Invalid responder",
+			cfg:         config.OpsgenieConfig{APIKey: "genie-key", Responders: []string{"platform"}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid trigger action",
+			cfg:         config.OpsgenieConfig{APIKey: "genie-key", Trigger: config.TriggerConfig{Actions: []string{"delete", "Replace"}}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestParseResponders(t *testing.T) {
+	responders, err := parseResponders([]string{"team:platform", "user:jane@example.com", "schedule:on-call"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	expected := []responder{
+		{Type: "team", Name: "platform"},
+		{Type: "user", Username: "jane@example.com"},
+		{Type: "schedule", Name: "on-call"},
+	}
+	if !slices.Equal(responders, expected) {
+		t.Errorf("parseResponders() = %+v, want %+v", responders, expected)
+	}
+
+	if _, err := parseResponders([]string{"group:platform"}); err == nil {
+		t.Error("Expected an error for unknown responder type")
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	var received alert
+	var authHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/alerts" {
+			t.Errorf("Expected path /v2/alerts, got %s", r.URL.Path)
+		}
+		authHeader = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ogTarget, err := New(config.OpsgenieConfig{
+		APIKey:     "genie-key",
+		URL:        server.URL + "/",
+		Tags:       []string{"prod"},
+		Responders: []string{"team:platform"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create opsgenie target: %v", err)
+	}
+
+	payload := &target.Payload{
+		Plan: &tfplan.Plan{
+			ResourceChanges: []tfplan.ResourceChange{
+				{Address: "aws_s3_bucket.logs", Change: tfplan.Change{Actions: []string{"create"}}},
+				{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+			},
+		},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &target.PayloadMetadata{
+			Git: &git.Metadata{Branch: "main", RepoURL: "https://github.com/example/infra"},
+		},
+	}
+	if err := ogTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if authHeader != "GenieKey genie-key" {
+		t.Errorf("Expected GenieKey authorization header, got %q", authHeader)
+	}
+	if received.Priority != "P2" {
+		t.Errorf("Expected priority P2 for replacements, got %s", received.Priority)
+	}
+	if !strings.HasPrefix(received.Alias, "infralog-") {
+		t.Errorf("Expected infralog alias, got %s", received.Alias)
+	}
+	if !slices.Equal(received.Tags, []string{"infralog", "prod", "action:replaced"}) {
+		t.Errorf("Unexpected tags: %v", received.Tags)
+	}
+	if len(received.Responders) != 1 || received.Responders[0].Name != "platform" {
+		t.Errorf("Unexpected responders: %+v", received.Responders)
+	}
+	if received.Details["branch"] != "main" || received.Entity != "https://github.com/example/infra" {
+		t.Errorf("Expected git details, got %v / %s", received.Details, received.Entity)
+	}
+	if !strings.Contains(received.Description, "aws_instance.web (replaced)") {
+		t.Errorf("Expected replaced resource in description, got %q", received.Description)
+	}
+	if strings.Contains(received.Description, "aws_s3_bucket.logs") {
+		t.Errorf("Expected non-triggering resources to be excluded, got %q", received.Description)
+	}
+	if !strings.HasPrefix(ogTarget.Result(), "alert created for ") {
+		t.Errorf("Unexpected result %q", ogTarget.Result())
+	}
+}
+
+func TestWrite_NoTriggeringChanges(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ogTarget, err := New(config.OpsgenieConfig{APIKey: "genie-key", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create opsgenie target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"update"}}},
+		},
+	})
+	if err := ogTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if called {
+		t.Error("Expected no alert to be created for a plan without destructive changes")
+	}
+	if ogTarget.Result() != "no triggering changes" {
+		t.Errorf("Unexpected result %q", ogTarget.Result())
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	ogTarget, err := New(config.OpsgenieConfig{APIKey: "genie-key", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create opsgenie target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"delete"}}},
+		},
+	})
+	if err := ogTarget.Write(payload); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
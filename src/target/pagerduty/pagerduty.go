@@ -0,0 +1,201 @@
+package pagerduty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	defaultURL    = "https://events.pagerduty.com/v2/enqueue"
+	defaultSource = "infralog"
+
+	// PagerDuty truncates summaries longer than 1024 characters.
+	maxSummaryLength = 1024
+
+	requestTimeout = 30 * time.Second
+)
+
+var validSeverities = []string{"critical", "error", "warning", "info"}
+
+type PagerDutyTarget struct {
+	url        string
+	routingKey string
+	severity   string
+	source     string
+	trigger    config.TriggerConfig
+	client     *http.Client
+	result     string
+}
+
+type event struct {
+	RoutingKey  string       `json:"routing_key"`
+	EventAction string       `json:"event_action"`
+	DedupKey    string       `json:"dedup_key"`
+	Client      string       `json:"client"`
+	Payload     eventPayload `json:"payload"`
+}
+
+type eventPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Class         string                 `json:"class"`
+	CustomDetails map[string]interface{} `json:"custom_details"`
+}
+
+func New(cfg config.PagerDutyConfig) (*PagerDutyTarget, error) {
+	if cfg.RoutingKey == "" {
+		return nil, fmt.Errorf("pagerduty routing key is required")
+	}
+
+	if cfg.Severity != "" && !slices.Contains(validSeverities, cfg.Severity) {
+		return nil, fmt.Errorf("invalid severity: %s. Severity must be critical, error, warning or info", cfg.Severity)
+	}
+
+	if err := target.ValidateTrigger(cfg.Trigger); err != nil {
+		return nil, err
+	}
+
+	url := cfg.URL
+	if url == "" {
+		url = defaultURL
+	}
+
+	return &PagerDutyTarget{
+		url:        url,
+		routingKey: cfg.RoutingKey,
+		severity:   cfg.Severity,
+		source:     cfg.Source,
+		trigger:    cfg.Trigger.WithDefaults(),
+		client:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write triggers a PagerDuty incident if the plan contains changes matching
+// the trigger conditions. Plans without matching changes are ignored.
+func (t *PagerDutyTarget) Write(p *target.Payload) error {
+	changes := target.TriggeringChanges(p.Plan, t.trigger)
+	if len(changes) == 0 {
+		t.result = "no triggering changes"
+		return nil
+	}
+
+	jsonData, err := json.Marshal(t.buildEvent(p, changes))
+	if err != nil {
+		return fmt.Errorf("error marshaling pagerduty event: %w", err)
+	}
+
+	resp, err := t.client.Post(t.url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending pagerduty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pagerduty request failed with status code: %d", resp.StatusCode)
+	}
+
+	t.result = fmt.Sprintf("incident triggered for %d change(s)", len(changes))
+	return nil
+}
+
+// Result describes the outcome of the last Write, including when no incident
+// was raised because no change matched the trigger.
+func (t *PagerDutyTarget) Result() string {
+	return t.result
+}
+
+func (t *PagerDutyTarget) buildEvent(p *target.Payload, changes []tfplan.ResourceChange) event {
+	details := map[string]interface{}{
+		"changes": formatChanges(changes),
+	}
+
+	source := t.source
+	var group string
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		details["branch"] = git.Branch
+		details["commit_sha"] = git.CommitSHA
+		details["committer"] = git.Committer
+		details["repo_url"] = git.RepoURL
+		if source == "" {
+			source = git.RepoURL
+		}
+		group = git.Branch
+	}
+	if source == "" {
+		source = defaultSource
+	}
+
+	return event{
+		RoutingKey:  t.routingKey,
+		EventAction: "trigger",
+		DedupKey:    target.DedupKey(p, changes),
+		Client:      "Infralog",
+		Payload: eventPayload{
+			Summary:       target.Truncate(buildSummary(changes), maxSummaryLength),
+			Source:        source,
+			Severity:      t.eventSeverity(changes),
+			Timestamp:     p.Datetime.Format(time.RFC3339),
+			Component:     "terraform",
+			Group:         group,
+			Class:         target.MostSevereStatus(changes),
+			CustomDetails: details,
+		},
+	}
+}
+
+// eventSeverity returns the configured severity, or derives it from the most
+// severe change: deletes are critical, replacements errors, updates warnings.
+func (t *PagerDutyTarget) eventSeverity(changes []tfplan.ResourceChange) string {
+	if t.severity != "" {
+		return t.severity
+	}
+
+	switch target.MostSevereStatus(changes) {
+	case target.StatusRemoved:
+		return "critical"
+	case target.StatusReplaced:
+		return "error"
+	case target.StatusChanged:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func buildSummary(changes []tfplan.ResourceChange) string {
+	groups := target.GroupByStatus(changes)
+	summary := fmt.Sprintf("Terraform plan will affect %d protected resource(s):", len(changes))
+	for _, status := range target.StatusOrder {
+		if n := len(groups[status]); n > 0 {
+			summary += fmt.Sprintf(" %d %s", n, status)
+		}
+	}
+	if len(changes) > 0 {
+		summary += fmt.Sprintf(" (%s", target.ResourceAddress(changes[0]))
+		if len(changes) > 1 {
+			summary += fmt.Sprintf(" and %d more", len(changes)-1)
+		}
+		summary += ")"
+	}
+	return summary
+}
+
+func formatChanges(changes []tfplan.ResourceChange) []string {
+	lines := make([]string, 0, len(changes))
+	for _, rc := range changes {
+		lines = append(lines, fmt.Sprintf("%s - %s", target.ResourceAddress(rc), target.ActionsToStatus(rc.Change.Actions)))
+	}
+	return lines
+}
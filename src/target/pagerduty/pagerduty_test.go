@@ -0,0 +1,204 @@
+package pagerduty
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.PagerDutyConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with routing key",
+			cfg:         config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y"},
+			expectError: false,
+		},
+		{
+			name:        "Empty routing key",
+			cfg:         config.PagerDutyConfig{},
+			expectError: true,
+		},
+		{
+			name:        "Valid severity",
+			cfg:         config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", Severity: "warning"},
+			expectError: false,
+		},
+		{
+			name:        "Invalid severity",
+			cfg:         config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", Severity: "urgent"},
+			expectError: true,
+		},
+		{
+			name:        "Invalid trigger action",
+			cfg:         config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", Trigger: config.TriggerConfig{Actions: []string{"destroy"}}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid trigger address pattern",
+			cfg:         config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", Trigger: config.TriggerConfig{Addresses: []string{`module.prod\`}}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	var received event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pdTarget, err := New(config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create pagerduty target: %v", err)
+	}
+
+	payload := targettest.Payload(
+		targettest.Change("aws_s3_bucket.logs", "create"),
+		targettest.Change("aws_db_instance.main", "delete"),
+		targettest.Change("aws_instance.web", "delete", "create"),
+	)
+	if err := pdTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if received.RoutingKey != "R0UT1NGK3Y" {
+		t.Errorf("Expected routing key R0UT1NGK3Y, got %s", received.RoutingKey)
+	}
+	if received.EventAction != "trigger" {
+		t.Errorf("Expected event action trigger, got %s", received.EventAction)
+	}
+	if !strings.HasPrefix(received.DedupKey, "infralog-") {
+		t.Errorf("Expected infralog dedup key, got %s", received.DedupKey)
+	}
+	if received.Payload.Severity != "critical" {
+		t.Errorf("Expected severity critical for deletes, got %s", received.Payload.Severity)
+	}
+	if received.Payload.Source != "git@github.com:company/infrastructure.git" {
+		t.Errorf("Expected repository URL as source, got %s", received.Payload.Source)
+	}
+	if received.Payload.Timestamp != "2025-12-12T10:30:45Z" {
+		t.Errorf("Unexpected timestamp: %s", received.Payload.Timestamp)
+	}
+	if !strings.Contains(received.Payload.Summary, "2 protected resource(s)") {
+		t.Errorf("Expected only the delete and replace to trigger, got summary %q", received.Payload.Summary)
+	}
+	changes, _ := received.Payload.CustomDetails["changes"].([]interface{})
+	if len(changes) != 2 {
+		t.Errorf("Expected 2 changes in custom details, got %v", received.Payload.CustomDetails["changes"])
+	}
+	if pdTarget.Result() != "incident triggered for 2 change(s)" {
+		t.Errorf("Unexpected result %q", pdTarget.Result())
+	}
+}
+
+func TestWrite_NoTriggeringChanges(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pdTarget, err := New(config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create pagerduty target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_s3_bucket.logs", Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+	})
+	if err := pdTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if called {
+		t.Error("Expected no event to be sent for a plan without destructive changes")
+	}
+	if pdTarget.Result() != "no triggering changes" {
+		t.Errorf("Unexpected result %q", pdTarget.Result())
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	pdTarget, err := New(config.PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create pagerduty target: %v", err)
+	}
+
+	if err := pdTarget.Write(targettest.Payload(targettest.Change("aws_db_instance.main", "delete"))); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestEventSeverity(t *testing.T) {
+	pdTarget := &PagerDutyTarget{}
+
+	tests := []struct {
+		actions  []string
+		expected string
+	}{
+		{[]string{"delete"}, "critical"},
+		{[]string{"delete", "create"}, "error"},
+		{[]string{"update"}, "warning"},
+		{[]string{"create"}, "info"},
+	}
+
+	for _, tt := range tests {
+		changes := []tfplan.ResourceChange{{Change: tfplan.Change{Actions: tt.actions}}}
+		if got := pdTarget.eventSeverity(changes); got != tt.expected {
+			t.Errorf("eventSeverity(%v) = %s, want %s", tt.actions, got, tt.expected)
+		}
+	}
+
+	pdTarget.severity = "info"
+	changes := []tfplan.ResourceChange{{Change: tfplan.Change{Actions: []string{"delete"}}}}
+	if got := pdTarget.eventSeverity(changes); got != "info" {
+		t.Errorf("Expected configured severity to take precedence, got %s", got)
+	}
+}
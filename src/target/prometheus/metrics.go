@@ -0,0 +1,180 @@
+package prometheus
+
+import (
+	"fmt"
+	"infralog/target"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Metric names.
+const (
+	MetricPlannedChanges         = "infralog_planned_changes"
+	MetricPlannedResourceChanges = "infralog_planned_resource_changes"
+	MetricLastRunTimestamp       = "infralog_last_run_timestamp_seconds"
+)
+
+// Terraform actions used as the action label. Resources that are both
+// created and deleted are counted as replace.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+)
+
+var actions = []string{ActionCreate, ActionUpdate, ActionDelete, ActionReplace}
+
+// reservedLabels are set by infralog on every series and can't be configured.
+var reservedLabels = map[string]bool{"action": true, "resource_type": true, "module": true}
+
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseLabels validates label names and parses label values as templates.
+func parseLabels(labels map[string]string) (map[string]*template.Template, error) {
+	parsed := make(map[string]*template.Template, len(labels))
+	for name, value := range labels {
+		if !labelNamePattern.MatchString(name) || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("invalid label name: %s", name)
+		}
+		if reservedLabels[name] {
+			return nil, fmt.Errorf("label %s is reserved", name)
+		}
+		tmpl, err := target.ParseTemplate(name, value)
+		if err != nil {
+			return nil, err
+		}
+		parsed[name] = tmpl
+	}
+	return parsed, nil
+}
+
+// executeLabels renders label value templates for a payload.
+func executeLabels(labels map[string]*template.Template, p *target.Payload) (map[string]string, error) {
+	values := make(map[string]string, len(labels))
+	for name, tmpl := range labels {
+		value, err := target.ExecuteTemplate(tmpl, p)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// planAction returns the action label for a resource change, or an empty
+// string for no-op and read changes.
+func planAction(tfActions []string) string {
+	var create, update, del bool
+	for _, a := range tfActions {
+		switch a {
+		case "create":
+			create = true
+		case "update":
+			update = true
+		case "delete":
+			del = true
+		}
+	}
+	switch {
+	case create && del:
+		return ActionReplace
+	case create:
+		return ActionCreate
+	case del:
+		return ActionDelete
+	case update:
+		return ActionUpdate
+	}
+	return ""
+}
+
+type resourceKey struct {
+	action       string
+	resourceType string
+	module       string
+}
+
+// RenderMetrics renders the planned changes of a payload in the Prometheus
+// text exposition format. Every series carries the given constant labels.
+//
+// The counts describe the last plan, so they are gauges: each run replaces
+// the previous values. The per-action totals are always present, including
+// zeros, so alerts such as deletes > 0 resolve once a plan has no deletes.
+func RenderMetrics(p *target.Payload, labels map[string]string) []byte {
+	totals := map[string]int{}
+	resources := map[resourceKey]int{}
+	for _, rc := range p.Plan.ResourceChanges {
+		action := planAction(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+		totals[action]++
+		resources[resourceKey{action, rc.Type, rc.ModuleAddress}]++
+	}
+
+	keys := make([]resourceKey, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.action != b.action {
+			return a.action < b.action
+		}
+		if a.resourceType != b.resourceType {
+			return a.resourceType < b.resourceType
+		}
+		return a.module < b.module
+	})
+
+	constant := formatLabels(labels)
+
+	var sb strings.Builder
+	writeHeader(&sb, MetricPlannedChanges, "Resource changes in the last Terraform plan by action.")
+	for _, action := range actions {
+		fmt.Fprintf(&sb, "%s{action=\"%s\"%s} %d\n", MetricPlannedChanges, action, constant, totals[action])
+	}
+
+	writeHeader(&sb, MetricPlannedResourceChanges, "Resource changes in the last Terraform plan by action, resource type and module.")
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "%s{action=\"%s\",module=\"%s\",resource_type=\"%s\"%s} %d\n",
+			MetricPlannedResourceChanges, key.action, escapeLabelValue(key.module), escapeLabelValue(key.resourceType), constant, resources[key])
+	}
+
+	writeHeader(&sb, MetricLastRunTimestamp, "Unix time of the last infralog run.")
+	if constant != "" {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", MetricLastRunTimestamp, constant[1:], p.Datetime.Unix())
+	} else {
+		fmt.Fprintf(&sb, "%s %d\n", MetricLastRunTimestamp, p.Datetime.Unix())
+	}
+
+	return []byte(sb.String())
+}
+
+func writeHeader(sb *strings.Builder, name, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// formatLabels formats labels sorted by name, each preceded by a comma.
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, ",%s=\"%s\"", name, escapeLabelValue(labels[name]))
+	}
+	return sb.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"infralog/target"
+	"infralog/target/targettest"
+	"strings"
+	"testing"
+)
+
+// metricsPayload returns a payload with resources of several types, a module
+// resource and a data source.
+func metricsPayload() *target.Payload {
+	return targettest.Payload(
+		targettest.Change("aws_s3_bucket.data", "create"),
+		targettest.Change("aws_s3_bucket.logs", "create"),
+		targettest.Change("module.db.aws_db_instance.main", "delete", "create"),
+		targettest.Change("aws_instance.web", "delete"),
+		targettest.Change("data.aws_ami.ubuntu", "read"),
+	)
+}
+
+func TestRenderMetrics(t *testing.T) {
+	got := string(RenderMetrics(metricsPayload(), map[string]string{"env": "prod", "repo": `a"b`}))
+
+	expected := `# HELP infralog_planned_changes Resource changes in the last Terraform plan by action.
+# TYPE infralog_planned_changes gauge
+infralog_planned_changes{action="create",env="prod",repo="a\"b"} 2
+infralog_planned_changes{action="update",env="prod",repo="a\"b"} 0
+infralog_planned_changes{action="delete",env="prod",repo="a\"b"} 1
+infralog_planned_changes{action="replace",env="prod",repo="a\"b"} 1
+# HELP infralog_planned_resource_changes Resource changes in the last Terraform plan by action, resource type and module.
+# TYPE infralog_planned_resource_changes gauge
+infralog_planned_resource_changes{action="create",module="",resource_type="aws_s3_bucket",env="prod",repo="a\"b"} 2
+infralog_planned_resource_changes{action="delete",module="",resource_type="aws_instance",env="prod",repo="a\"b"} 1
+infralog_planned_resource_changes{action="replace",module="module.db",resource_type="aws_db_instance",env="prod",repo="a\"b"} 1
+# HELP infralog_last_run_timestamp_seconds Unix time of the last infralog run.
+# TYPE infralog_last_run_timestamp_seconds gauge
+infralog_last_run_timestamp_seconds{env="prod",repo="a\"b"} 1765535445
+`
+	if got != expected {
+		t.Errorf("Unexpected metrics:\n%s", got)
+	}
+}
+
+func TestRenderMetrics_EmptyPlan(t *testing.T) {
+	p := metricsPayload()
+	p.Plan.ResourceChanges = nil
+
+	got := string(RenderMetrics(p, nil))
+
+	for _, s := range []string{
+		`infralog_planned_changes{action="delete"} 0`,
+		"infralog_last_run_timestamp_seconds 1765535445\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", s, got)
+		}
+	}
+	if strings.Contains(got, "infralog_planned_resource_changes{") {
+		t.Errorf("Expected no per-resource series, got:\n%s", got)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	for _, name := range []string{"action", "module", "resource_type", "__name__", "1env", "env-name"} {
+		if _, err := parseLabels(map[string]string{name: "x"}); err == nil {
+			t.Errorf("Expected an error for label %q", name)
+		}
+	}
+	if _, err := parseLabels(map[string]string{"branch": "{{.Git"}); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,144 @@
+package prometheus
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultJob = "infralog"
+
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	requestTimeout = 30 * time.Second
+)
+
+type PushgatewayTarget struct {
+	url      string
+	job      *template.Template
+	grouping map[string]*template.Template
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewPushgateway(cfg config.PushgatewayConfig) (*PushgatewayTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("pushgateway URL is required")
+	}
+
+	jobText := cfg.Job
+	if jobText == "" {
+		jobText = defaultJob
+	}
+	job, err := target.ParseTemplate("job", jobText)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := cfg.Grouping["job"]; ok {
+		return nil, fmt.Errorf("grouping label job is reserved, set job instead")
+	}
+	grouping, err := parseLabels(cfg.Grouping)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushgatewayTarget{
+		url:      strings.TrimSuffix(cfg.URL, "/"),
+		job:      job,
+		grouping: grouping,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// ReportsEmptyPlans reports that metrics are pushed for plans without
+// changes, so that the previous counts are reset to zero.
+func (t *PushgatewayTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write replaces the metrics of the grouping key with the counts of the plan.
+func (t *PushgatewayTarget) Write(p *target.Payload) error {
+	groupURL, err := t.groupURL(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, groupURL, bytes.NewReader(RenderMetrics(p, nil)))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if t.username != "" || t.password != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error pushing metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushgateway returned non-success status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// groupURL returns the URL of the grouping key, e.g.
+// {url}/metrics/job/infralog/env/prod. Grouping labels are sorted by name.
+func (t *PushgatewayTarget) groupURL(p *target.Payload) (string, error) {
+	job, err := target.ExecuteTemplate(t.job, p)
+	if err != nil {
+		return "", err
+	}
+	if job == "" {
+		return "", fmt.Errorf("job template rendered an empty job name")
+	}
+
+	grouping, err := executeLabels(t.grouping, p)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(grouping))
+	for name := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(t.url + "/metrics")
+	sb.WriteString(groupingPathSegment("job", job))
+	for _, name := range names {
+		sb.WriteString(groupingPathSegment(name, grouping[name]))
+	}
+	return sb.String(), nil
+}
+
+// groupingPathSegment encodes a grouping label as a URL path segment. Values
+// that are empty or contain a slash use the Pushgateway's base64 encoding.
+func groupingPathSegment(name, value string) string {
+	if value == "" || strings.Contains(value, "/") {
+		encoded := base64.URLEncoding.EncodeToString([]byte(value))
+		if encoded == "" {
+			encoded = "="
+		}
+		return "/" + name + "@base64/" + encoded
+	}
+	return "/" + name + "/" + url.PathEscape(value)
+}
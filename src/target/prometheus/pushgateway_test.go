@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"infralog/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushgatewayWrite_Success(t *testing.T) {
+	var gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT, got %s", r.Method)
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+			t.Errorf("Unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "infralog" || pass != "secret" {
+			t.Errorf("Expected basic auth, got %q %q", user, pass)
+		}
+		gotPath = r.URL.EscapedPath()
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tgt, err := NewPushgateway(config.PushgatewayConfig{
+		URL:      server.URL + "/",
+		Grouping: map[string]string{"env": "prod", "repo": "{{.Git.RepoURL}}", "branch": "{{.Git.Branch}}"},
+		Username: "infralog",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewPushgateway() error = %v", err)
+	}
+
+	if err := tgt.Write(metricsPayload()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	expectedPath := "/metrics/job/infralog/branch/main/env/prod/repo@base64/Z2l0QGdpdGh1Yi5jb206Y29tcGFueS9pbmZyYXN0cnVjdHVyZS5naXQ="
+	if gotPath != expectedPath {
+		t.Errorf("Expected path %s, got %s", expectedPath, gotPath)
+	}
+	if !strings.Contains(gotBody, "infralog_planned_changes{action=\"delete\"} 1\n") {
+		t.Errorf("Unexpected body:\n%s", gotBody)
+	}
+}
+
+func TestPushgatewayWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("pushed metrics are invalid or inconsistent with existing metrics"))
+	}))
+	defer server.Close()
+
+	tgt, err := NewPushgateway(config.PushgatewayConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewPushgateway() error = %v", err)
+	}
+
+	err = tgt.Write(metricsPayload())
+	if err == nil || !strings.Contains(err.Error(), "400: pushed metrics are invalid") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestGroupingPathSegment(t *testing.T) {
+	tests := map[string]string{
+		"prod":        "/env/prod",
+		"":            "/env@base64/=",
+		"a/b":         "/env@base64/YS9i",
+		"eu west":     "/env/eu%20west",
+		"feature/abc": "/env@base64/ZmVhdHVyZS9hYmM=",
+	}
+	for value, expected := range tests {
+		if got := groupingPathSegment("env", value); got != expected {
+			t.Errorf("groupingPathSegment(%q) = %s, want %s", value, got, expected)
+		}
+	}
+}
+
+func TestNewPushgateway_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PushgatewayConfig
+	}{
+		{"Missing URL", config.PushgatewayConfig{}},
+		{"Job in grouping", config.PushgatewayConfig{URL: "http://localhost:9091", Grouping: map[string]string{"job": "x"}}},
+		{"Reserved grouping label", config.PushgatewayConfig{URL: "http://localhost:9091", Grouping: map[string]string{"action": "x"}}},
+		{"Invalid job template", config.PushgatewayConfig{URL: "http://localhost:9091", Job: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPushgateway(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package prometheus
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/fsutil"
+	"strings"
+	"text/template"
+)
+
+type TextfileTarget struct {
+	path   string
+	labels map[string]*template.Template
+	result string
+}
+
+func NewTextfile(cfg config.TextfileConfig) (*TextfileTarget, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("textfile path is required")
+	}
+	// The node_exporter textfile collector only reads *.prom files.
+	if !strings.HasSuffix(cfg.Path, ".prom") {
+		return nil, fmt.Errorf("textfile path must end with .prom: %s", cfg.Path)
+	}
+
+	labels, err := parseLabels(cfg.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TextfileTarget{
+		path:   cfg.Path,
+		labels: labels,
+	}, nil
+}
+
+// ReportsEmptyPlans reports that the file is written for plans without
+// changes, so that the previous counts are reset to zero.
+func (t *TextfileTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write replaces the file with the counts of the plan.
+func (t *TextfileTarget) Write(p *target.Payload) error {
+	labels, err := executeLabels(t.labels, p)
+	if err != nil {
+		return err
+	}
+
+	// The temporary file doesn't end with .prom, so node_exporter never
+	// scrapes a partial file.
+	if err := fsutil.WriteFileAtomic(t.path, RenderMetrics(p, labels)); err != nil {
+		return err
+	}
+
+	t.result = t.path
+	return nil
+}
+
+// Result returns the path written by the last Write.
+func (t *TextfileTarget) Result() string {
+	return t.result
+}
@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"infralog/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextfileWrite_Success(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "infralog.prom")
+
+	tgt, err := NewTextfile(config.TextfileConfig{Path: path, Labels: map[string]string{"branch": "{{.Git.Branch}}"}})
+	if err != nil {
+		t.Fatalf("NewTextfile() error = %v", err)
+	}
+
+	if err := tgt.Write(metricsPayload()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read textfile: %v", err)
+	}
+	if !strings.Contains(string(data), `infralog_planned_changes{action="replace",branch="main"} 1`) {
+		t.Errorf("Unexpected textfile:\n%s", data)
+	}
+	if tgt.Result() != path {
+		t.Errorf("Expected result %s, got %s", path, tgt.Result())
+	}
+
+	// A second run replaces the file and leaves no temporary files behind.
+	p := metricsPayload()
+	p.Plan.ResourceChanges = nil
+	if err := tgt.Write(p); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), `infralog_planned_changes{action="replace",branch="main"} 0`) {
+		t.Errorf("Expected counts to be reset, got:\n%s", data)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the textfile in %s, got %d entries", dir, len(entries))
+	}
+}
+
+func TestNewTextfile_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TextfileConfig
+	}{
+		{"Missing path", config.TextfileConfig{}},
+		{"Not a .prom file", config.TextfileConfig{Path: "/tmp/infralog.txt"}},
+		{"Reserved label", config.TextfileConfig{Path: "/tmp/infralog.prom", Labels: map[string]string{"module": "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTextfile(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
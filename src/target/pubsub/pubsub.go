@@ -0,0 +1,199 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultEndpoint = "https://pubsub.googleapis.com"
+
+	// emulatorHostEnv points the Google Cloud client libraries at the Pub/Sub
+	// emulator, e.g. localhost:8085.
+	emulatorHostEnv = "PUBSUB_EMULATOR_HOST"
+
+	pubsubScope = "https://www.googleapis.com/auth/pubsub"
+
+	// Pub/Sub accepts at most 1000 messages and 10 MB per publish request.
+	maxBatchMessages = 1000
+	maxBatchSize     = 10 * 1000 * 1000
+
+	publishTimeout = 30 * time.Second
+)
+
+type pubsubMessage struct {
+	Data        string            `json:"data"` // Base64-encoded message body
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+type PubSubTarget struct {
+	topic       string // projects/{project}/topics/{topic}
+	endpoint    string
+	perResource bool
+	orderingKey *template.Template
+}
+
+func New(cfg config.PubSubConfig) (*PubSubTarget, error) {
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("pubsub topic is required")
+	}
+
+	topic := cfg.Topic
+	if !strings.HasPrefix(topic, "projects/") {
+		project := cfg.Project
+		if project == "" {
+			project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+		}
+		if project == "" {
+			return nil, fmt.Errorf("pubsub project is required")
+		}
+		topic = "projects/" + project + "/topics/" + topic
+	}
+
+	endpoint := cfg.EndpointURL
+	if endpoint == "" {
+		if host := os.Getenv(emulatorHostEnv); host != "" {
+			endpoint = "http://" + host
+		} else {
+			endpoint = defaultEndpoint
+		}
+	}
+
+	var orderingKey *template.Template
+	if cfg.OrderingKey != "" {
+		var err error
+		if orderingKey, err = target.ParseTemplate("ordering_key", cfg.OrderingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PubSubTarget{
+		topic:       topic,
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		perResource: cfg.MessagePerResource,
+		orderingKey: orderingKey,
+	}, nil
+}
+
+// Write publishes the payload to the topic, as one message or as one message
+// per resource change.
+func (t *PubSubTarget) Write(p *target.Payload) error {
+	messages, err := t.buildMessages(p)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	client, err := t.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range target.Batch(messages, maxBatchMessages, maxBatchSize, messageSize) {
+		if err := t.publish(ctx, client, batch); err != nil {
+			return fmt.Errorf("error publishing to pubsub topic %s: %w", t.topic, err)
+		}
+	}
+	return nil
+}
+
+func (t *PubSubTarget) buildMessages(p *target.Payload) ([]pubsubMessage, error) {
+	var orderingKey string
+	if t.orderingKey != nil {
+		var err error
+		if orderingKey, err = target.ExecuteTemplate(t.orderingKey, p); err != nil {
+			return nil, err
+		}
+	}
+
+	counts := target.CountAttributes(p)
+
+	var messages []pubsubMessage
+	for _, m := range target.NewMessages(p, t.perResource) {
+		body, err := m.Body()
+		if err != nil {
+			return nil, err
+		}
+
+		attributes := make(map[string]string, len(m.Attributes)+len(counts))
+		for k, v := range m.Attributes {
+			attributes[k] = v
+		}
+		for k, v := range counts {
+			attributes[k] = v
+		}
+
+		messages = append(messages, pubsubMessage{
+			Data:        base64.StdEncoding.EncodeToString(body),
+			Attributes:  attributes,
+			OrderingKey: orderingKey,
+		})
+	}
+	return messages, nil
+}
+
+// client returns an HTTP client authenticated with Application Default
+// Credentials. Plain HTTP endpoints, such as the emulator, are not
+// authenticated.
+func (t *PubSubTarget) client(ctx context.Context) (*http.Client, error) {
+	if strings.HasPrefix(t.endpoint, "http://") {
+		return http.DefaultClient, nil
+	}
+
+	client, err := google.DefaultClient(ctx, pubsubScope)
+	if err != nil {
+		return nil, fmt.Errorf("error loading google credentials: %w", err)
+	}
+	return client, nil
+}
+
+func (t *PubSubTarget) publish(ctx context.Context, client *http.Client, messages []pubsubMessage) error {
+	jsonData, err := json.Marshal(map[string]interface{}{"messages": messages})
+	if err != nil {
+		return fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+"/v1/"+t.topic+":publish", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("publish failed with status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// messageSize returns the size of a message counted against the request size
+// limit: its data and attributes.
+func messageSize(m pubsubMessage) int {
+	size := len(m.Data)
+	for k, v := range m.Attributes {
+		size += len(k) + len(v)
+	}
+	return size
+}
@@ -0,0 +1,182 @@
+package pubsub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer returns a fake Pub/Sub endpoint that records published
+// messages.
+func newTestServer(t *testing.T, status int) (*httptest.Server, *[]pubsubMessage) {
+	t.Helper()
+
+	var messages []pubsubMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/projects/infra-prod/topics/infra-changes:publish" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Expected no credentials for a plain HTTP endpoint")
+		}
+
+		var req struct{ Messages []pubsubMessage }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		messages = append(messages, req.Messages...)
+
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			json.NewEncoder(w).Encode(map[string][]string{"messageIds": {"1"}})
+		} else {
+			w.Write([]byte(`{"error": {"message": "Resource not found"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &messages
+}
+
+func decodeData(t *testing.T, m pubsubMessage, v interface{}) {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(m.Data)
+	if err != nil {
+		t.Fatalf("Failed to decode data: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to unmarshal data: %v", err)
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	server, messages := newTestServer(t, http.StatusOK)
+
+	tgt, err := New(config.PubSubConfig{Project: "infra-prod", Topic: "infra-changes", EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(*messages))
+	}
+	m := (*messages)[0]
+
+	var got struct {
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	decodeData(t, m, &got)
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected message: %+v", got)
+	}
+
+	expected := map[string]string{
+		"infralog-branch":     "main",
+		"infralog-commit-sha": "abc123def456",
+		"infralog-resources":  "2",
+		"infralog-added":      "1",
+		"infralog-removed":    "1",
+		"infralog-replaced":   "0",
+	}
+	for key, value := range expected {
+		if m.Attributes[key] != value {
+			t.Errorf("Expected attribute %s %q, got %q", key, value, m.Attributes[key])
+		}
+	}
+	if m.OrderingKey != "" {
+		t.Errorf("Expected no ordering key, got %q", m.OrderingKey)
+	}
+}
+
+func TestWrite_PerResource(t *testing.T) {
+	server, messages := newTestServer(t, http.StatusOK)
+
+	tgt, err := New(config.PubSubConfig{
+		Topic:              "projects/infra-prod/topics/infra-changes",
+		EndpointURL:        server.URL,
+		MessagePerResource: true,
+		OrderingKey:        "{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(*messages))
+	}
+	for i, expected := range []string{"aws_s3_bucket.data", "aws_instance.web"} {
+		m := (*messages)[i]
+		if m.Attributes["infralog-address"] != expected || m.OrderingKey != "main" {
+			t.Errorf("Message %d: unexpected attributes %v and ordering key %q", i, m.Attributes, m.OrderingKey)
+		}
+
+		var got struct {
+			Data target.ResourcePayload `json:"data"`
+		}
+		decodeData(t, m, &got)
+		if got.Data.ResourceChange.Address != expected {
+			t.Errorf("Message %d: expected %s, got %s", i, expected, got.Data.ResourceChange.Address)
+		}
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusNotFound)
+
+	tgt, err := New(config.PubSubConfig{Project: "infra-prod", Topic: "infra-changes", EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "Resource not found") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("PUBSUB_EMULATOR_HOST", "")
+
+	if _, err := New(config.PubSubConfig{}); err == nil {
+		t.Error("Expected an error for a missing topic")
+	}
+	if _, err := New(config.PubSubConfig{Topic: "infra-changes"}); err == nil {
+		t.Error("Expected an error for a missing project")
+	}
+	if _, err := New(config.PubSubConfig{Project: "infra-prod", Topic: "infra-changes", OrderingKey: "{{.Git"}); err == nil {
+		t.Error("Expected an error for an invalid ordering key template")
+	}
+
+	tgt, err := New(config.PubSubConfig{Project: "infra-prod", Topic: "infra-changes"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.endpoint != defaultEndpoint || tgt.topic != "projects/infra-prod/topics/infra-changes" {
+		t.Errorf("Unexpected endpoint %q and topic %q", tgt.endpoint, tgt.topic)
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "infra-dev")
+	t.Setenv("PUBSUB_EMULATOR_HOST", "localhost:8085")
+	tgt, err = New(config.PubSubConfig{Topic: "infra-changes"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.endpoint != "http://localhost:8085" || tgt.topic != "projects/infra-dev/topics/infra-changes" {
+		t.Errorf("Unexpected endpoint %q and topic %q", tgt.endpoint, tgt.topic)
+	}
+}
@@ -0,0 +1,243 @@
+package target
+
+import (
+	"fmt"
+	"html/template"
+	"infralog/git"
+	"sort"
+	"strings"
+)
+
+// report is the view model shared by the text and HTML renderings.
+type report struct {
+	Summary   string
+	Time      string
+	Git       *git.Metadata
+	ShortSHA  string
+	Resources []resourceRow
+	Outputs   []outputRow
+}
+
+type resourceRow struct {
+	Address    string
+	Status     string
+	Color      string
+	Attributes []attributeRow
+}
+
+type attributeRow struct {
+	Name   string
+	Before string
+	After  string
+}
+
+type outputRow struct {
+	Name   string
+	Status string
+	Color  string
+	Before string
+	After  string
+}
+
+func buildReport(p *Payload) report {
+	r := report{
+		Summary: Summarize(p.Plan).Text(),
+		Time:    p.Datetime.Format("2006-01-02 15:04:05 UTC"),
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		r.Git = p.Metadata.Git
+		r.ShortSHA = ShortSHA(p.Metadata.Git.CommitSHA)
+	}
+
+	groups := GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range StatusOrder {
+		for _, rc := range groups[status] {
+			row := resourceRow{
+				Address: ResourceAddress(rc),
+				Status:  status,
+				Color:   statusColor(status),
+			}
+
+			// Show changed attributes for updates
+			if status == StatusChanged || status == StatusReplaced {
+				changes := ExtractChanges(rc.Change)
+				for _, attr := range SortedAttributes(changes) {
+					row.Attributes = append(row.Attributes, attributeRow{
+						Name:   attr,
+						Before: FormatValue(changes[attr].Before),
+						After:  FormatValue(changes[attr].After),
+					})
+				}
+			}
+
+			r.Resources = append(r.Resources, row)
+		}
+	}
+
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(p.Plan.OutputChanges))
+	for name := range p.Plan.OutputChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oc := p.Plan.OutputChanges[name]
+		status := ActionsToStatus(oc.Change.Actions)
+		row := outputRow{
+			Name:   name,
+			Status: status,
+			Color:  statusColor(status),
+		}
+		if status == StatusChanged || status == StatusReplaced {
+			row.Before = FormatValue(oc.Change.Before)
+			row.After = FormatValue(oc.Change.After)
+		}
+		r.Outputs = append(r.Outputs, row)
+	}
+
+	return r
+}
+
+// RenderText renders the payload as a plain-text report with the summary, git
+// context, resource changes with their changed attributes, and output changes.
+func RenderText(p *Payload) string {
+	r := buildReport(p)
+	var sb strings.Builder
+
+	sb.WriteString("Terraform Plan Changes\n")
+	sb.WriteString(r.Summary + "\n\n")
+	sb.WriteString(fmt.Sprintf("Time: %s\n", r.Time))
+
+	if r.Git != nil {
+		if r.Git.Committer != "" {
+			sb.WriteString(fmt.Sprintf("Committer: %s\n", r.Git.Committer))
+		}
+		if r.Git.Branch != "" {
+			sb.WriteString(fmt.Sprintf("Branch: %s\n", r.Git.Branch))
+		}
+		if r.ShortSHA != "" {
+			sb.WriteString(fmt.Sprintf("Commit: %s\n", r.ShortSHA))
+		}
+		if r.Git.RepoURL != "" {
+			sb.WriteString(fmt.Sprintf("Repository: %s\n", r.Git.RepoURL))
+		}
+	}
+
+	if len(r.Resources) > 0 {
+		sb.WriteString("\nResource Changes\n\n")
+		for _, row := range r.Resources {
+			sb.WriteString(fmt.Sprintf("%s %s - %s\n", statusSymbol(row.Status), row.Address, row.Status))
+			for _, attr := range row.Attributes {
+				sb.WriteString(fmt.Sprintf("    %s: %s -> %s\n", attr.Name, attr.Before, attr.After))
+			}
+		}
+	}
+
+	if len(r.Outputs) > 0 {
+		sb.WriteString("\nOutput Changes\n\n")
+		for _, row := range r.Outputs {
+			sb.WriteString(fmt.Sprintf("%s %s - %s\n", statusSymbol(row.Status), row.Name, row.Status))
+			if row.Before != "" || row.After != "" {
+				sb.WriteString(fmt.Sprintf("    %s -> %s\n", row.Before, row.After))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, Helvetica, sans-serif; font-size: 14px; color: #24292f;">
+<h2 style="margin-bottom: 4px;">Terraform Plan Changes</h2>
+<p style="margin-top: 0; color: #57606a;">{{.Summary}}</p>
+<table cellpadding="4" cellspacing="0" style="border-collapse: collapse; margin-bottom: 16px;">
+<tr><td><strong>Time</strong></td><td>{{.Time}}</td></tr>
+{{- with .Git}}
+{{- if .Committer}}
+<tr><td><strong>Committer</strong></td><td>{{.Committer}}</td></tr>
+{{- end}}
+{{- if .Branch}}
+<tr><td><strong>Branch</strong></td><td><code>{{.Branch}}</code></td></tr>
+{{- end}}
+{{- end}}
+{{- if .ShortSHA}}
+<tr><td><strong>Commit</strong></td><td><code>{{.ShortSHA}}</code></td></tr>
+{{- end}}
+{{- with .Git}}
+{{- if .RepoURL}}
+<tr><td><strong>Repository</strong></td><td>{{.RepoURL}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if .Resources}}
+<h3>Resource Changes</h3>
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse; border: 1px solid #d0d7de;">
+<tr style="background: #f6f8fa;"><th align="left" style="border: 1px solid #d0d7de;">Action</th><th align="left" style="border: 1px solid #d0d7de;">Resource</th><th align="left" style="border: 1px solid #d0d7de;">Changed attributes</th></tr>
+{{- range .Resources}}
+<tr>
+<td style="border: 1px solid #d0d7de; color: {{.Color}}; font-weight: bold;">{{.Status}}</td>
+<td style="border: 1px solid #d0d7de;"><code>{{.Address}}</code></td>
+<td style="border: 1px solid #d0d7de;">
+{{- range .Attributes}}
+<div><code>{{.Name}}</code>: <span style="color: #cf222e;">{{.Before}}</span> &rarr; <span style="color: #1a7f37;">{{.After}}</span></div>
+{{- end}}
+</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Outputs}}
+<h3>Output Changes</h3>
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse; border: 1px solid #d0d7de;">
+<tr style="background: #f6f8fa;"><th align="left" style="border: 1px solid #d0d7de;">Action</th><th align="left" style="border: 1px solid #d0d7de;">Output</th><th align="left" style="border: 1px solid #d0d7de;">Change</th></tr>
+{{- range .Outputs}}
+<tr>
+<td style="border: 1px solid #d0d7de; color: {{.Color}}; font-weight: bold;">{{.Status}}</td>
+<td style="border: 1px solid #d0d7de;"><code>{{.Name}}</code></td>
+<td style="border: 1px solid #d0d7de;">{{if or .Before .After}}<span style="color: #cf222e;">{{.Before}}</span> &rarr; <span style="color: #1a7f37;">{{.After}}</span>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML renders the same report as RenderText as a standalone HTML
+// document with inline styles, suitable for email clients.
+func RenderHTML(p *Payload) (string, error) {
+	var sb strings.Builder
+	if err := htmlTemplate.Execute(&sb, buildReport(p)); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func statusSymbol(status string) string {
+	switch status {
+	case StatusAdded:
+		return "[+]"
+	case StatusRemoved:
+		return "[-]"
+	case StatusChanged, StatusReplaced:
+		return "[~]"
+	default:
+		return "[?]"
+	}
+}
+
+func statusColor(status string) string {
+	switch status {
+	case StatusAdded:
+		return "#1a7f37"
+	case StatusRemoved:
+		return "#cf222e"
+	case StatusChanged, StatusReplaced:
+		return "#9a6700"
+	default:
+		return "#57606a"
+	}
+}
@@ -0,0 +1,44 @@
+package target
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	body := RenderText(markdownTestPayload(1))
+
+	expected := []string{
+		"Terraform plan changes detected: 3 resource(s), 1 output(s) changed",
+		"Branch: main\nCommit: abc123de\n",
+		"[+] aws_s3_bucket.data - added",
+		"[~] aws_instance.web - changed\n    tag_00: old -> new\n",
+		"[-] aws_security_group.old - removed",
+		"[~] instance_ip - changed",
+	}
+	for _, s := range expected {
+		if !strings.Contains(body, s) {
+			t.Errorf("Expected body to contain %q, got:\n%s", s, body)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.Metadata.Git.Branch = "feature/<script>"
+
+	body, err := RenderHTML(payload)
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+
+	if !strings.HasPrefix(body, "<!DOCTYPE html>") {
+		t.Error("Expected a standalone HTML document")
+	}
+	if !strings.Contains(body, "<code>aws_instance.web</code>") {
+		t.Errorf("Expected resource row, got:\n%s", body)
+	}
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "feature/&lt;script&gt;") {
+		t.Error("Expected git metadata to be escaped")
+	}
+}
@@ -0,0 +1,30 @@
+package target
+
+import (
+	"infralog/tfplan"
+	"time"
+)
+
+// ResourcePayload contains a single resource change, for targets that publish
+// one message per resource instead of the whole plan.
+type ResourcePayload struct {
+	ResourceChange tfplan.ResourceChange `json:"resource_change"`
+	Action         string                `json:"action"` // added, changed, replaced or removed
+	Datetime       time.Time             `json:"datetime"`
+	Metadata       *PayloadMetadata      `json:"metadata,omitempty"`
+}
+
+// SplitByResource returns one ResourcePayload per resource change in the
+// payload's plan, in plan order.
+func SplitByResource(p *Payload) []ResourcePayload {
+	resources := make([]ResourcePayload, 0, len(p.Plan.ResourceChanges))
+	for _, rc := range p.Plan.ResourceChanges {
+		resources = append(resources, ResourcePayload{
+			ResourceChange: rc,
+			Action:         ActionsToStatus(rc.Change.Actions),
+			Datetime:       p.Datetime,
+			Metadata:       p.Metadata,
+		})
+	}
+	return resources
+}
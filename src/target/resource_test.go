@@ -0,0 +1,28 @@
+package target
+
+import (
+	"testing"
+)
+
+func TestSplitByResource(t *testing.T) {
+	payload := markdownTestPayload(1)
+
+	resources := SplitByResource(payload)
+	if len(resources) != 3 {
+		t.Fatalf("Expected 3 resource payloads, got %d", len(resources))
+	}
+
+	expected := []struct{ address, action string }{
+		{"aws_s3_bucket.data", StatusAdded},
+		{"aws_instance.web", StatusChanged},
+		{"aws_security_group.old", StatusRemoved},
+	}
+	for i, e := range expected {
+		if resources[i].ResourceChange.Address != e.address || resources[i].Action != e.action {
+			t.Errorf("Resource %d = %s (%s), expected %s (%s)", i, resources[i].ResourceChange.Address, resources[i].Action, e.address, e.action)
+		}
+		if resources[i].Metadata != payload.Metadata || !resources[i].Datetime.Equal(payload.Datetime) {
+			t.Errorf("Resource %d does not carry the payload metadata", i)
+		}
+	}
+}
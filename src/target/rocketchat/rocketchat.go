@@ -0,0 +1,213 @@
+package rocketchat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Maximum number of changed attributes listed per resource.
+const maxAttributes = 5
+
+type RocketChatTarget struct {
+	webhookURL string
+	channel    string
+	alias      string
+	emoji      string
+	avatar     string
+}
+
+type rocketChatMessage struct {
+	Channel     string       `json:"channel,omitempty"`
+	Alias       string       `json:"alias,omitempty"`
+	Emoji       string       `json:"emoji,omitempty"`
+	Avatar      string       `json:"avatar,omitempty"`
+	Text        string       `json:"text"`
+	Attachments []attachment `json:"attachments,omitempty"`
+}
+
+type attachment struct {
+	Title  string  `json:"title,omitempty"`
+	Text   string  `json:"text,omitempty"`
+	Color  string  `json:"color,omitempty"`
+	Fields []field `json:"fields,omitempty"`
+}
+
+type field struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func New(cfg config.RocketChatConfig) (*RocketChatTarget, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("rocket.chat webhook URL is required")
+	}
+
+	return &RocketChatTarget{
+		webhookURL: cfg.WebhookURL,
+		channel:    cfg.Channel,
+		alias:      cfg.Alias,
+		emoji:      cfg.Emoji,
+		avatar:     cfg.Avatar,
+	}, nil
+}
+
+func (t *RocketChatTarget) Write(p *target.Payload) error {
+	msg := t.buildMessage(p)
+
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshaling rocket.chat message: %w", err)
+	}
+
+	resp, err := http.Post(t.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending rocket.chat message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("rocket.chat request failed with status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (t *RocketChatTarget) buildMessage(p *target.Payload) rocketChatMessage {
+	// Context - timestamp and git metadata
+	fields := []field{
+		{Title: "Time", Value: p.Datetime.Format("2006-01-02 15:04:05 UTC"), Short: true},
+	}
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		if git.Committer != "" {
+			fields = append(fields, field{Title: "Committer", Value: git.Committer, Short: true})
+		}
+		if git.Branch != "" {
+			fields = append(fields, field{Title: "Branch", Value: fmt.Sprintf("`%s`", git.Branch), Short: true})
+		}
+		if git.CommitSHA != "" {
+			fields = append(fields, field{Title: "Commit", Value: fmt.Sprintf("`%s`", target.ShortSHA(git.CommitSHA)), Short: true})
+		}
+		if git.RepoURL != "" {
+			fields = append(fields, field{Title: "Repository", Value: git.RepoURL, Short: false})
+		}
+	}
+
+	attachments := []attachment{
+		{
+			Title:  "Git Context",
+			Color:  planColor(p.Plan),
+			Fields: fields,
+		},
+	}
+
+	if len(p.Plan.ResourceChanges) > 0 {
+		attachments = append(attachments, attachment{
+			Title: "Resource Changes",
+			Text:  formatResourceChanges(p.Plan.ResourceChanges),
+			Color: planColor(p.Plan),
+		})
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		attachments = append(attachments, attachment{
+			Title: "Output Changes",
+			Text:  formatOutputChanges(p.Plan.OutputChanges),
+			Color: planColor(p.Plan),
+		})
+	}
+
+	return rocketChatMessage{
+		Channel:     t.channel,
+		Alias:       t.alias,
+		Emoji:       t.emoji,
+		Avatar:      t.avatar,
+		Text:        fmt.Sprintf("*Terraform Plan Changes*\n%s", target.Summarize(p.Plan).Text()),
+		Attachments: attachments,
+	}
+}
+
+func formatResourceChanges(changes []tfplan.ResourceChange) string {
+	var sb strings.Builder
+
+	for _, rc := range changes {
+		status := target.ActionsToStatus(rc.Change.Actions)
+		sb.WriteString(fmt.Sprintf("%s `%s` - %s\n",
+			statusEmoji(status), target.ResourceAddress(rc), status))
+
+		// Show changed attributes for updates
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			attrChanges := target.ExtractChanges(rc.Change)
+			for i, attr := range target.SortedAttributes(attrChanges) {
+				if i >= maxAttributes {
+					sb.WriteString(fmt.Sprintf("    • _...and %d more attributes_\n", len(attrChanges)-maxAttributes))
+					break
+				}
+				change := attrChanges[attr]
+				sb.WriteString(fmt.Sprintf("    • `%s`: `%v` → `%v`\n", attr, change.Before, change.After))
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func formatOutputChanges(changes map[string]tfplan.OutputChange) string {
+	var sb strings.Builder
+
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oc := changes[name]
+		status := target.ActionsToStatus(oc.Change.Actions)
+		sb.WriteString(fmt.Sprintf("%s `%s` - %s\n", statusEmoji(status), name, status))
+
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			sb.WriteString(fmt.Sprintf("    • `%v` → `%v`\n", oc.Change.Before, oc.Change.After))
+		}
+	}
+
+	return sb.String()
+}
+
+// planColor returns the attachment color for the most severe change in the plan.
+func planColor(plan *tfplan.Plan) string {
+	summary := target.Summarize(plan)
+	switch {
+	case summary.HasDestructiveChanges():
+		return "#f5455c"
+	case summary.Changed > 0:
+		return "#ffd21f"
+	case summary.Added > 0:
+		return "#2de0a5"
+	default:
+		return "#9ea2a8"
+	}
+}
+
+// statusEmoji uses Rocket.Chat's emoji shortcodes.
+func statusEmoji(status string) string {
+	switch status {
+	case target.StatusAdded:
+		return ":green_circle:"
+	case target.StatusRemoved:
+		return ":red_circle:"
+	case target.StatusChanged, target.StatusReplaced:
+		return ":yellow_circle:"
+	default:
+		return ":white_circle:"
+	}
+}
@@ -0,0 +1,148 @@
+package rocketchat
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.RocketChatConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with webhook URL",
+			cfg:         config.RocketChatConfig{WebhookURL: "https://rocket.example.com/hooks/xxx/yyy"},
+			expectError: false,
+		},
+		{
+			name:        "Empty webhook URL",
+			cfg:         config.RocketChatConfig{WebhookURL: ""},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_WithOptionalFields(t *testing.T) {
+	var receivedBody rocketChatMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rocketTarget, err := New(config.RocketChatConfig{
+		WebhookURL: server.URL,
+		Channel:    "#infra",
+		Alias:      "Infralog",
+		Emoji:      ":robot:",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create rocket.chat target: %v", err)
+	}
+
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_s3_bucket.data", Change: tfplan.Change{Actions: []string{"create"}}},
+			{
+				Address: "aws_instance.web",
+				Change: tfplan.Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "t2.micro"},
+					After:   map[string]interface{}{"instance_type": "t2.small"},
+				},
+			},
+		},
+		OutputChanges: map[string]tfplan.OutputChange{
+			"vpc_id": {Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+	}
+	if err := rocketTarget.Write(target.NewPayload(plan)); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	if receivedBody.Channel != "#infra" || receivedBody.Alias != "Infralog" || receivedBody.Emoji != ":robot:" {
+		t.Errorf("Expected overrides to be set, got %+v", receivedBody)
+	}
+	if !strings.HasPrefix(receivedBody.Text, "*Terraform Plan Changes*") {
+		t.Errorf("Expected Rocket.Chat bold header, got %q", receivedBody.Text)
+	}
+	if len(receivedBody.Attachments) != 3 {
+		t.Fatalf("Expected context, resource and output attachments, got %d", len(receivedBody.Attachments))
+	}
+	resources := receivedBody.Attachments[1].Text
+	if !strings.Contains(resources, ":green_circle: `aws_s3_bucket.data` - added") {
+		t.Errorf("Expected added resource line, got %q", resources)
+	}
+	if !strings.Contains(resources, "`instance_type`: `t2.micro` → `t2.small`") {
+		t.Errorf("Expected attribute diff, got %q", resources)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	rocketTarget, err := New(config.RocketChatConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create rocket.chat target: %v", err)
+	}
+
+	if err := rocketTarget.Write(target.NewPayload(&tfplan.Plan{})); err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestStatusEmoji(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{"added", ":green_circle:"},
+		{"removed", ":red_circle:"},
+		{"changed", ":yellow_circle:"},
+		{"replaced", ":yellow_circle:"},
+		{"unknown", ":white_circle:"},
+	}
+
+	for _, tt := range tests {
+		result := statusEmoji(tt.status)
+		if result != tt.expected {
+			t.Errorf("statusEmoji(%s) = %s, expected %s", tt.status, result, tt.expected)
+		}
+	}
+}
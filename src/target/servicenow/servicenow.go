@@ -0,0 +1,223 @@
+package servicenow
+
+import (
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Change request types.
+const (
+	TypeNormal   = "normal"
+	TypeStandard = "standard"
+)
+
+// Change request risk values in the default ServiceNow configuration.
+const (
+	RiskHigh     = "2"
+	RiskModerate = "3"
+	RiskLow      = "4"
+)
+
+const (
+	defaultShortDescription = "Terraform changes{{if .Git.Branch}} on {{.Git.Branch}}{{end}}: {{.Summary.Resources}} resource(s)"
+
+	// ServiceNow truncates short descriptions longer than 160 characters.
+	maxShortDescriptionLength = 160
+)
+
+type ServiceNowTarget struct {
+	url              string
+	changeType       string
+	assignmentGroup  string
+	shortDescription *template.Template
+	customFields     map[string]*template.Template
+	api              *target.APIClient
+	result           string
+}
+
+func New(cfg config.ServiceNowConfig) (*ServiceNowTarget, error) {
+	if cfg.InstanceURL == "" {
+		return nil, fmt.Errorf("servicenow instance URL is required")
+	}
+	if cfg.Token == "" && (cfg.Username == "" || cfg.Password == "") {
+		return nil, fmt.Errorf("servicenow token or username and password are required")
+	}
+
+	changeType := strings.ToLower(cfg.Type)
+	switch changeType {
+	case "":
+		changeType = TypeNormal
+	case TypeNormal, TypeStandard:
+	default:
+		return nil, fmt.Errorf("invalid change type: %s. Type must be normal or standard", cfg.Type)
+	}
+
+	shortDescriptionText := cfg.ShortDescription
+	if shortDescriptionText == "" {
+		shortDescriptionText = defaultShortDescription
+	}
+	shortDescription, err := target.ParseTemplate("short_description", shortDescriptionText)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields := make(map[string]*template.Template, len(cfg.CustomFields))
+	for field, text := range cfg.CustomFields {
+		tmpl, err := target.ParseTemplate(field, text)
+		if err != nil {
+			return nil, err
+		}
+		customFields[field] = tmpl
+	}
+
+	return &ServiceNowTarget{
+		url:              strings.TrimSuffix(cfg.InstanceURL, "/") + "/api/now/table/change_request",
+		changeType:       changeType,
+		assignmentGroup:  cfg.AssignmentGroup,
+		shortDescription: shortDescription,
+		customFields:     customFields,
+		api: target.NewAPIClient(func(req *http.Request) {
+			if cfg.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+cfg.Token)
+			} else {
+				req.SetBasicAuth(cfg.Username, cfg.Password)
+			}
+		}),
+	}, nil
+}
+
+// Write opens a change request for the plan.
+func (t *ServiceNowTarget) Write(p *target.Payload) error {
+	record, err := t.buildRecord(p)
+	if err != nil {
+		return err
+	}
+
+	number, err := t.create(record)
+	if err != nil {
+		return fmt.Errorf("error creating servicenow change request: %w", err)
+	}
+	t.result = number
+	return nil
+}
+
+// Result returns the number of the change request created by the last Write.
+func (t *ServiceNowTarget) Result() string {
+	return t.result
+}
+
+func (t *ServiceNowTarget) buildRecord(p *target.Payload) (map[string]string, error) {
+	shortDescription, err := target.ExecuteTemplate(t.shortDescription, p)
+	if err != nil {
+		return nil, err
+	}
+	shortDescription = target.Truncate(shortDescription, maxShortDescriptionLength)
+
+	summary := target.Summarize(p.Plan)
+	record := map[string]string{
+		"type":              t.changeType,
+		"short_description": shortDescription,
+		"description":       buildDescription(p),
+		"risk":              risk(summary),
+	}
+	if t.assignmentGroup != "" {
+		record["assignment_group"] = t.assignmentGroup
+	}
+
+	for field, tmpl := range t.customFields {
+		value, err := target.ExecuteTemplate(tmpl, p)
+		if err != nil {
+			return nil, err
+		}
+		record[field] = value
+	}
+
+	return record, nil
+}
+
+// risk returns high risk for plans that remove or replace resources, moderate
+// risk for updates and low risk for plans that only create resources.
+func risk(s target.Summary) string {
+	switch {
+	case s.HasDestructiveChanges():
+		return RiskHigh
+	case s.Changed > 0:
+		return RiskModerate
+	default:
+		return RiskLow
+	}
+}
+
+func buildDescription(p *target.Payload) string {
+	var sb strings.Builder
+
+	s := target.Summarize(p.Plan)
+	sb.WriteString(fmt.Sprintf("Terraform plan changes: %d resource(s), %d output(s)\n", s.Resources, s.Outputs))
+	sb.WriteString(fmt.Sprintf("Added: %d, changed: %d, replaced: %d, removed: %d\n", s.Added, s.Changed, s.Replaced, s.Removed))
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		sb.WriteString("\n")
+		for _, field := range []struct{ label, value string }{
+			{"Repository", git.RepoURL},
+			{"Branch", git.Branch},
+			{"Commit", git.CommitSHA},
+			{"Committer", git.Committer},
+		} {
+			if field.value != "" {
+				sb.WriteString(fmt.Sprintf("%s: %s\n", field.label, field.value))
+			}
+		}
+	}
+
+	groups := target.GroupByStatus(p.Plan.ResourceChanges)
+	for _, status := range target.StatusOrder {
+		if len(groups[status]) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s resources:\n", strings.ToUpper(status[:1])+status[1:]))
+		for _, rc := range groups[status] {
+			sb.WriteString(fmt.Sprintf("  - %s\n", target.ResourceAddress(rc)))
+			if status == target.StatusChanged || status == target.StatusReplaced {
+				changes := target.ExtractChanges(rc.Change)
+				for _, attr := range target.SortedAttributes(changes) {
+					sb.WriteString(fmt.Sprintf("      %s: %s -> %s\n", attr, target.FormatValue(changes[attr].Before), target.FormatValue(changes[attr].After)))
+				}
+			}
+		}
+	}
+
+	if len(p.Plan.OutputChanges) > 0 {
+		names := make([]string, 0, len(p.Plan.OutputChanges))
+		for name := range p.Plan.OutputChanges {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("\nOutputs:\n")
+		for _, name := range names {
+			status := target.ActionsToStatus(p.Plan.OutputChanges[name].Change.Actions)
+			sb.WriteString(fmt.Sprintf("  - %s (%s)\n", name, status))
+		}
+	}
+
+	return sb.String()
+}
+
+// create posts the record to the Table API and returns the change number.
+func (t *ServiceNowTarget) create(record map[string]string) (string, error) {
+	var created struct {
+		Result struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := t.api.Do("POST", t.url+"?sysparm_fields=number,sys_id", record, &created); err != nil {
+		return "", err
+	}
+	return created.Result.Number, nil
+}
@@ -0,0 +1,139 @@
+package servicenow
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"infralog/tfplan"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var got map[string]string
+	var username, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/now/table/change_request" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		username, password, _ = r.BasicAuth()
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"result":{"number":"CHG0030001","sys_id":"abc"}}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.ServiceNowConfig{
+		InstanceURL:     server.URL + "/",
+		Username:        "infralog",
+		Password:        "secret",
+		AssignmentGroup: "Cloud Platform",
+		CustomFields:    map[string]string{"u_repository": "{{.Git.RepoURL}}"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	update := targettest.Change("aws_instance.web", "update")
+	update.Change.Before = map[string]interface{}{"instance_type": "t2.micro", "monitoring": nil}
+	update.Change.After = map[string]interface{}{"instance_type": "t2.small", "monitoring": true}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), update)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if username != "infralog" || password != "secret" {
+		t.Errorf("Expected basic auth infralog:secret, got %s:%s", username, password)
+	}
+	if tgt.Result() != "CHG0030001" {
+		t.Errorf("Result() = %q, expected CHG0030001", tgt.Result())
+	}
+
+	expected := map[string]string{
+		"type":              TypeNormal,
+		"short_description": "Terraform changes on main: 2 resource(s)",
+		"risk":              RiskModerate,
+		"assignment_group":  "Cloud Platform",
+		"u_repository":      "git@github.com:company/infrastructure.git",
+	}
+	for field, value := range expected {
+		if got[field] != value {
+			t.Errorf("Expected %s %q, got %q", field, value, got[field])
+		}
+	}
+	for _, want := range []string{"Branch: main", "  - aws_instance.web\n", "instance_type: t2.micro -> t2.small", "monitoring: null -> true"} {
+		if !strings.Contains(got["description"], want) {
+			t.Errorf("Expected description to contain %q, got:\n%s", want, got["description"])
+		}
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"message":"Insufficient rights"}}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.ServiceNowConfig{InstanceURL: server.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create")))
+	if err == nil || !strings.Contains(err.Error(), "Insufficient rights") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestRisk(t *testing.T) {
+	tests := []struct {
+		name     string
+		actions  [][]string
+		expected string
+	}{
+		{"Creates only", [][]string{{"create"}}, RiskLow},
+		{"Updates", [][]string{{"create"}, {"update"}}, RiskModerate},
+		{"Deletes", [][]string{{"update"}, {"delete"}}, RiskHigh},
+		{"Replacements", [][]string{{"delete", "create"}}, RiskHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []tfplan.ResourceChange
+			for i, actions := range tt.actions {
+				changes = append(changes, targettest.Change(fmt.Sprintf("aws_instance.web_%d", i), actions...))
+			}
+			if got := risk(target.Summarize(&tfplan.Plan{ResourceChanges: changes})); got != tt.expected {
+				t.Errorf("risk() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ServiceNowConfig
+	}{
+		{"Missing credentials", config.ServiceNowConfig{InstanceURL: "https://example.service-now.com", Username: "infralog"}},
+		{"Invalid type", config.ServiceNowConfig{InstanceURL: "https://example.service-now.com", Token: "token", Type: "emergency"}},
+		{"Invalid template", config.ServiceNowConfig{InstanceURL: "https://example.service-now.com", Token: "token", ShortDescription: "{{.Git"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
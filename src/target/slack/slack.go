@@ -98,12 +98,7 @@ func (t *SlackTarget) buildMessage(p *target.Payload) slackMessage {
 			contextText += fmt.Sprintf("🌿 *Branch:* `%s`\n", git.Branch)
 		}
 		if git.CommitSHA != "" {
-			// Show short SHA (first 8 characters)
-			shortSHA := git.CommitSHA
-			if len(shortSHA) > 8 {
-				shortSHA = shortSHA[:8]
-			}
-			contextText += fmt.Sprintf("📝 *Commit:* `%s`\n", shortSHA)
+			contextText += fmt.Sprintf("📝 *Commit:* `%s`\n", target.ShortSHA(git.CommitSHA))
 		}
 		if git.RepoURL != "" {
 			contextText += fmt.Sprintf("🔗 *Repository:* %s\n", git.RepoURL)
@@ -168,14 +163,14 @@ func (t *SlackTarget) formatResourceChanges(changes []tfplan.ResourceChange) str
 	sb.WriteString("*Resource Changes*\n\n")
 
 	for _, rc := range changes {
-		status := actionsToStatus(rc.Change.Actions)
+		status := target.ActionsToStatus(rc.Change.Actions)
 		emoji := statusEmoji(status)
 		sb.WriteString(fmt.Sprintf("%s `%s.%s` - %s\n",
 			emoji, rc.Type, rc.Name, status))
 
 		// Show changed attributes for updates
-		if status == "changed" || status == "replaced" {
-			changes := extractChanges(rc.Change.Before, rc.Change.After)
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			changes := target.ExtractChanges(rc.Change)
 			// Limit to first 5 changed attributes to avoid excessive Slack message length
 			count := 0
 			for attr, change := range changes {
@@ -206,12 +201,12 @@ func (t *SlackTarget) formatOutputChanges(changes map[string]tfplan.OutputChange
 
 	for _, name := range names {
 		oc := changes[name]
-		status := actionsToStatus(oc.Change.Actions)
+		status := target.ActionsToStatus(oc.Change.Actions)
 		emoji := statusEmoji(status)
 		sb.WriteString(fmt.Sprintf("%s `%s` - %s\n",
 			emoji, name, status))
 
-		if status == "changed" || status == "replaced" {
+		if status == target.StatusChanged || status == target.StatusReplaced {
 			sb.WriteString(fmt.Sprintf("    • `%v` → `%v`\n",
 				oc.Change.Before, oc.Change.After))
 		}
@@ -221,100 +216,18 @@ func (t *SlackTarget) formatOutputChanges(changes map[string]tfplan.OutputChange
 }
 
 func (t *SlackTarget) buildFallbackText(plan *tfplan.Plan) string {
-	resourceCount := len(plan.ResourceChanges)
-	outputCount := len(plan.OutputChanges)
-
-	parts := []string{}
-	if resourceCount > 0 {
-		parts = append(parts, fmt.Sprintf("%d resource(s)", resourceCount))
-	}
-	if outputCount > 0 {
-		parts = append(parts, fmt.Sprintf("%d output(s)", outputCount))
-	}
-
-	return fmt.Sprintf("Terraform plan changes detected: %s changed", strings.Join(parts, ", "))
-}
-
-// actionsToStatus maps Terraform plan actions to a readable status string.
-func actionsToStatus(actions []string) string {
-	if len(actions) == 0 {
-		return "unknown"
-	}
-
-	// Sort actions to normalize ordering
-	sortedActions := make([]string, len(actions))
-	copy(sortedActions, actions)
-	sort.Strings(sortedActions)
-
-	// Single action cases
-	if len(sortedActions) == 1 {
-		switch sortedActions[0] {
-		case "create":
-			return "added"
-		case "delete":
-			return "removed"
-		case "update":
-			return "changed"
-		default:
-			return sortedActions[0]
-		}
-	}
-
-	// Multiple actions (typically replace operations: create + delete)
-	if len(sortedActions) == 2 {
-		if sortedActions[0] == "create" && sortedActions[1] == "delete" {
-			return "replaced"
-		}
-	}
-
-	return "changed"
+	return target.Summarize(plan).Text()
 }
 
 func statusEmoji(status string) string {
 	switch status {
-	case "added":
+	case target.StatusAdded:
 		return ":large_green_circle:"
-	case "removed":
+	case target.StatusRemoved:
 		return ":red_circle:"
-	case "changed", "replaced":
+	case target.StatusChanged, target.StatusReplaced:
 		return ":large_yellow_circle:"
 	default:
 		return ":white_circle:"
 	}
 }
-
-// ValueChange represents a before/after value pair for Slack formatting.
-type ValueChange struct {
-	Before interface{}
-	After  interface{}
-}
-
-// extractChanges compares before and after attribute maps and returns changed attributes.
-func extractChanges(before, after map[string]interface{}) map[string]ValueChange {
-	changes := make(map[string]ValueChange)
-
-	// Collect all unique attribute keys
-	allKeys := make(map[string]bool)
-	for key := range before {
-		allKeys[key] = true
-	}
-	for key := range after {
-		allKeys[key] = true
-	}
-
-	// Compare each attribute
-	for key := range allKeys {
-		beforeVal := before[key]
-		afterVal := after[key]
-
-		// Check if values are different (simple comparison)
-		if fmt.Sprintf("%v", beforeVal) != fmt.Sprintf("%v", afterVal) {
-			changes[key] = ValueChange{
-				Before: beforeVal,
-				After:  afterVal,
-			}
-		}
-	}
-
-	return changes
-}
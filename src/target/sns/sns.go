@@ -0,0 +1,144 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/awsutil"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+const (
+	defaultMessageGroupID = "infralog"
+
+	publishTimeout = 30 * time.Second
+)
+
+type SNSTarget struct {
+	topicARN       string
+	region         string
+	endpointURL    string
+	perResource    bool
+	fifo           bool
+	messageGroupID *template.Template
+}
+
+func New(cfg config.SNSConfig) (*SNSTarget, error) {
+	if cfg.TopicARN == "" {
+		return nil, fmt.Errorf("sns topic ARN is required")
+	}
+
+	messageGroupIDText := cfg.MessageGroupID
+	if messageGroupIDText == "" {
+		messageGroupIDText = defaultMessageGroupID
+	}
+	messageGroupID, err := target.ParseTemplate("message_group_id", messageGroupIDText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SNSTarget{
+		topicARN:       cfg.TopicARN,
+		region:         cfg.Region,
+		endpointURL:    cfg.EndpointURL,
+		perResource:    cfg.MessagePerResource,
+		fifo:           strings.HasSuffix(cfg.TopicARN, ".fifo"),
+		messageGroupID: messageGroupID,
+	}, nil
+}
+
+// Write publishes the payload to the topic, split into one message per
+// resource change if configured or if the plan exceeds the message size limit.
+func (t *SNSTarget) Write(p *target.Payload) error {
+	entries, err := t.buildEntries(p)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	awsCfg, err := awsutil.LoadConfig(ctx, t.region)
+	if err != nil {
+		return err
+	}
+	client := sns.NewFromConfig(awsCfg, func(o *sns.Options) {
+		if t.endpointURL != "" {
+			o.BaseEndpoint = aws.String(t.endpointURL)
+		}
+	})
+
+	for _, batch := range target.Batch(entries, awsutil.MaxBatchEntries, awsutil.MaxMessageSize, entrySize) {
+		out, err := client.PublishBatch(ctx, &sns.PublishBatchInput{
+			TopicArn:                   aws.String(t.topicARN),
+			PublishBatchRequestEntries: batch,
+		})
+		if err != nil {
+			return fmt.Errorf("error publishing to sns topic %s: %w", t.topicARN, err)
+		}
+		if len(out.Failed) > 0 {
+			f := out.Failed[0]
+			return fmt.Errorf("error publishing %d message(s) to sns topic %s: %s: %s", len(out.Failed), t.topicARN, aws.ToString(f.Code), aws.ToString(f.Message))
+		}
+	}
+	return nil
+}
+
+func (t *SNSTarget) buildEntries(p *target.Payload) ([]types.PublishBatchRequestEntry, error) {
+	messages, err := awsutil.Split(p, t.perResource, func(m target.Message) (int, error) {
+		body, err := m.Body()
+		return len(body) + awsutil.AttributesSize(awsutil.MessageAttributes(p, m)), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var messageGroupID string
+	if t.fifo {
+		if messageGroupID, err = target.ExecuteTemplate(t.messageGroupID, p); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]types.PublishBatchRequestEntry, 0, len(messages))
+	for _, m := range messages {
+		body, err := m.Body()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := types.PublishBatchRequestEntry{
+			Id:                aws.String(m.ID),
+			Message:           aws.String(string(body)),
+			MessageAttributes: map[string]types.MessageAttributeValue{},
+		}
+		for name, a := range awsutil.MessageAttributes(p, m) {
+			entry.MessageAttributes[name] = types.MessageAttributeValue{
+				DataType:    aws.String(a.DataType),
+				StringValue: aws.String(a.Value),
+			}
+		}
+		if t.fifo {
+			entry.MessageGroupId = aws.String(messageGroupID)
+			entry.MessageDeduplicationId = aws.String(m.ID)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func entrySize(e types.PublishBatchRequestEntry) int {
+	size := len(aws.ToString(e.Message))
+	for name, a := range e.MessageAttributes {
+		size += len(name) + len(aws.ToString(a.DataType)) + len(aws.ToString(a.StringValue))
+	}
+	return size
+}
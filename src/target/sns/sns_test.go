@@ -0,0 +1,169 @@
+package sns
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func setAWSEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+}
+
+// newTestServer returns a fake SNS endpoint that records PublishBatch
+// requests and fails the entries whose ID is returned by fail.
+func newTestServer(t *testing.T, fail func(id string) bool) (*httptest.Server, *[]url.Values) {
+	t.Helper()
+
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse request: %v", err)
+		}
+		if r.Form.Get("Action") != "PublishBatch" {
+			t.Errorf("Expected PublishBatch action, got %q", r.Form.Get("Action"))
+		}
+		requests = append(requests, r.Form)
+
+		var successful, failed string
+		for i := 1; r.Form.Get(fmt.Sprintf("PublishBatchRequestEntries.member.%d.Id", i)) != ""; i++ {
+			id := r.Form.Get(fmt.Sprintf("PublishBatchRequestEntries.member.%d.Id", i))
+			if fail != nil && fail(id) {
+				failed += fmt.Sprintf("<member><Id>%s</Id><Code>InternalError</Code><Message>boom</Message><SenderFault>false</SenderFault></member>", id)
+			} else {
+				successful += fmt.Sprintf("<member><Id>%s</Id><MessageId>%d</MessageId></member>", id, i)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, `<PublishBatchResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishBatchResult><Successful>%s</Successful><Failed>%s</Failed></PublishBatchResult><ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></PublishBatchResponse>`, successful, failed)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestWrite_Success(t *testing.T) {
+	setAWSEnv(t)
+	server, requests := newTestServer(t, nil)
+
+	tgt, err := New(config.SNSConfig{
+		TopicARN:    "arn:aws:sns:eu-west-1:123456789012:infra",
+		EndpointURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(*requests))
+	}
+	form := (*requests)[0]
+	if form.Get("TopicArn") != "arn:aws:sns:eu-west-1:123456789012:infra" {
+		t.Errorf("Unexpected topic %q", form.Get("TopicArn"))
+	}
+	if form.Get("PublishBatchRequestEntries.member.2.Id") != "" {
+		t.Error("Expected a single message")
+	}
+	if form.Get("PublishBatchRequestEntries.member.1.MessageGroupId") != "" {
+		t.Error("Expected no message group for a standard topic")
+	}
+
+	var got struct {
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("PublishBatchRequestEntries.member.1.Message")), &got); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected message: %+v", got)
+	}
+
+	attributes := map[string]string{}
+	for i := 1; form.Get(fmt.Sprintf("PublishBatchRequestEntries.member.1.MessageAttributes.entry.%d.Name", i)) != ""; i++ {
+		prefix := fmt.Sprintf("PublishBatchRequestEntries.member.1.MessageAttributes.entry.%d.", i)
+		attributes[form.Get(prefix+"Name")] = form.Get(prefix + "Value.StringValue")
+	}
+	expected := map[string]string{
+		"infralog-type":      target.MessageTypePlan,
+		"infralog-resources": "2",
+		"infralog-removed":   "1",
+		"infralog-branch":    "main",
+	}
+	for name, value := range expected {
+		if attributes[name] != value {
+			t.Errorf("Expected attribute %s %q, got %q", name, value, attributes[name])
+		}
+	}
+}
+
+func TestWrite_FIFOPerResource(t *testing.T) {
+	setAWSEnv(t)
+	server, requests := newTestServer(t, nil)
+
+	tgt, err := New(config.SNSConfig{
+		TopicARN:           "arn:aws:sns:eu-west-1:123456789012:infra.fifo",
+		EndpointURL:        server.URL,
+		MessagePerResource: true,
+		MessageGroupID:     "{{.Git.Branch}}",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	form := (*requests)[0]
+	for i := 1; i <= 2; i++ {
+		prefix := fmt.Sprintf("PublishBatchRequestEntries.member.%d.", i)
+		if form.Get(prefix+"MessageGroupId") != "main" {
+			t.Errorf("Entry %d: expected message group main, got %q", i, form.Get(prefix+"MessageGroupId"))
+		}
+		if form.Get(prefix+"MessageDeduplicationId") != form.Get(prefix+"Id") {
+			t.Errorf("Entry %d: expected the message ID as deduplication ID", i)
+		}
+	}
+}
+
+func TestWrite_Failed(t *testing.T) {
+	setAWSEnv(t)
+	server, _ := newTestServer(t, func(string) bool { return true })
+
+	tgt, err := New(config.SNSConfig{
+		TopicARN:    "arn:aws:sns:eu-west-1:123456789012:infra",
+		EndpointURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected an error for failed entries")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(config.SNSConfig{}); err == nil {
+		t.Error("Expected an error for a missing topic ARN")
+	}
+	if _, err := New(config.SNSConfig{TopicARN: "arn:aws:sns:eu-west-1:123456789012:infra", MessageGroupID: "{{.Git"}); err == nil {
+		t.Error("Expected an error for an invalid message group template")
+	}
+}
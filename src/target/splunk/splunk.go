@@ -0,0 +1,119 @@
+package splunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	eventPath = "/services/collector/event"
+
+	defaultSource     = "infralog"
+	defaultSourceType = "infralog:change"
+
+	requestTimeout = 30 * time.Second
+)
+
+// event is an HTTP Event Collector event.
+type event struct {
+	Time       int64             `json:"time"` // Unix time in seconds
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source"`
+	SourceType string            `json:"sourcetype"`
+	Index      string            `json:"index,omitempty"`
+	Event      map[string]string `json:"event"`
+}
+
+type SplunkTarget struct {
+	url        string
+	token      string
+	index      string
+	source     string
+	sourceType string
+	host       string
+	client     *http.Client
+}
+
+func New(cfg config.SplunkConfig) (*SplunkTarget, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("splunk URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("splunk token is required")
+	}
+
+	url := strings.TrimSuffix(cfg.URL, "/")
+	if !strings.HasSuffix(url, eventPath) {
+		url += eventPath
+	}
+
+	source := cfg.Source
+	if source == "" {
+		source = defaultSource
+	}
+	sourceType := cfg.SourceType
+	if sourceType == "" {
+		sourceType = defaultSourceType
+	}
+
+	return &SplunkTarget{
+		url:        url,
+		token:      cfg.Token,
+		index:      cfg.Index,
+		source:     source,
+		sourceType: sourceType,
+		host:       cfg.Host,
+		client:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Write sends one event per resource change in a single request.
+func (t *SplunkTarget) Write(p *target.Payload) error {
+	documents := target.ResourceDocuments(p)
+	if len(documents) == 0 {
+		return nil
+	}
+
+	// HEC accepts several events in one request as concatenated JSON objects.
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, document := range documents {
+		if err := enc.Encode(event{
+			Time:       p.Datetime.Unix(),
+			Host:       t.host,
+			Source:     t.source,
+			SourceType: t.sourceType,
+			Index:      t.index,
+			Event:      document,
+		}); err != nil {
+			return fmt.Errorf("error marshaling event: %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.url, &body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Splunk "+t.token)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// HEC explains failures, e.g. an invalid token or index, in the body
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("splunk returned status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
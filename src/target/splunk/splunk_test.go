@@ -0,0 +1,96 @@
+package splunk
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target/targettest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite_Success(t *testing.T) {
+	var events []event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/collector/event" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Splunk hec-token" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+
+		dec := json.NewDecoder(r.Body)
+		for {
+			var e event
+			if err := dec.Decode(&e); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+			events = append(events, e)
+		}
+		w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.SplunkConfig{URL: server.URL + "/", Token: "hec-token", Index: "audit"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	e := events[1]
+	if e.Time != 1765535445 || e.Index != "audit" || e.Source != "infralog" || e.SourceType != "infralog:change" {
+		t.Errorf("Unexpected event metadata: %+v", e)
+	}
+	expected := map[string]string{
+		"address":   "module.app.aws_instance.web",
+		"type":      "aws_instance",
+		"action":    "removed",
+		"module":    "module.app",
+		"branch":    "main",
+		"sha":       "abc123def456",
+		"committer": "Jane Doe",
+		"timestamp": "2025-12-12T10:30:45Z",
+	}
+	for key, value := range expected {
+		if e.Event[key] != value {
+			t.Errorf("Expected %s %q, got %q", key, value, e.Event[key])
+		}
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"text":"Invalid token","code":4}`))
+	}))
+	defer server.Close()
+
+	tgt, err := New(config.SplunkConfig{URL: server.URL + "/services/collector/event", Token: "wrong"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete")))
+	if err == nil || !strings.Contains(err.Error(), "Invalid token") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(config.SplunkConfig{Token: "hec-token"}); err == nil {
+		t.Error("Expected an error for a missing URL")
+	}
+	if _, err := New(config.SplunkConfig{URL: "https://splunk.example.com:8088"}); err == nil {
+		t.Error("Expected an error for a missing token")
+	}
+}
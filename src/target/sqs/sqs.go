@@ -0,0 +1,144 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/awsutil"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	defaultMessageGroupID = "infralog"
+
+	sendTimeout = 30 * time.Second
+)
+
+type SQSTarget struct {
+	queueURL       string
+	region         string
+	endpointURL    string
+	perResource    bool
+	fifo           bool
+	messageGroupID *template.Template
+}
+
+func New(cfg config.SQSConfig) (*SQSTarget, error) {
+	if cfg.QueueURL == "" {
+		return nil, fmt.Errorf("sqs queue URL is required")
+	}
+
+	messageGroupIDText := cfg.MessageGroupID
+	if messageGroupIDText == "" {
+		messageGroupIDText = defaultMessageGroupID
+	}
+	messageGroupID, err := target.ParseTemplate("message_group_id", messageGroupIDText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQSTarget{
+		queueURL:       cfg.QueueURL,
+		region:         cfg.Region,
+		endpointURL:    cfg.EndpointURL,
+		perResource:    cfg.MessagePerResource,
+		fifo:           strings.HasSuffix(cfg.QueueURL, ".fifo"),
+		messageGroupID: messageGroupID,
+	}, nil
+}
+
+// Write sends the payload to the queue, split into one message per resource
+// change if configured or if the plan exceeds the message size limit.
+func (t *SQSTarget) Write(p *target.Payload) error {
+	entries, err := t.buildEntries(p)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	awsCfg, err := awsutil.LoadConfig(ctx, t.region)
+	if err != nil {
+		return err
+	}
+	client := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+		if t.endpointURL != "" {
+			o.BaseEndpoint = aws.String(t.endpointURL)
+		}
+	})
+
+	for _, batch := range target.Batch(entries, awsutil.MaxBatchEntries, awsutil.MaxMessageSize, entrySize) {
+		out, err := client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(t.queueURL),
+			Entries:  batch,
+		})
+		if err != nil {
+			return fmt.Errorf("error sending to sqs queue %s: %w", t.queueURL, err)
+		}
+		if len(out.Failed) > 0 {
+			f := out.Failed[0]
+			return fmt.Errorf("error sending %d message(s) to sqs queue %s: %s: %s", len(out.Failed), t.queueURL, aws.ToString(f.Code), aws.ToString(f.Message))
+		}
+	}
+	return nil
+}
+
+func (t *SQSTarget) buildEntries(p *target.Payload) ([]types.SendMessageBatchRequestEntry, error) {
+	messages, err := awsutil.Split(p, t.perResource, func(m target.Message) (int, error) {
+		body, err := m.Body()
+		return len(body) + awsutil.AttributesSize(awsutil.MessageAttributes(p, m)), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var messageGroupID string
+	if t.fifo {
+		if messageGroupID, err = target.ExecuteTemplate(t.messageGroupID, p); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]types.SendMessageBatchRequestEntry, 0, len(messages))
+	for _, m := range messages {
+		body, err := m.Body()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := types.SendMessageBatchRequestEntry{
+			Id:                aws.String(m.ID),
+			MessageBody:       aws.String(string(body)),
+			MessageAttributes: map[string]types.MessageAttributeValue{},
+		}
+		for name, a := range awsutil.MessageAttributes(p, m) {
+			entry.MessageAttributes[name] = types.MessageAttributeValue{
+				DataType:    aws.String(a.DataType),
+				StringValue: aws.String(a.Value),
+			}
+		}
+		if t.fifo {
+			entry.MessageGroupId = aws.String(messageGroupID)
+			entry.MessageDeduplicationId = aws.String(m.ID)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func entrySize(e types.SendMessageBatchRequestEntry) int {
+	size := len(aws.ToString(e.MessageBody))
+	for name, a := range e.MessageAttributes {
+		size += len(name) + len(aws.ToString(a.DataType)) + len(aws.ToString(a.StringValue))
+	}
+	return size
+}
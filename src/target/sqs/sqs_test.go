@@ -0,0 +1,176 @@
+package sqs
+
+import (
+	"encoding/json"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type attributeValue struct {
+	DataType    string
+	StringValue string
+}
+
+type entry struct {
+	Id                     string
+	MessageBody            string
+	MessageAttributes      map[string]attributeValue
+	MessageGroupId         string
+	MessageDeduplicationId string
+}
+
+type batchRequest struct {
+	QueueUrl string
+	Entries  []entry
+}
+
+func setAWSEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+}
+
+// newTestServer returns a fake SQS endpoint that records SendMessageBatch
+// requests and fails every entry if fail is set.
+func newTestServer(t *testing.T, fail bool) (*httptest.Server, *[]batchRequest) {
+	t.Helper()
+
+	var requests []batchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "AmazonSQS.SendMessageBatch" {
+			t.Errorf("Expected SendMessageBatch, got %q", target)
+		}
+
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+
+		type result struct {
+			Id          string
+			MessageId   string `json:",omitempty"`
+			Code        string `json:",omitempty"`
+			Message     string `json:",omitempty"`
+			SenderFault bool   `json:",omitempty"`
+		}
+		resp := struct{ Successful, Failed []result }{Successful: []result{}, Failed: []result{}}
+		for _, e := range req.Entries {
+			if fail {
+				resp.Failed = append(resp.Failed, result{Id: e.Id, Code: "InternalError", Message: "boom"})
+			} else {
+				resp.Successful = append(resp.Successful, result{Id: e.Id, MessageId: e.Id})
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestWrite_Success(t *testing.T) {
+	setAWSEnv(t)
+	server, requests := newTestServer(t, false)
+
+	tgt, err := New(config.SQSConfig{
+		QueueURL:    server.URL + "/123456789012/infra",
+		EndpointURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(*requests) != 1 || len((*requests)[0].Entries) != 1 {
+		t.Fatalf("Expected a single message, got %+v", *requests)
+	}
+	e := (*requests)[0].Entries[0]
+	if e.MessageGroupId != "" {
+		t.Error("Expected no message group for a standard queue")
+	}
+
+	var got struct {
+		Type string         `json:"type"`
+		Data target.Payload `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(e.MessageBody), &got); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+	if got.Type != target.MessageTypePlan || len(got.Data.Plan.ResourceChanges) != 2 {
+		t.Errorf("Unexpected message: %+v", got)
+	}
+
+	if e.MessageAttributes["infralog-resources"] != (attributeValue{"Number", "2"}) {
+		t.Errorf("Unexpected resources attribute %+v", e.MessageAttributes["infralog-resources"])
+	}
+	if e.MessageAttributes["infralog-commit-sha"] != (attributeValue{"String", "abc123def456"}) {
+		t.Errorf("Unexpected commit attribute %+v", e.MessageAttributes["infralog-commit-sha"])
+	}
+}
+
+func TestWrite_FIFOPerResource(t *testing.T) {
+	setAWSEnv(t)
+	server, requests := newTestServer(t, false)
+
+	tgt, err := New(config.SQSConfig{
+		QueueURL:           server.URL + "/123456789012/infra.fifo",
+		EndpointURL:        server.URL,
+		MessagePerResource: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	entries := (*requests)[0].Entries
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(entries))
+	}
+	for i, expected := range []string{"aws_s3_bucket.data", "aws_instance.web"} {
+		e := entries[i]
+		if e.MessageAttributes["infralog-address"].StringValue != expected {
+			t.Errorf("Entry %d: expected address %s, got %+v", i, expected, e.MessageAttributes)
+		}
+		if e.MessageGroupId != "infralog" || e.MessageDeduplicationId != e.Id {
+			t.Errorf("Entry %d: unexpected FIFO fields %+v", i, e)
+		}
+	}
+}
+
+func TestWrite_Failed(t *testing.T) {
+	setAWSEnv(t)
+	server, _ := newTestServer(t, true)
+
+	tgt, err := New(config.SQSConfig{
+		QueueURL:    server.URL + "/123456789012/infra",
+		EndpointURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))); err == nil {
+		t.Error("Expected an error for failed entries")
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(config.SQSConfig{}); err == nil {
+		t.Error("Expected an error for a missing queue URL")
+	}
+}
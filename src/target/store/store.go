@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"infralog/config"
+	"infralog/store"
+	"infralog/target"
+	"strings"
+	"time"
+)
+
+const writeTimeout = 30 * time.Second
+
+type StoreTarget struct {
+	driver string
+	dsn    string
+	result string
+}
+
+func New(cfg config.StoreConfig) (*StoreTarget, error) {
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("store DSN is required")
+	}
+
+	driver := strings.ToLower(cfg.Driver)
+	switch driver {
+	case "":
+		driver = store.DetectDriver(cfg.DSN)
+	case store.DriverSQLite, store.DriverPostgres:
+	default:
+		return nil, fmt.Errorf("invalid store driver: %s. Driver must be sqlite or postgres", cfg.Driver)
+	}
+
+	return &StoreTarget{
+		driver: driver,
+		dsn:    cfg.DSN,
+	}, nil
+}
+
+// ReportsEmptyPlans reports that plans without changes are recorded too, so
+// the history has every run.
+func (t *StoreTarget) ReportsEmptyPlans() bool {
+	return true
+}
+
+// Write records the run and its resource changes, after applying pending
+// schema migrations.
+func (t *StoreTarget) Write(p *target.Payload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	s, err := store.Open(ctx, t.driver, t.dsn)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	run := newRun(p)
+	if err := s.SaveRun(ctx, run); err != nil {
+		return err
+	}
+
+	t.result = run.ID
+	return nil
+}
+
+// Result returns the ID of the run recorded by the last Write.
+func (t *StoreTarget) Result() string {
+	return t.result
+}
+
+// newRun converts a payload to a run, recorded under the payload's run ID.
+func newRun(p *target.Payload) *store.Run {
+	id := p.ID
+	if id == "" {
+		id = target.NewID()
+	}
+
+	s := target.Summarize(p.Plan)
+	run := &store.Run{
+		ID:               id,
+		Datetime:         p.Datetime,
+		TerraformVersion: p.Plan.TerraformVersion,
+		Resources:        s.Resources,
+		Added:            s.Added,
+		Changed:          s.Changed,
+		Replaced:         s.Replaced,
+		Removed:          s.Removed,
+		Outputs:          s.Outputs,
+	}
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		run.RepoURL = p.Metadata.Git.RepoURL
+		run.Branch = p.Metadata.Git.Branch
+		run.CommitSHA = p.Metadata.Git.CommitSHA
+		run.Committer = p.Metadata.Git.Committer
+	}
+
+	for _, rp := range target.SplitByResource(p) {
+		rc := rp.ResourceChange
+		run.ResourceChanges = append(run.ResourceChanges, store.ResourceChange{
+			Address:      target.ResourceAddress(rc),
+			Type:         rc.Type,
+			Module:       rc.ModuleAddress,
+			Action:       rp.Action,
+			Actions:      strings.Join(rc.Change.Actions, ","),
+			ActionReason: rc.ActionReason,
+		})
+	}
+
+	return run
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"infralog/config"
+	"infralog/store"
+	"infralog/target"
+	"infralog/target/targettest"
+	"infralog/tfplan"
+	"path/filepath"
+	"testing"
+)
+
+// storePayload returns a payload with a replaced module resource and an
+// output change.
+func storePayload() *target.Payload {
+	replace := targettest.Change("module.db.aws_db_instance.main", "delete", "create")
+	replace.ActionReason = "replace_because_cannot_update"
+
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), replace)
+	p.Plan.TerraformVersion = "1.9.0"
+	p.Plan.OutputChanges = map[string]tfplan.OutputChange{
+		"bucket_name": {Change: tfplan.Change{Actions: []string{"create"}}},
+	}
+	return p
+}
+
+func TestWrite_Success(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "infralog.db")
+
+	tgt, err := New(config.StoreConfig{DSN: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, id := range []string{"run-1", "run-2"} {
+		p := storePayload()
+		p.ID = id
+		if err := tgt.Write(p); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	s, err := store.Open(context.Background(), store.DriverSQLite, path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	runs, err := s.Runs(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Runs() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID == runs[1].ID {
+		t.Fatalf("Expected 2 runs with distinct IDs, got %+v", runs)
+	}
+	for _, run := range runs {
+		if run.ID != "run-1" && run.ID != "run-2" {
+			t.Errorf("Expected runs to be recorded under the payload ID, got %q", run.ID)
+		}
+	}
+
+	run := runs[0]
+	if tgt.Result() != "run-2" {
+		t.Errorf("Expected the last payload ID as result, got %q", tgt.Result())
+	}
+	if run.Branch != "main" || run.Committer != "Jane Doe" || run.TerraformVersion != "1.9.0" {
+		t.Errorf("Unexpected run metadata: %+v", run)
+	}
+	if run.Resources != 2 || run.Added != 1 || run.Replaced != 1 || run.Outputs != 1 {
+		t.Errorf("Unexpected run counts: %+v", run)
+	}
+
+	changes, err := s.ResourceChanges(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("ResourceChanges() error = %v", err)
+	}
+	expected := store.ResourceChange{
+		Address:      "module.db.aws_db_instance.main",
+		Type:         "aws_db_instance",
+		Module:       "module.db",
+		Action:       target.StatusReplaced,
+		Actions:      "delete,create",
+		ActionReason: "replace_because_cannot_update",
+	}
+	if len(changes) != 2 || changes[1] != expected {
+		t.Errorf("Unexpected resource changes: %+v", changes)
+	}
+}
+
+func TestWrite_Error(t *testing.T) {
+	tgt, err := New(config.StoreConfig{DSN: filepath.Join(t.TempDir(), "missing", "infralog.db")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := tgt.Write(storePayload()); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.StoreConfig{}); err == nil {
+		t.Error("Expected an error for a missing DSN")
+	}
+	if _, err := New(config.StoreConfig{Driver: "mysql", DSN: "infralog"}); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+
+	tgt, err := New(config.StoreConfig{DSN: "postgres://infralog@db:5432/infralog"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tgt.driver != store.DriverPostgres {
+		t.Errorf("Expected postgres to be detected, got %s", tgt.driver)
+	}
+}
@@ -0,0 +1,232 @@
+package syslog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transport protocols.
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+	ProtocolTLS = "tls"
+)
+
+const (
+	defaultFacility = "user"
+	defaultAppName  = "infralog"
+
+	// sdID identifies the structured data element. 32473 is the private
+	// enterprise number reserved for documentation (RFC 5612).
+	sdID = "infralog@32473"
+
+	timeout = 30 * time.Second
+)
+
+// facilities maps facility names to their codes (RFC 5424, section 6.2.1).
+var facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"ntp":      12,
+	"security": 13,
+	"console":  14,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// severities maps record severities to syslog severity codes.
+var severities = map[string]int{
+	target.SeverityInfo:    6, // informational
+	target.SeverityWarning: 4, // warning
+}
+
+type SyslogTarget struct {
+	address   string
+	protocol  string
+	facility  int
+	appName   string
+	hostname  string
+	tlsConfig *tls.Config
+}
+
+func New(cfg config.SyslogConfig) (*SyslogTarget, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+
+	protocol := strings.ToLower(cfg.Protocol)
+	switch protocol {
+	case "":
+		protocol = ProtocolUDP
+	case ProtocolUDP, ProtocolTCP, ProtocolTLS:
+	default:
+		return nil, fmt.Errorf("invalid protocol: %s. Protocol must be udp, tcp or tls", cfg.Protocol)
+	}
+
+	facilityName := strings.ToLower(cfg.Facility)
+	if facilityName == "" {
+		facilityName = defaultFacility
+	}
+	facility, ok := facilities[facilityName]
+	if !ok {
+		return nil, fmt.Errorf("invalid facility: %s", cfg.Facility)
+	}
+
+	appName := cfg.AppName
+	if appName == "" {
+		appName = defaultAppName
+	}
+	if !validHeaderField(appName, 48) {
+		return nil, fmt.Errorf("invalid app name: %q. App name must be at most 48 printable ASCII characters without spaces", appName)
+	}
+
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if !validHeaderField(hostname, 255) {
+		hostname = "-"
+	}
+
+	t := &SyslogTarget{
+		address:  cfg.Address,
+		protocol: protocol,
+		facility: facility,
+		appName:  appName,
+		hostname: hostname,
+	}
+
+	if protocol == ProtocolTLS {
+		var err error
+		if t.tlsConfig, err = tlsConfig(cfg.CAFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Write sends one message per resource change followed by a summary message.
+func (t *SyslogTarget) Write(p *target.Payload) error {
+	conn, err := t.dial()
+	if err != nil {
+		return fmt.Errorf("error connecting to syslog server %s: %w", t.address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	for _, r := range target.NewLogRecords(p) {
+		msg := t.format(r)
+
+		// Stream transports delimit messages with octet counting (RFC 6587
+		// and RFC 5425); UDP sends one message per datagram.
+		if t.protocol != ProtocolUDP {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			return fmt.Errorf("error sending to syslog server %s: %w", t.address, err)
+		}
+	}
+	return nil
+}
+
+func (t *SyslogTarget) dial() (net.Conn, error) {
+	switch t.protocol {
+	case ProtocolTLS:
+		return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", t.address, t.tlsConfig)
+	case ProtocolTCP:
+		return net.DialTimeout("tcp", t.address, timeout)
+	default:
+		return net.DialTimeout("udp", t.address, timeout)
+	}
+}
+
+// format returns the RFC 5424 message for a record:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD-ELEMENT] MSG
+func (t *SyslogTarget) format(r target.LogRecord) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d %s ",
+		t.facility*8+severities[r.Severity],
+		r.Datetime.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		t.hostname,
+		t.appName,
+		os.Getpid(),
+		r.Event,
+	)
+
+	b.WriteString("[" + sdID)
+	for _, f := range r.Fields {
+		b.WriteString(" " + f.Name + `="` + escapeParamValue(f.Value) + `"`)
+	}
+	b.WriteString("] ")
+	b.WriteString(r.Message)
+
+	return b.Bytes()
+}
+
+// escapeParamValue escapes '"', '\' and ']' in structured data parameter
+// values (RFC 5424, section 6.3.3).
+func escapeParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// validHeaderField reports whether s is a valid header field: at most max
+// printable US-ASCII characters, without spaces.
+func validHeaderField(s string, max int) bool {
+	if s == "" || len(s) > max {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+func tlsConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading syslog CA file: %w", err)
+	}
+	cfg.RootCAs = x509.NewCertPool()
+	if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in syslog CA file %s", caFile)
+	}
+	return cfg, nil
+}
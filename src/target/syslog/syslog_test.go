@@ -0,0 +1,199 @@
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/target/targettest"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFrames reads octet-counted messages from a stream connection.
+func readFrames(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+
+	var messages []string
+	for i := 0; i < n; i++ {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatalf("Failed to read message length: %v", err)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			t.Fatalf("Invalid message length %q", length)
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		messages = append(messages, string(msg))
+	}
+	return messages
+}
+
+// acceptFrames accepts a single connection and returns the first n messages
+// sent over it.
+func acceptFrames(t *testing.T, ln net.Listener, n int) <-chan []string {
+	result := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Errorf("Accept() error = %v", err)
+			result <- nil
+			return
+		}
+		defer conn.Close()
+		result <- readFrames(t, bufio.NewReader(conn), n)
+	}()
+	return result
+}
+
+func TestFormat(t *testing.T) {
+	tgt, err := New(config.SyslogConfig{Address: "localhost:514", Facility: "local0", Hostname: "ci-runner"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	records := target.NewLogRecords(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete")))
+
+	expected := fmt.Sprintf(`<132>1 2025-12-12T10:30:45.000000Z ci-runner infralog %d resource_change [infralog@32473 address="module.app.aws_instance.web" type="aws_instance" action="removed" module="module.app" branch="main" sha="abc123def456" committer="Jane Doe" repo="git@github.com:company/infrastructure.git"] module.app.aws_instance.web removed`, os.Getpid())
+	if got := string(tgt.format(records[1])); got != expected {
+		t.Errorf("Unexpected message:\n got: %s\nwant: %s", got, expected)
+	}
+
+	// local0 (16) * 8 + informational (6)
+	if got := string(tgt.format(records[0])); !strings.HasPrefix(got, "<134>1 ") {
+		t.Errorf("Expected informational priority, got %s", got)
+	}
+
+	summary := string(tgt.format(records[2]))
+	if !strings.Contains(summary, ` summary [infralog@32473 resources="2" added="1" changed="0" replaced="0" removed="1" outputs="0" branch="main" sha="abc123def456" committer="Jane Doe" repo="git@github.com:company/infrastructure.git"] Terraform plan: 2 resource(s) changed`) {
+		t.Errorf("Unexpected summary message: %s", summary)
+	}
+}
+
+func TestEscapeParamValue(t *testing.T) {
+	if got := escapeParamValue(`aws_instance.web["a]b"]\`); got != `aws_instance.web[\"a\]b\"\]\\` {
+		t.Errorf("Unexpected escaped value %s", got)
+	}
+}
+
+func TestWrite_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer pc.Close()
+
+	tgt, err := New(config.SyslogConfig{Address: pc.LocalAddr().String(), AppName: "terraform"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 65536)
+	for i, msgID := range []string{"resource_change", "resource_change", "summary"} {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("ReadFrom() error = %v", err)
+		}
+		msg := string(buf[:n])
+		// user (1) * 8 + informational (6) or warning (4)
+		if !strings.HasPrefix(msg, "<14>1 ") && !strings.HasPrefix(msg, "<12>1 ") {
+			t.Errorf("Message %d: unexpected priority: %s", i, msg)
+		}
+		if !strings.Contains(msg, " terraform ") || !strings.Contains(msg, " "+msgID+" [infralog@32473 ") {
+			t.Errorf("Message %d: expected app name and %s, got %s", i, msgID, msg)
+		}
+	}
+}
+
+func TestWrite_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	result := acceptFrames(t, ln, 3)
+
+	tgt, err := New(config.SyslogConfig{Address: ln.Addr().String(), Protocol: "tcp"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	messages := <-result
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	if !strings.Contains(messages[0], `address="aws_s3_bucket.data"`) || !strings.Contains(messages[2], " summary ") {
+		t.Errorf("Unexpected messages: %q", messages)
+	}
+}
+
+func TestWrite_TLS(t *testing.T) {
+	// Borrow the test server's certificate for 127.0.0.1.
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	result := acceptFrames(t, ln, 3)
+
+	tgt, err := New(config.SyslogConfig{Address: ln.Addr().String(), Protocol: "tls", CAFile: caFile})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := tgt.Write(targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("module.app.aws_instance.web", "delete"))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if messages := <-result; len(messages) != 3 {
+		t.Errorf("Expected 3 messages, got %d", len(messages))
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.SyslogConfig
+	}{
+		{"Missing address", config.SyslogConfig{}},
+		{"Invalid protocol", config.SyslogConfig{Address: "localhost:514", Protocol: "relp"}},
+		{"Invalid facility", config.SyslogConfig{Address: "localhost:514", Facility: "local8"}},
+		{"Invalid app name", config.SyslogConfig{Address: "localhost:514", AppName: "infra log"}},
+		{"Missing CA file", config.SyslogConfig{Address: "localhost:6514", Protocol: "tls", CAFile: "/nonexistent/ca.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
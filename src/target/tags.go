@@ -0,0 +1,59 @@
+package target
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tags returns key:value tags describing a plan, for event and annotation
+// targets: the repository and branch from git metadata, and one tag per
+// distinct Terraform action in the plan, e.g. action:delete.
+func Tags(p *Payload) []string {
+	var tags []string
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		if repo := RepoName(p.Metadata.Git.RepoURL); repo != "" {
+			tags = append(tags, "repo:"+repo)
+		}
+		if p.Metadata.Git.Branch != "" {
+			tags = append(tags, "branch:"+p.Metadata.Git.Branch)
+		}
+	}
+
+	actions := map[string]bool{}
+	for _, rc := range p.Plan.ResourceChanges {
+		for _, action := range rc.Change.Actions {
+			if action != "no-op" && action != "read" {
+				actions[action] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(actions))
+	for action := range actions {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+	for _, action := range names {
+		tags = append(tags, "action:"+action)
+	}
+
+	return tags
+}
+
+// RepoName returns the path of a repository URL without the host and the .git
+// suffix, e.g. company/infrastructure for git@github.com:company/infrastructure.git.
+func RepoName(url string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	if i := strings.Index(name, "://"); i >= 0 {
+		// scheme://[user@]host/path
+		name = name[i+3:]
+		if j := strings.Index(name, "/"); j >= 0 {
+			return name[j+1:]
+		}
+		return ""
+	}
+	// scp-like [user@]host:path
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
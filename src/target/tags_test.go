@@ -0,0 +1,42 @@
+package target
+
+import (
+	"infralog/tfplan"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	payload := markdownTestPayload(1)
+	payload.Metadata.Git.RepoURL = "git@github.com:company/infrastructure.git"
+	payload.Plan.ResourceChanges = append(payload.Plan.ResourceChanges,
+		tfplan.ResourceChange{Address: "aws_instance.db", Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+		tfplan.ResourceChange{Address: "data.aws_ami.ubuntu", Change: tfplan.Change{Actions: []string{"read"}}},
+	)
+
+	expected := "repo:company/infrastructure,branch:main,action:create,action:delete,action:update"
+	if got := strings.Join(Tags(payload), ","); got != expected {
+		t.Errorf("Expected tags %s, got %s", expected, got)
+	}
+
+	payload.Metadata = nil
+	if got := strings.Join(Tags(payload), ","); got != "action:create,action:delete,action:update" {
+		t.Errorf("Expected only action tags without git metadata, got %s", got)
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:company/infrastructure.git":                "company/infrastructure",
+		"https://github.com/company/infrastructure.git":            "company/infrastructure",
+		"https://gitlab.example.com/group/sub/infrastructure":      "group/sub/infrastructure",
+		"ssh://git@bitbucket.example.com:7999/infra/terraform.git": "infra/terraform",
+		"https://github.com": "",
+		"":                   "",
+	}
+	for url, expected := range tests {
+		if got := RepoName(url); got != expected {
+			t.Errorf("RepoName(%q) = %q, want %q", url, got, expected)
+		}
+	}
+}
@@ -11,8 +11,27 @@ type Target interface {
 	Write(*Payload) error
 }
 
+// EmptyPlanTarget is implemented by targets that must also be notified when
+// a plan has no changes, such as status checks that report a result for every
+// commit.
+type EmptyPlanTarget interface {
+	Target
+	ReportsEmptyPlans() bool
+}
+
+// Reporter is implemented by targets that produce a result worth showing in
+// the CLI output, such as the number of a ticket they created. Result returns
+// an empty string if there is nothing to report.
+type Reporter interface {
+	Target
+	Result() string
+}
+
 // Payload contains the change data sent to targets.
 type Payload struct {
+	// ID identifies the run, so the notifications sent by every target for
+	// the same plan can be correlated.
+	ID       string           `json:"id,omitempty"`
 	Plan     *tfplan.Plan     `json:"plan"`
 	Datetime time.Time        `json:"datetime"`
 	Metadata *PayloadMetadata `json:"metadata,omitempty"`
@@ -23,9 +42,11 @@ type PayloadMetadata struct {
 	Git *git.Metadata `json:"git,omitempty"`
 }
 
-// NewPayload creates a new Payload with the current timestamp and metadata.
+// NewPayload creates a new Payload with a new run ID, the current timestamp
+// and metadata.
 func NewPayload(plan *tfplan.Plan) *Payload {
 	return &Payload{
+		ID:       NewID(),
 		Plan:     plan,
 		Datetime: time.Now().UTC(),
 		Metadata: extractMetadata(),
@@ -0,0 +1,56 @@
+// Package targettest provides the payloads used by target tests.
+package targettest
+
+import (
+	"infralog/git"
+	"infralog/target"
+	"infralog/tfplan"
+	"strings"
+	"time"
+)
+
+// Datetime is the time of payloads returned by Payload.
+var Datetime = time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC)
+
+// Payload returns a payload for a plan with the given resource changes. The
+// time and git metadata are fixed, so tests can assert on rendered output.
+func Payload(changes ...tfplan.ResourceChange) *target.Payload {
+	return &target.Payload{
+		Plan:     &tfplan.Plan{ResourceChanges: changes},
+		Datetime: Datetime,
+		Metadata: &target.PayloadMetadata{
+			Git: &git.Metadata{
+				Branch:    "main",
+				CommitSHA: "abc123def456",
+				Committer: "Jane Doe",
+				RepoURL:   "git@github.com:company/infrastructure.git",
+			},
+		},
+	}
+}
+
+// Change returns a change with the given actions to the resource at address,
+// e.g. "module.db.aws_db_instance.main". The resource type and module address
+// are derived from the address.
+func Change(address string, actions ...string) tfplan.ResourceChange {
+	rc := tfplan.ResourceChange{
+		Address: address,
+		Change:  tfplan.Change{Actions: actions},
+	}
+
+	parts := strings.Split(address, ".")
+	i := 0
+	for i+1 < len(parts) && parts[i] == "module" {
+		i += 2
+	}
+	if i > 0 {
+		rc.ModuleAddress = strings.Join(parts[:i], ".")
+	}
+	if i < len(parts) && parts[i] == "data" {
+		i++
+	}
+	if i < len(parts) {
+		rc.Type = parts[i]
+	}
+	return rc
+}
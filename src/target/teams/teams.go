@@ -0,0 +1,379 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTitle = "Terraform Plan Changes"
+
+	// Teams rejects webhook messages larger than 28 KB.
+	maxPayloadSize = 28 * 1024
+
+	// Maximum number of changed attributes listed per resource.
+	maxAttributes = 5
+)
+
+type TeamsTarget struct {
+	webhookURL string
+	title      string
+}
+
+type teamsMessage struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string       `json:"contentType"`
+	ContentURL  *string      `json:"contentUrl"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string          `json:"$schema"`
+	Type    string          `json:"type"`
+	Version string          `json:"version"`
+	Body    []element       `json:"body"`
+	MSTeams *msTeamsOptions `json:"msteams,omitempty"`
+}
+
+type msTeamsOptions struct {
+	Width string `json:"width"`
+}
+
+// element is a minimal Adaptive Card element covering TextBlock, FactSet and Container.
+type element struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text,omitempty"`
+	Size      string    `json:"size,omitempty"`
+	Weight    string    `json:"weight,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	Wrap      bool      `json:"wrap,omitempty"`
+	IsSubtle  bool      `json:"isSubtle,omitempty"`
+	Spacing   string    `json:"spacing,omitempty"`
+	Separator bool      `json:"separator,omitempty"`
+	Facts     []fact    `json:"facts,omitempty"`
+	Items     []element `json:"items,omitempty"`
+}
+
+type fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// renderOptions controls how much detail is included in the card.
+type renderOptions struct {
+	maxResources   int
+	maxOutputs     int
+	showAttributes bool
+}
+
+func New(cfg config.TeamsConfig) (*TeamsTarget, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("teams webhook URL is required")
+	}
+
+	title := cfg.Title
+	if title == "" {
+		title = defaultTitle
+	}
+
+	return &TeamsTarget{
+		webhookURL: cfg.WebhookURL,
+		title:      title,
+	}, nil
+}
+
+func (t *TeamsTarget) Write(p *target.Payload) error {
+	jsonData, err := t.marshalMessage(p)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(t.webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending teams message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("teams request failed with status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// marshalMessage renders the card, dropping detail until it fits within the
+// Teams payload size limit: attribute diffs go first, then resources, then
+// outputs, down to a card with only the summary and counts.
+func (t *TeamsTarget) marshalMessage(p *target.Payload) ([]byte, error) {
+	opts := renderOptions{
+		maxResources:   len(p.Plan.ResourceChanges),
+		maxOutputs:     len(p.Plan.OutputChanges),
+		showAttributes: true,
+	}
+
+	for {
+		jsonData, err := json.Marshal(t.buildMessage(p, opts))
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling teams message: %w", err)
+		}
+
+		if len(jsonData) <= maxPayloadSize {
+			return jsonData, nil
+		}
+
+		switch {
+		case opts.showAttributes:
+			opts.showAttributes = false
+		case opts.maxResources > 0:
+			opts.maxResources /= 2
+		case opts.maxOutputs > 0:
+			opts.maxOutputs /= 2
+		default:
+			// Nothing left to drop; Teams reports the error.
+			return jsonData, nil
+		}
+	}
+}
+
+func (t *TeamsTarget) buildMessage(p *target.Payload, opts renderOptions) teamsMessage {
+	var body []element
+
+	// Header
+	body = append(body, element{
+		Type:   "TextBlock",
+		Text:   t.title,
+		Size:   "Large",
+		Weight: "Bolder",
+		Wrap:   true,
+	})
+	body = append(body, element{
+		Type:     "TextBlock",
+		Text:     target.Summarize(p.Plan).Text(),
+		IsSubtle: true,
+		Spacing:  "None",
+		Wrap:     true,
+	})
+
+	// Context - timestamp and git metadata
+	body = append(body, element{
+		Type:  "FactSet",
+		Facts: buildContextFacts(p),
+	})
+
+	// Resource changes
+	if len(p.Plan.ResourceChanges) > 0 {
+		body = append(body, buildResourceChanges(p.Plan.ResourceChanges, opts)...)
+	}
+
+	// Output changes
+	if len(p.Plan.OutputChanges) > 0 {
+		body = append(body, buildOutputChanges(p.Plan.OutputChanges, opts)...)
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []attachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: adaptiveCard{
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:    "AdaptiveCard",
+					Version: "1.4",
+					Body:    body,
+					MSTeams: &msTeamsOptions{Width: "Full"},
+				},
+			},
+		},
+	}
+}
+
+func buildContextFacts(p *target.Payload) []fact {
+	facts := []fact{
+		{Title: "Time", Value: p.Datetime.Format("2006-01-02 15:04:05 UTC")},
+	}
+
+	if p.Metadata == nil || p.Metadata.Git == nil {
+		return facts
+	}
+
+	git := p.Metadata.Git
+	if git.Committer != "" {
+		facts = append(facts, fact{Title: "Committer", Value: git.Committer})
+	}
+	if git.Branch != "" {
+		facts = append(facts, fact{Title: "Branch", Value: git.Branch})
+	}
+	if git.CommitSHA != "" {
+		facts = append(facts, fact{Title: "Commit", Value: target.ShortSHA(git.CommitSHA)})
+	}
+	if git.RepoURL != "" {
+		facts = append(facts, fact{Title: "Repository", Value: git.RepoURL})
+	}
+
+	return facts
+}
+
+func buildResourceChanges(changes []tfplan.ResourceChange, opts renderOptions) []element {
+	elements := []element{
+		{
+			Type:      "TextBlock",
+			Text:      "Resource Changes",
+			Size:      "Medium",
+			Weight:    "Bolder",
+			Separator: true,
+			Wrap:      true,
+		},
+	}
+
+	groups := target.GroupByStatus(changes)
+	shown := 0
+	for _, status := range target.StatusOrder {
+		group := groups[status]
+		if len(group) == 0 || shown >= opts.maxResources {
+			continue
+		}
+
+		elements = append(elements, element{
+			Type:   "TextBlock",
+			Text:   fmt.Sprintf("%s (%d)", strings.ToUpper(status[:1])+status[1:], len(group)),
+			Weight: "Bolder",
+			Color:  statusColor(status),
+			Wrap:   true,
+		})
+
+		var items []element
+		for _, rc := range group {
+			if shown >= opts.maxResources {
+				break
+			}
+			items = append(items, element{
+				Type: "TextBlock",
+				Text: target.ResourceAddress(rc),
+				Wrap: true,
+			})
+			if opts.showAttributes && (status == target.StatusChanged || status == target.StatusReplaced) {
+				if text := formatAttributeChanges(rc); text != "" {
+					items = append(items, element{
+						Type:     "TextBlock",
+						Text:     text,
+						IsSubtle: true,
+						Spacing:  "None",
+						Wrap:     true,
+					})
+				}
+			}
+			shown++
+		}
+
+		elements = append(elements, element{
+			Type:    "Container",
+			Spacing: "Small",
+			Items:   items,
+		})
+	}
+
+	if hidden := len(changes) - shown; hidden > 0 {
+		elements = append(elements, element{
+			Type:     "TextBlock",
+			Text:     fmt.Sprintf("_...and %d more resource change(s) not shown_", hidden),
+			IsSubtle: true,
+			Wrap:     true,
+		})
+	}
+
+	return elements
+}
+
+// formatAttributeChanges renders the changed attributes of a resource as a markdown list.
+func formatAttributeChanges(rc tfplan.ResourceChange) string {
+	changes := target.ExtractChanges(rc.Change)
+	attrs := target.SortedAttributes(changes)
+
+	var lines []string
+	for i, attr := range attrs {
+		// Limit to first 5 changed attributes to keep the card readable
+		if i >= maxAttributes {
+			lines = append(lines, fmt.Sprintf("- _...and %d more attributes_", len(attrs)-maxAttributes))
+			break
+		}
+		change := changes[attr]
+		lines = append(lines, fmt.Sprintf("- **%s**: %v → %v", attr, change.Before, change.After))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func buildOutputChanges(changes map[string]tfplan.OutputChange, opts renderOptions) []element {
+	elements := []element{
+		{
+			Type:      "TextBlock",
+			Text:      "Output Changes",
+			Size:      "Medium",
+			Weight:    "Bolder",
+			Separator: true,
+			Wrap:      true,
+		},
+	}
+
+	// Sort output names for consistent ordering
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	shown := min(len(names), opts.maxOutputs)
+	facts := make([]fact, 0, shown)
+	for _, name := range names[:shown] {
+		oc := changes[name]
+		status := target.ActionsToStatus(oc.Change.Actions)
+		value := status
+		if status == target.StatusChanged || status == target.StatusReplaced {
+			value = fmt.Sprintf("%s: %v → %v", status, oc.Change.Before, oc.Change.After)
+		}
+		facts = append(facts, fact{Title: name, Value: value})
+	}
+
+	if len(facts) > 0 {
+		elements = append(elements, element{
+			Type:  "FactSet",
+			Facts: facts,
+		})
+	}
+
+	if hidden := len(names) - shown; hidden > 0 {
+		elements = append(elements, element{
+			Type:     "TextBlock",
+			Text:     fmt.Sprintf("_...and %d more output change(s) not shown_", hidden),
+			IsSubtle: true,
+			Wrap:     true,
+		})
+	}
+
+	return elements
+}
+
+// statusColor maps a change status to an Adaptive Card text color.
+func statusColor(status string) string {
+	switch status {
+	case target.StatusAdded:
+		return "Good"
+	case target.StatusRemoved:
+		return "Attention"
+	case target.StatusChanged, target.StatusReplaced:
+		return "Warning"
+	default:
+		return "Default"
+	}
+}
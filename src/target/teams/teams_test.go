@@ -0,0 +1,324 @@
+package teams
+
+import (
+	"encoding/json"
+	"fmt"
+	"infralog/config"
+	"infralog/target"
+	"infralog/tfplan"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.TeamsConfig
+		expectError bool
+	}{
+		{
+			name:        "Valid config with webhook URL",
+			cfg:         config.TeamsConfig{WebhookURL: "https://example.webhook.office.com/webhookb2/xxx"},
+			expectError: false,
+		},
+		{
+			name:        "Empty webhook URL",
+			cfg:         config.TeamsConfig{WebhookURL: ""},
+			expectError: true,
+		},
+		{
+			name: "Config with title",
+			cfg: config.TeamsConfig{
+				WebhookURL: "https://example.webhook.office.com/webhookb2/xxx",
+				Title:      "Production plan",
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := New(tt.cfg)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				if target != nil {
+					t.Error("Expected nil target but got a value")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if target == nil {
+					t.Error("Expected non-nil target but got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_Success(t *testing.T) {
+	var receivedBody teamsMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected application/json content type, got %s", r.Header.Get("Content-Type"))
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&receivedBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		// Workflows webhooks answer with 202 Accepted
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	teamsTarget, err := New(config.TeamsConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create teams target: %v", err)
+	}
+
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{
+				Type: "aws_instance",
+				Name: "web",
+				Change: tfplan.Change{
+					Actions: []string{"update"},
+					Before: map[string]interface{}{
+						"instance_type": "t2.micro",
+					},
+					After: map[string]interface{}{
+						"instance_type": "t2.small",
+					},
+				},
+			},
+		},
+	}
+	payload := target.NewPayload(plan)
+	if err := teamsTarget.Write(payload); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	if receivedBody.Type != "message" {
+		t.Errorf("Expected message type, got %s", receivedBody.Type)
+	}
+	if len(receivedBody.Attachments) != 1 {
+		t.Fatalf("Expected 1 attachment, got %d", len(receivedBody.Attachments))
+	}
+	card := receivedBody.Attachments[0]
+	if card.ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("Expected adaptive card content type, got %s", card.ContentType)
+	}
+	if card.Content.Type != "AdaptiveCard" {
+		t.Errorf("Expected AdaptiveCard, got %s", card.Content.Type)
+	}
+	if len(card.Content.Body) == 0 {
+		t.Fatal("Expected card body elements")
+	}
+	if card.Content.Body[0].Text != defaultTitle {
+		t.Errorf("Expected title %q, got %q", defaultTitle, card.Content.Body[0].Text)
+	}
+}
+
+func TestWrite_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	teamsTarget, err := New(config.TeamsConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create teams target: %v", err)
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{})
+	err = teamsTarget.Write(payload)
+	if err == nil {
+		t.Error("Expected an error but got none")
+	}
+}
+
+func TestBuildMessage_GroupsByStatus(t *testing.T) {
+	teamsTarget := &TeamsTarget{title: "Plan"}
+
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_security_group.old", Change: tfplan.Change{Actions: []string{"delete"}}},
+			{Address: "aws_s3_bucket.data", Change: tfplan.Change{Actions: []string{"create"}}},
+			{
+				Address: "aws_instance.web",
+				Change: tfplan.Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "t2.micro"},
+					After:   map[string]interface{}{"instance_type": "t2.small"},
+				},
+			},
+		},
+	}
+	payload := &target.Payload{Plan: plan, Datetime: time.Now().UTC()}
+
+	msg := teamsTarget.buildMessage(payload, renderOptions{maxResources: 3, showAttributes: true})
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	result := string(data)
+
+	added := strings.Index(result, "Added (1)")
+	changed := strings.Index(result, "Changed (1)")
+	removed := strings.Index(result, "Removed (1)")
+	if added < 0 || changed < 0 || removed < 0 {
+		t.Fatalf("Expected all status groups in card, got %s", result)
+	}
+	if !(added < changed && changed < removed) {
+		t.Error("Expected groups ordered as added, changed, removed")
+	}
+	if !strings.Contains(result, "instance_type") {
+		t.Error("Expected attribute diff for updated resource")
+	}
+}
+
+func TestWrite_TruncatesLargePlans(t *testing.T) {
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	teamsTarget, err := New(config.TeamsConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create teams target: %v", err)
+	}
+
+	var changes []tfplan.ResourceChange
+	for i := 0; i < 1000; i++ {
+		changes = append(changes, tfplan.ResourceChange{
+			Address: fmt.Sprintf("aws_instance.server_with_a_long_name_%d", i),
+			Change: tfplan.Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"instance_type": "t2.micro"},
+				After:   map[string]interface{}{"instance_type": "t2.small"},
+			},
+		})
+	}
+
+	payload := target.NewPayload(&tfplan.Plan{ResourceChanges: changes})
+	if err := teamsTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(receivedBody) > maxPayloadSize {
+		t.Errorf("Expected payload within %d bytes, got %d", maxPayloadSize, len(receivedBody))
+	}
+	if !strings.Contains(string(receivedBody), "more resource change(s) not shown") {
+		t.Error("Expected truncation notice in card")
+	}
+}
+
+func TestWrite_TruncatesLargeOutputs(t *testing.T) {
+	var receivedBody []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	teamsTarget, err := New(config.TeamsConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create teams target: %v", err)
+	}
+
+	outputs := map[string]tfplan.OutputChange{}
+	for i := 0; i < 20; i++ {
+		outputs[fmt.Sprintf("output_%02d", i)] = tfplan.OutputChange{
+			Change: tfplan.Change{Actions: []string{"update"}, Before: map[string]interface{}{"value": strings.Repeat("a", 2048)}, After: map[string]interface{}{"value": strings.Repeat("b", 2048)}},
+		}
+	}
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+		OutputChanges: outputs,
+	})
+	if err := teamsTarget.Write(payload); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(receivedBody) > maxPayloadSize {
+		t.Errorf("Expected payload within %d bytes, got %d", maxPayloadSize, len(receivedBody))
+	}
+	if !strings.Contains(string(receivedBody), "more output change(s) not shown") {
+		t.Error("Expected output truncation notice in card")
+	}
+	if !strings.Contains(string(receivedBody), "output_00") {
+		t.Error("Expected the first outputs to remain in the card")
+	}
+}
+
+func TestMarshalMessage_SummaryOnly(t *testing.T) {
+	teamsTarget, err := New(config.TeamsConfig{WebhookURL: "https://example.com/webhook"})
+	if err != nil {
+		t.Fatalf("Failed to create teams target: %v", err)
+	}
+
+	// A single output too large to fit leaves only the summary and counts.
+	payload := target.NewPayload(&tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_instance.web", Change: tfplan.Change{Actions: []string{"create"}}},
+		},
+		OutputChanges: map[string]tfplan.OutputChange{
+			"certificate": {Change: tfplan.Change{Actions: []string{"update"}, After: map[string]interface{}{"pem": strings.Repeat("x", maxPayloadSize)}}},
+		},
+	})
+	jsonData, err := teamsTarget.marshalMessage(payload)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	body := string(jsonData)
+	if len(jsonData) > maxPayloadSize {
+		t.Errorf("Expected payload within %d bytes, got %d", maxPayloadSize, len(jsonData))
+	}
+	for _, s := range []string{"1 resource(s), 1 output(s) changed", "1 more resource change(s) not shown", "1 more output change(s) not shown"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("Expected summary-only card to contain %q", s)
+		}
+	}
+	if strings.Contains(body, "aws_instance.web") || strings.Contains(body, "xxxx") {
+		t.Error("Expected resources and outputs to be dropped")
+	}
+}
+
+func TestStatusColor(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{"added", "Good"},
+		{"removed", "Attention"},
+		{"changed", "Warning"},
+		{"replaced", "Warning"},
+		{"unknown", "Default"},
+	}
+
+	for _, tt := range tests {
+		result := statusColor(tt.status)
+		if result != tt.expected {
+			t.Errorf("statusColor(%s) = %s, expected %s", tt.status, result, tt.expected)
+		}
+	}
+}
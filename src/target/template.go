@@ -0,0 +1,178 @@
+package target
+
+import (
+	"fmt"
+	"infralog/git"
+	"infralog/tfplan"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// TemplateData is the data available to user-provided templates, such as
+// thread keys, subjects or file paths.
+type TemplateData struct {
+	Plan     *tfplan.Plan
+	Datetime TemplateTime
+	Git      git.Metadata
+	Summary  Summary
+}
+
+// TemplateTime is a time that renders in a compact form safe for use in
+// paths and keys (e.g. 20251212T103045Z). Time methods such as Format
+// remain available to templates.
+type TemplateTime struct {
+	time.Time
+}
+
+func (t TemplateTime) String() string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// NewTemplateData builds the template data for a payload. Git fields are
+// empty strings when no git metadata is available.
+func NewTemplateData(p *Payload) TemplateData {
+	data := TemplateData{
+		Plan:     p.Plan,
+		Datetime: TemplateTime{p.Datetime},
+		Summary:  Summarize(p.Plan),
+	}
+
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		data.Git = *p.Metadata.Git
+	}
+
+	return data
+}
+
+// ParseTemplate parses a user-provided template. Field references are
+// checked against TemplateData, so that unknown fields are reported when the
+// target is created rather than on the first write. Other rendering errors,
+// such as indexing into an empty list, depend on the plan and are left to
+// ExecuteTemplate.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	if tmpl.Tree != nil {
+		c := fieldChecker{tree: tmpl.Tree, root: reflect.TypeOf(TemplateData{})}
+		if err := c.check(tmpl.Tree.Root, c.root); err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// fieldChecker walks a parsed template and resolves field chains against the
+// type of dot. Inside range and with blocks dot depends on the data, so
+// fields relative to dot are only checked again through $.
+type fieldChecker struct {
+	tree *parse.Tree
+	root reflect.Type
+}
+
+// check checks the fields referenced by node. A nil dot means its type isn't
+// known statically.
+func (c fieldChecker) check(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := c.check(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return c.check(n.Pipe, dot)
+	case *parse.TemplateNode:
+		return c.check(n.Pipe, dot)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := c.check(arg, dot); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.IfNode:
+		return c.checkBranch(&n.BranchNode, dot, dot)
+	case *parse.RangeNode:
+		return c.checkBranch(&n.BranchNode, dot, nil)
+	case *parse.WithNode:
+		return c.checkBranch(&n.BranchNode, dot, nil)
+	case *parse.ChainNode:
+		return c.check(n.Node, dot)
+	case *parse.FieldNode:
+		return c.checkFields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			return c.checkFields(n, c.root, n.Ident[1:])
+		}
+	}
+	return nil
+}
+
+// checkBranch checks an if, range or with block. The pipeline and the else
+// branch are evaluated with the outer dot, the body with inner.
+func (c fieldChecker) checkBranch(n *parse.BranchNode, dot, inner reflect.Type) error {
+	if err := c.check(n.Pipe, dot); err != nil {
+		return err
+	}
+	if err := c.check(n.List, inner); err != nil {
+		return err
+	}
+	return c.check(n.ElseList, dot)
+}
+
+// checkFields resolves a chain of field or method names starting at typ.
+// Maps and interfaces can't be checked statically, so resolution stops there.
+func (c fieldChecker) checkFields(node parse.Node, typ reflect.Type, names []string) error {
+	for _, name := range names {
+		if typ == nil {
+			return nil
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if method, ok := reflect.PointerTo(typ).MethodByName(name); ok {
+			if method.Type.NumOut() == 0 {
+				return nil
+			}
+			typ = method.Type.Out(0)
+			continue
+		}
+
+		switch typ.Kind() {
+		case reflect.Map, reflect.Interface:
+			return nil
+		case reflect.Struct:
+			if field, ok := typ.FieldByName(name); ok && field.IsExported() {
+				typ = field.Type
+				continue
+			}
+		}
+
+		location, _ := c.tree.ErrorContext(node)
+		return fmt.Errorf("%s: can't evaluate field %s in type %s", location, name, typ)
+	}
+	return nil
+}
+
+// ExecuteTemplate renders a template parsed with ParseTemplate for a payload.
+func ExecuteTemplate(tmpl *template.Template, p *Payload) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, NewTemplateData(p)); err != nil {
+		return "", fmt.Errorf("error rendering %s template: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
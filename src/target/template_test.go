@@ -0,0 +1,115 @@
+package target
+
+import (
+	"infralog/git"
+	"infralog/tfplan"
+	"testing"
+	"time"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	payload := &Payload{
+		Plan: &tfplan.Plan{
+			ResourceChanges: []tfplan.ResourceChange{
+				{Change: tfplan.Change{Actions: []string{"create"}}},
+				{Change: tfplan.Change{Actions: []string{"delete"}}},
+				{Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+			},
+		},
+		Datetime: time.Date(2025, 12, 12, 10, 30, 45, 0, time.UTC),
+		Metadata: &PayloadMetadata{
+			Git: &git.Metadata{Branch: "main", CommitSHA: "abc123def456"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"Git fields", "{{.Git.Branch}}@{{.Git.CommitSHA}}", "main@abc123def456"},
+		{"Summary counts", "[infralog] {{.Summary.Resources}} changes on {{.Git.Branch}}", "[infralog] 3 changes on main"},
+		{"Compact datetime", "reports/{{.Datetime}}.json", "reports/20251212T103045Z.json"},
+		{"Datetime format", `{{.Datetime.Format "2006-01-02"}}`, "2025-12-12"},
+		{"Destructive changes", "{{if .Summary.HasDestructiveChanges}}destroy{{end}}", "destroy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate("test", tt.template)
+			if err != nil {
+				t.Fatalf("ParseTemplate() error = %v", err)
+			}
+			result, err := ExecuteTemplate(tmpl, payload)
+			if err != nil {
+				t.Fatalf("ExecuteTemplate() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ExecuteTemplate() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteTemplate_NoGitMetadata(t *testing.T) {
+	tmpl, err := ParseTemplate("test", "{{.Git.Branch}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	result, err := ExecuteTemplate(tmpl, &Payload{Plan: &tfplan.Plan{}})
+	if err != nil {
+		t.Fatalf("ExecuteTemplate() error = %v", err)
+	}
+	if result != "" {
+		t.Errorf("Expected empty result without git metadata, got %q", result)
+	}
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	if _, err := ParseTemplate("test", "{{.Git.Branch"); err == nil {
+		t.Error("Expected an error for an unterminated action")
+	}
+
+	for _, text := range []string{"{{.Unknown}}", "{{.Git.Brnach}}", "{{.Summary.Added.Foo}}"} {
+		if _, err := ParseTemplate("test", text); err == nil {
+			t.Errorf("Expected an error for unknown field in %q", text)
+		}
+	}
+}
+
+func TestParseTemplate_PlanDependent(t *testing.T) {
+	// Errors that depend on the plan are only reported when rendering.
+	tmpl, err := ParseTemplate("test", "{{(index .Plan.ResourceChanges 0).Address}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	if _, err := ExecuteTemplate(tmpl, &Payload{Plan: &tfplan.Plan{}}); err == nil {
+		t.Error("Expected an error indexing an empty plan")
+	}
+}
+
+func TestParseTemplate_Fields(t *testing.T) {
+	for _, text := range []string{
+		"{{.Git.Branch}}",
+		"{{.Datetime.Format \"2006-01-02\"}}",
+		"{{.Datetime}}-{{.Summary.Text}}",
+		"{{if .Git.Branch}}{{.Git.Branch}}{{else}}{{.Git.CommitSHA}}{{end}}",
+		"{{range .Plan.ResourceChanges}}{{.Address}}{{$.Git.Branch}}{{end}}",
+		"{{with .Git}}{{.Branch}}{{end}}",
+	} {
+		if _, err := ParseTemplate("test", text); err != nil {
+			t.Errorf("ParseTemplate(%q) error = %v", text, err)
+		}
+	}
+
+	for _, text := range []string{
+		"{{range .Plan.ResourceChanges}}{{$.Git.Brnach}}{{end}}",
+		"{{if .Git.Branch}}{{else}}{{.Unknown}}{{end}}",
+		"{{printf \"%s\" .Datetime.Foo}}",
+	} {
+		if _, err := ParseTemplate("test", text); err == nil {
+			t.Errorf("Expected an error for unknown field in %q", text)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package target
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"infralog/config"
+	"infralog/tfplan"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// triggerActions maps change statuses to the action names used in trigger configuration.
+var triggerActions = map[string]string{
+	StatusAdded:    "create",
+	StatusChanged:  "update",
+	StatusReplaced: "replace",
+	StatusRemoved:  "delete",
+}
+
+// ValidateTrigger checks the trigger actions and address patterns, so that
+// typos are reported when the target is created instead of silently never
+// matching.
+func ValidateTrigger(trigger config.TriggerConfig) error {
+	for _, action := range trigger.WithDefaults().Actions {
+		if !slices.Contains([]string{"create", "update", "replace", "delete"}, action) {
+			return fmt.Errorf("invalid trigger action: %s. Action must be create, update, replace or delete", action)
+		}
+	}
+	for _, pattern := range trigger.Addresses {
+		if _, err := path.Match(addressPattern(pattern), ""); err != nil {
+			return fmt.Errorf("invalid trigger address pattern: %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// TriggeringChanges returns the resource changes that match the trigger
+// conditions. Defaults are applied to the trigger before matching.
+func TriggeringChanges(plan *tfplan.Plan, trigger config.TriggerConfig) []tfplan.ResourceChange {
+	trigger = trigger.WithDefaults()
+
+	var matched []tfplan.ResourceChange
+	for _, rc := range plan.ResourceChanges {
+		action := triggerActions[ActionsToStatus(rc.Change.Actions)]
+		if !slices.Contains(trigger.Actions, action) {
+			continue
+		}
+		if len(trigger.ResourceTypes) > 0 && !slices.Contains(trigger.ResourceTypes, rc.Type) {
+			continue
+		}
+		if len(trigger.Addresses) > 0 && !matchesAnyAddress(ResourceAddress(rc), trigger.Addresses) {
+			continue
+		}
+		matched = append(matched, rc)
+	}
+
+	return matched
+}
+
+// MostSevereStatus returns the most severe status among the changes, ordered
+// removed, replaced, changed, added. Returns StatusUnknown for no changes.
+func MostSevereStatus(changes []tfplan.ResourceChange) string {
+	severity := map[string]int{
+		StatusAdded:    1,
+		StatusChanged:  2,
+		StatusReplaced: 3,
+		StatusRemoved:  4,
+	}
+
+	result := StatusUnknown
+	for _, rc := range changes {
+		status := ActionsToStatus(rc.Change.Actions)
+		if severity[status] > severity[result] {
+			result = status
+		}
+	}
+	return result
+}
+
+// DedupKey derives a stable key identifying an alert for the given changes,
+// so that repeated runs for the same repository, branch, commit and set of
+// changes update the existing alert instead of opening a new one.
+func DedupKey(p *Payload, changes []tfplan.ResourceChange) string {
+	var parts []string
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		git := p.Metadata.Git
+		parts = append(parts, git.RepoURL, git.Branch, git.CommitSHA)
+	}
+
+	var resources []string
+	for _, rc := range changes {
+		resources = append(resources, ResourceAddress(rc)+":"+ActionsToStatus(rc.Change.Actions))
+	}
+	sort.Strings(resources)
+	parts = append(parts, resources...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return "infralog-" + hex.EncodeToString(sum[:])
+}
+
+func matchesAnyAddress(address string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(addressPattern(pattern), address); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addressPattern escapes the brackets of a resource address glob, so that
+// instance keys such as aws_instance.web[0] or aws_instance.web["a"] match
+// literally instead of as character classes. * and ? remain wildcards.
+func addressPattern(pattern string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(pattern)
+}
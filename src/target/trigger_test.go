@@ -0,0 +1,141 @@
+package target
+
+import (
+	"infralog/config"
+	"infralog/git"
+	"infralog/tfplan"
+	"testing"
+)
+
+func TestTriggeringChanges(t *testing.T) {
+	plan := &tfplan.Plan{
+		ResourceChanges: []tfplan.ResourceChange{
+			{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: tfplan.Change{Actions: []string{"create"}}},
+			{Address: "aws_instance.web", Type: "aws_instance", Change: tfplan.Change{Actions: []string{"update"}}},
+			{Address: "module.prod.aws_db_instance.main", Type: "aws_db_instance", Change: tfplan.Change{Actions: []string{"delete"}}},
+			{Address: "module.dev.aws_db_instance.main", Type: "aws_db_instance", Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+			{Address: "aws_instance.db[0]", Type: "aws_instance", Change: tfplan.Change{Actions: []string{"delete"}}},
+			{Address: `module.prod["eu"].aws_instance.db[1]`, Type: "aws_instance", Change: tfplan.Change{Actions: []string{"delete"}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		trigger  config.TriggerConfig
+		expected []string
+	}{
+		{
+			name:     "Defaults to deletes and replacements",
+			trigger:  config.TriggerConfig{},
+			expected: []string{"module.prod.aws_db_instance.main", "module.dev.aws_db_instance.main", "aws_instance.db[0]", `module.prod["eu"].aws_instance.db[1]`},
+		},
+		{
+			name:     "Custom actions",
+			trigger:  config.TriggerConfig{Actions: []string{"create", "update"}},
+			expected: []string{"aws_s3_bucket.logs", "aws_instance.web"},
+		},
+		{
+			name:     "Resource types",
+			trigger:  config.TriggerConfig{Actions: []string{"create", "update"}, ResourceTypes: []string{"aws_instance"}},
+			expected: []string{"aws_instance.web"},
+		},
+		{
+			name:     "Address patterns",
+			trigger:  config.TriggerConfig{Addresses: []string{"module.prod.*"}},
+			expected: []string{"module.prod.aws_db_instance.main"},
+		},
+		{
+			name:     "Indexed addresses match literally",
+			trigger:  config.TriggerConfig{Addresses: []string{"aws_instance.db[0]", `module.prod["eu"].*`}},
+			expected: []string{"aws_instance.db[0]", `module.prod["eu"].aws_instance.db[1]`},
+		},
+		{
+			name:     "Empty actions never trigger",
+			trigger:  config.TriggerConfig{Actions: []string{}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := TriggeringChanges(plan, tt.trigger)
+			if len(matched) != len(tt.expected) {
+				t.Fatalf("Expected %d matches, got %d", len(tt.expected), len(matched))
+			}
+			for i, rc := range matched {
+				if rc.Address != tt.expected[i] {
+					t.Errorf("Match %d = %s, expected %s", i, rc.Address, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTrigger(t *testing.T) {
+	tests := []struct {
+		name        string
+		trigger     config.TriggerConfig
+		expectError bool
+	}{
+		{name: "Defaults", trigger: config.TriggerConfig{}},
+		{name: "All actions", trigger: config.TriggerConfig{Actions: []string{"create", "update", "replace", "delete"}}},
+		{name: "Unknown action", trigger: config.TriggerConfig{Actions: []string{"destroy"}}, expectError: true},
+		{name: "Indexed address", trigger: config.TriggerConfig{Addresses: []string{"aws_instance.web[0]"}}},
+		{name: "Bad address pattern", trigger: config.TriggerConfig{Addresses: []string{`module.prod.\`}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTrigger(tt.trigger)
+			if tt.expectError && err == nil {
+				t.Error("Expected an error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMostSevereStatus(t *testing.T) {
+	changes := []tfplan.ResourceChange{
+		{Change: tfplan.Change{Actions: []string{"create"}}},
+		{Change: tfplan.Change{Actions: []string{"delete", "create"}}},
+		{Change: tfplan.Change{Actions: []string{"update"}}},
+	}
+
+	if got := MostSevereStatus(changes); got != StatusReplaced {
+		t.Errorf("MostSevereStatus() = %s, expected %s", got, StatusReplaced)
+	}
+	if got := MostSevereStatus(nil); got != StatusUnknown {
+		t.Errorf("MostSevereStatus(nil) = %s, expected %s", got, StatusUnknown)
+	}
+}
+
+func TestDedupKey(t *testing.T) {
+	payload := &Payload{
+		Plan:     &tfplan.Plan{},
+		Metadata: &PayloadMetadata{Git: &git.Metadata{Branch: "main", CommitSHA: "abc123"}},
+	}
+	changes := []tfplan.ResourceChange{
+		{Address: "aws_instance.a", Change: tfplan.Change{Actions: []string{"delete"}}},
+		{Address: "aws_instance.b", Change: tfplan.Change{Actions: []string{"delete"}}},
+	}
+	reversed := []tfplan.ResourceChange{changes[1], changes[0]}
+
+	key := DedupKey(payload, changes)
+	if key != DedupKey(payload, reversed) {
+		t.Error("Expected dedup key to be independent of change order")
+	}
+
+	other := &Payload{
+		Plan:     &tfplan.Plan{},
+		Metadata: &PayloadMetadata{Git: &git.Metadata{Branch: "main", CommitSHA: "def456"}},
+	}
+	if key == DedupKey(other, changes) {
+		t.Error("Expected dedup key to change with the commit")
+	}
+	if key == DedupKey(payload, changes[:1]) {
+		t.Error("Expected dedup key to change with the set of changes")
+	}
+}
@@ -15,9 +15,10 @@ import (
 )
 
 type WebhookTarget struct {
-	url    string
-	method string
-	retry  config.RetryConfig
+	url         string
+	method      string
+	retry       config.RetryConfig
+	cloudEvents *target.CloudEvents
 }
 
 func New(cfg config.WebhookConfig) (*WebhookTarget, error) {
@@ -28,22 +29,28 @@ func New(cfg config.WebhookConfig) (*WebhookTarget, error) {
 		return nil, fmt.Errorf("invalid method: %s. Method must be POST or PUT", cfg.Method)
 	}
 
+	cloudEvents, err := target.NewCloudEvents(cfg.CloudEvents)
+	if err != nil {
+		return nil, err
+	}
+
 	return &WebhookTarget{
-		url:    cfg.URL,
-		method: method,
-		retry:  cfg.Retry.WithDefaults(),
+		url:         cfg.URL,
+		method:      method,
+		retry:       cfg.Retry.WithDefaults(),
+		cloudEvents: cloudEvents,
 	}, nil
 }
 
 func (t *WebhookTarget) Write(p *target.Payload) error {
-	jsonData, err := json.Marshal(p)
+	body, headers, err := t.buildRequest(p)
 	if err != nil {
-		return fmt.Errorf("error marshaling webhook body: %w", err)
+		return err
 	}
 
 	var lastErr error
 	for attempt := 1; attempt <= t.retry.MaxAttempts; attempt++ {
-		statusCode, err := t.doRequest(jsonData)
+		statusCode, err := t.doRequest(body, headers)
 		if err != nil {
 			lastErr = err
 			if attempt < t.retry.MaxAttempts {
@@ -70,12 +77,40 @@ func (t *WebhookTarget) Write(p *target.Payload) error {
 	return fmt.Errorf("webhook request failed after %d attempts: %w", t.retry.MaxAttempts, lastErr)
 }
 
-func (t *WebhookTarget) doRequest(jsonData []byte) (int, error) {
-	req, err := http.NewRequest(t.method, t.url, bytes.NewBuffer(jsonData))
+// buildRequest returns the request body and headers: the payload as JSON, or
+// a CloudEvent in the configured HTTP content mode.
+func (t *WebhookTarget) buildRequest(p *target.Payload) ([]byte, map[string]string, error) {
+	if t.cloudEvents == nil {
+		jsonData, err := json.Marshal(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshaling webhook body: %w", err)
+		}
+		return jsonData, map[string]string{"Content-Type": "application/json"}, nil
+	}
+
+	body, attributes, err := target.EncodeMessage(p, target.NewMessages(p, false)[0], t.cloudEvents, "ce-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only the CloudEvents attributes are sent, not the message-bus headers.
+	headers := map[string]string{"Content-Type": attributes["content-type"]}
+	for key, value := range attributes {
+		if strings.HasPrefix(key, "ce-") {
+			headers[key] = value
+		}
+	}
+	return body, headers, nil
+}
+
+func (t *WebhookTarget) doRequest(body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequest(t.method, t.url, bytes.NewBuffer(body))
 	if err != nil {
 		return 0, fmt.Errorf("error creating request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -1,6 +1,7 @@
 package webhook
 
 import (
+	"encoding/json"
 	"infralog/config"
 	"infralog/target"
 	"infralog/tfplan"
@@ -98,6 +99,61 @@ func TestWrite_Success(t *testing.T) {
 	}
 }
 
+func TestWrite_CloudEvents(t *testing.T) {
+	tests := []struct {
+		mode                string
+		expectedContentType string
+		expectedCEType      string
+	}{
+		{"structured", "application/cloudevents+json", ""},
+		{"binary", "application/json", target.CloudEventTypePlanChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var header http.Header
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				json.NewDecoder(r.Body).Decode(&body)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			wh, err := New(config.WebhookConfig{URL: server.URL, CloudEvents: config.CloudEventsConfig{Mode: tt.mode}})
+			if err != nil {
+				t.Fatalf("Failed to create webhook target: %v", err)
+			}
+
+			if err := wh.Write(target.NewPayload(&tfplan.Plan{})); err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if got := header.Get("Content-Type"); got != tt.expectedContentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.expectedContentType, got)
+			}
+			if got := header.Get("Ce-Type"); got != tt.expectedCEType {
+				t.Errorf("Expected ce-type header %q, got %q", tt.expectedCEType, got)
+			}
+			if header.Get("Infralog-Branch") != "" || header.Get("Infralog-Id") != "" {
+				t.Error("Expected message-bus headers not to be sent")
+			}
+
+			// The payload is the event data in binary mode, and nested in structured mode.
+			data := body
+			if tt.mode == "structured" {
+				if body["specversion"] != "1.0" || body["type"] != target.CloudEventTypePlanChanged {
+					t.Errorf("Unexpected event: %v", body)
+				}
+				data, _ = body["data"].(map[string]interface{})
+			}
+			if _, ok := data["plan"]; !ok {
+				t.Errorf("Expected the payload as event data, got %v", body)
+			}
+		})
+	}
+}
+
 func TestWrite_NonRetryableError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -0,0 +1,243 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"infralog/target"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/log"
+	lognoop "go.opentelemetry.io/otel/log/noop"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// OTLP protocols, set with OTEL_EXPORTER_OTLP_PROTOCOL or the per-signal
+// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL and OTEL_EXPORTER_OTLP_LOGS_PROTOCOL.
+const (
+	ProtocolHTTP = "http/protobuf"
+	ProtocolGRPC = "grpc"
+)
+
+const (
+	serviceName     = "infralog"
+	instrumentation = "infralog"
+
+	// attributePrefix namespaces span and log attributes set by infralog.
+	attributePrefix = "infralog."
+)
+
+// Telemetry exports a trace of each run and a log record per resource change
+// over OTLP. Exporters are configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables; a signal is only exported when an OTLP endpoint is
+// set for it, otherwise its spans or records are dropped.
+type Telemetry struct {
+	tracer   trace.Tracer
+	logger   log.Logger
+	shutdown []func(context.Context) error
+}
+
+// New creates exporters for the signals that have an OTLP endpoint.
+func New(ctx context.Context) (*Telemetry, error) {
+	t := &Telemetry{
+		tracer: tracenoop.NewTracerProvider().Tracer(instrumentation),
+		logger: lognoop.NewLoggerProvider().Logger(instrumentation),
+	}
+
+	tracesEnabled, logsEnabled := signalEnabled("TRACES"), signalEnabled("LOGS")
+	if !tracesEnabled && !logsEnabled {
+		return t, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating telemetry resource: %w", err)
+	}
+
+	if tracesEnabled {
+		exporter, err := newTraceExporter(ctx, protocol("TRACES"))
+		if err != nil {
+			return nil, err
+		}
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+		t.tracer = provider.Tracer(instrumentation)
+		t.shutdown = append(t.shutdown, provider.Shutdown)
+	}
+
+	if logsEnabled {
+		exporter, err := newLogExporter(ctx, protocol("LOGS"))
+		if err != nil {
+			return nil, err
+		}
+		provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)), sdklog.WithResource(res))
+		t.logger = provider.Logger(instrumentation)
+		t.shutdown = append(t.shutdown, provider.Shutdown)
+	}
+
+	return t, nil
+}
+
+// signalEnabled reports whether an OTLP endpoint is set for a signal, TRACES
+// or LOGS, and the signal isn't disabled with OTEL_SDK_DISABLED or
+// OTEL_<signal>_EXPORTER=none.
+func signalEnabled(signal string) bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") || os.Getenv("OTEL_"+signal+"_EXPORTER") == "none" {
+		return false
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_"+signal+"_ENDPOINT") != ""
+}
+
+// protocol returns the OTLP protocol of a signal, http/protobuf by default.
+func protocol(signal string) string {
+	if p := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_PROTOCOL"); p != "" {
+		return p
+	}
+	if p := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); p != "" {
+		return p
+	}
+	return ProtocolHTTP
+}
+
+func newTraceExporter(ctx context.Context, protocol string) (sdktrace.SpanExporter, error) {
+	switch protocol {
+	case ProtocolHTTP:
+		return otlptracehttp.New(ctx)
+	case ProtocolGRPC:
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s. Protocol must be http/protobuf or grpc", protocol)
+	}
+}
+
+func newLogExporter(ctx context.Context, protocol string) (sdklog.Exporter, error) {
+	switch protocol {
+	case ProtocolHTTP:
+		return otlploghttp.New(ctx)
+	case ProtocolGRPC:
+		return otlploggrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s. Protocol must be http/protobuf or grpc", protocol)
+	}
+}
+
+// StartRun starts the root span of a run. If the TRACEPARENT environment
+// variable holds a W3C trace context, for example set by a CI pipeline, the
+// run is part of that trace.
+func (t *Telemetry) StartRun(ctx context.Context, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if traceparent := os.Getenv("TRACEPARENT"); traceparent != "" {
+		carrier := propagation.MapCarrier{"traceparent": traceparent, "tracestate": os.Getenv("TRACESTATE")}
+		ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+	}
+	return t.Start(ctx, "infralog run", attrs...)
+}
+
+// Start starts a span as a child of the span in ctx.
+func (t *Telemetry) Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends a span, recording err as its status.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// String returns an infralog string attribute, e.g. infralog.target.
+func String(name, value string) attribute.KeyValue {
+	return attribute.String(attributePrefix+name, value)
+}
+
+// Int returns an infralog integer attribute, e.g. infralog.resources.
+func Int(name string, value int) attribute.KeyValue {
+	return attribute.Int(attributePrefix+name, value)
+}
+
+// PayloadAttributes returns the git metadata and change counts of a payload
+// as span attributes.
+func PayloadAttributes(p *target.Payload) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if p.Metadata != nil && p.Metadata.Git != nil {
+		for _, a := range []attribute.KeyValue{
+			String("git.branch", p.Metadata.Git.Branch),
+			String("git.sha", p.Metadata.Git.CommitSHA),
+			String("git.repo", p.Metadata.Git.RepoURL),
+		} {
+			if a.Value.AsString() != "" {
+				attrs = append(attrs, a)
+			}
+		}
+	}
+
+	s := target.Summarize(p.Plan)
+	return append(attrs,
+		Int("resources", s.Resources),
+		Int("added", s.Added),
+		Int("changed", s.Changed),
+		Int("replaced", s.Replaced),
+		Int("removed", s.Removed),
+		Int("outputs", s.Outputs),
+	)
+}
+
+// EmitLogs emits a log record per resource change and a summary record for
+// the payload, correlated with the span in ctx.
+func (t *Telemetry) EmitLogs(ctx context.Context, p *target.Payload) {
+	now := time.Now()
+	for _, r := range target.NewLogRecords(p) {
+		var record log.Record
+		record.SetEventName(attributePrefix + r.Event)
+		record.SetTimestamp(r.Datetime)
+		record.SetObservedTimestamp(now)
+		record.SetBody(log.StringValue(r.Message))
+
+		severity := log.SeverityInfo
+		if r.Severity == target.SeverityWarning {
+			severity = log.SeverityWarn
+		}
+		record.SetSeverity(severity)
+		record.SetSeverityText(severity.String())
+
+		attrs := make([]log.KeyValue, 0, len(r.Fields))
+		for _, f := range r.Fields {
+			attrs = append(attrs, log.String(attributePrefix+f.Name, f.Value))
+		}
+		record.AddAttributes(attrs...)
+
+		t.logger.Emit(ctx, record)
+	}
+}
+
+// Shutdown flushes and stops the exporters.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, shutdown := range t.shutdown {
+		if err := shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error exporting telemetry: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"infralog/target/targettest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeCollector records OTLP/HTTP export requests.
+type fakeCollector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+	logs  *collectorlogs.ExportLogsServiceRequest
+}
+
+func newFakeCollector(t *testing.T) (*httptest.Server, *fakeCollector) {
+	t.Helper()
+
+	c := &fakeCollector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read request: %v", err)
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/traces":
+			var req collectortrace.ExportTraceServiceRequest
+			if err := proto.Unmarshal(body, &req); err != nil {
+				t.Errorf("Failed to decode traces: %v", err)
+			}
+			for _, rs := range req.ResourceSpans {
+				for _, ss := range rs.ScopeSpans {
+					c.spans = append(c.spans, ss.Spans...)
+				}
+			}
+		case "/v1/logs":
+			c.logs = &collectorlogs.ExportLogsServiceRequest{}
+			if err := proto.Unmarshal(body, c.logs); err != nil {
+				t.Errorf("Failed to decode logs: %v", err)
+			}
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(server.Close)
+	return server, c
+}
+
+func attributeValue(attrs []*commonpb.KeyValue, key string) *commonpb.AnyValue {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return nil
+}
+
+func TestTelemetry_Export(t *testing.T) {
+	server, collector := newFakeCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("OTEL_SERVICE_NAME", "infralog-ci")
+
+	ctx := context.Background()
+	tel, err := New(ctx)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	p := targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete"))
+	runCtx, run := tel.StartRun(ctx)
+	_, parse := tel.Start(runCtx, "parse plan")
+	End(parse, nil)
+	_, deliver := tel.Start(runCtx, "deliver Slack", String("target", "Slack"))
+	End(deliver, errors.New("slack returned non-success status code: 500"))
+	run.SetAttributes(PayloadAttributes(p)...)
+	tel.EmitLogs(runCtx, p)
+	End(run, nil)
+
+	if err := tel.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	spans := map[string]*tracepb.Span{}
+	for _, s := range collector.spans {
+		spans[s.Name] = s
+	}
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(collector.spans))
+	}
+
+	root := spans["infralog run"]
+	for _, name := range []string{"parse plan", "deliver Slack"} {
+		if s := spans[name]; s == nil || string(s.ParentSpanId) != string(root.SpanId) {
+			t.Errorf("Expected %s to be a child of the run span", name)
+		}
+	}
+	if v := attributeValue(root.Attributes, "infralog.removed"); v.GetIntValue() != 1 {
+		t.Errorf("Expected 1 removed resource, got %v", v)
+	}
+	if v := attributeValue(root.Attributes, "infralog.git.branch"); v.GetStringValue() != "main" {
+		t.Errorf("Expected branch main, got %v", v)
+	}
+
+	deliverSpan := spans["deliver Slack"]
+	if deliverSpan.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR || deliverSpan.Status.GetMessage() == "" {
+		t.Errorf("Expected error status, got %v", deliverSpan.Status)
+	}
+	if v := attributeValue(deliverSpan.Attributes, "infralog.target"); v.GetStringValue() != "Slack" {
+		t.Errorf("Expected target attribute, got %v", v)
+	}
+
+	if collector.logs == nil {
+		t.Fatal("Expected logs to be exported")
+	}
+	rl := collector.logs.ResourceLogs[0]
+	if v := attributeValue(rl.Resource.Attributes, "service.name"); v.GetStringValue() != "infralog-ci" {
+		t.Errorf("Expected service name from OTEL_SERVICE_NAME, got %v", v)
+	}
+
+	records := rl.ScopeLogs[0].LogRecords
+	if len(records) != 3 {
+		t.Fatalf("Expected 2 resource records and a summary, got %d", len(records))
+	}
+	removed := records[1]
+	if removed.EventName != "infralog.resource_change" || removed.SeverityText != "WARN" {
+		t.Errorf("Unexpected record: %v", removed)
+	}
+	if v := attributeValue(removed.Attributes, "infralog.address"); v.GetStringValue() != "aws_instance.web" {
+		t.Errorf("Expected address attribute, got %v", v)
+	}
+	if string(removed.TraceId) != string(root.TraceId) || string(removed.SpanId) != string(root.SpanId) {
+		t.Error("Expected records to be correlated with the run span")
+	}
+	if records[2].EventName != "infralog.summary" {
+		t.Errorf("Expected a summary record, got %q", records[2].EventName)
+	}
+}
+
+func TestTelemetry_TraceParent(t *testing.T) {
+	server, collector := newFakeCollector(t)
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", server.URL+"/v1/traces")
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+	ctx := context.Background()
+	tel, err := New(ctx)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, run := tel.StartRun(ctx)
+	End(run, nil)
+	tel.EmitLogs(ctx, targettest.Payload(targettest.Change("aws_s3_bucket.data", "create"), targettest.Change("aws_instance.web", "delete")))
+
+	if err := tel.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if len(collector.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(collector.spans))
+	}
+	s := collector.spans[0]
+	if got := s.TraceId; len(got) != 16 || got[0] != 0x0a || got[15] != 0x9c {
+		t.Errorf("Expected the trace ID from TRACEPARENT, got %x", got)
+	}
+	if collector.logs != nil {
+		t.Error("Expected no logs without a logs endpoint")
+	}
+}
+
+func TestNew_Disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "")
+
+	tel, err := New(context.Background())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(tel.shutdown) != 0 {
+		t.Error("Expected no exporters without an endpoint")
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	if tel, _ := New(context.Background()); len(tel.shutdown) != 0 {
+		t.Error("Expected no exporters with OTEL_SDK_DISABLED")
+	}
+}
+
+func TestNew_UnsupportedProtocol(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/json")
+
+	if _, err := New(context.Background()); err == nil {
+		t.Error("Expected an error for http/json")
+	}
+}